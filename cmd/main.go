@@ -1,23 +1,65 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
 	"github.com/ChongYanOvO/little-blue-book/wire"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	app, err := wire.InitApp()
+	// 授予角色的接口本身需要管理员权限，第一个管理员只能通过命令行创建
+	grantAdmin := flag.Int64("grant-admin", 0, "给指定用户授予管理员角色之后退出")
+	flag.Parse()
+	if *grantAdmin > 0 {
+		svc, err := wire.InitRoleService()
+		if err != nil {
+			panic(err)
+		}
+		if err = svc.Grant(context.Background(), *grantAdmin, domain.RoleAdmin); err != nil {
+			panic(err)
+		}
+		fmt.Printf("已授予用户 %d 管理员角色\n", *grantAdmin)
+		return
+	}
+
+	app, cleanup, err := wire.InitApp()
 	if err != nil {
 		panic(err)
 	}
+	defer cleanup()
 
 	config, err := wire.InitConfig()
 	if err != nil {
 		panic(err)
 	}
-	app.Server.Run(
-		fmt.Sprintf("%s:%d",
+	server := &http.Server{
+		Addr: fmt.Sprintf("%s:%d",
 			config.ServerConfig.Host,
 			config.ServerConfig.Port),
-	)
+		Handler: app.Server,
+	}
+	go func() {
+		if er := server.ListenAndServe(); er != nil && !errors.Is(er, http.ErrServerClosed) {
+			panic(er)
+		}
+	}()
+
+	// 收到退出信号之后先停止接收请求，再停掉后台任务
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	if err = server.Shutdown(ctx); err != nil {
+		app.Logger.Error("关闭 http 服务失败", zap.Error(err))
+	}
 }
@@ -0,0 +1,24 @@
+package bootstrap
+
+import "github.com/ChongYanOvO/little-blue-book/pkg/captcha"
+
+// CaptchaConfig 图形验证码配置
+type CaptchaConfig struct {
+	Length int `mapstructure:"length" json:"length" yaml:"length"` // 数字的个数
+	Width  int `mapstructure:"width" json:"width" yaml:"width"`
+	Height int `mapstructure:"height" json:"height" yaml:"height"`
+}
+
+// NewCaptchaGenerator 没有配置的时候生成 120x40 的 4 位数字
+func NewCaptchaGenerator(c *Config) captcha.Generator {
+	length, width, height := 4, 120, 40
+	if cc := c.CaptchaConfig; cc != nil {
+		if cc.Length > 0 {
+			length = cc.Length
+		}
+		if cc.Width > 0 && cc.Height > 0 {
+			width, height = cc.Width, cc.Height
+		}
+	}
+	return captcha.NewDigitGenerator(length, width, height)
+}
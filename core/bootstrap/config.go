@@ -15,6 +15,12 @@ type Config struct {
 	TokenConfig  *TokenConfig  `mapstructure:"token" json:"token" yaml:"token"`
 	CacheConfig  *CacheConfig  `mapstructure:"cache" json:"cache" yaml:"cache"`
 	LimitConfig  *LimitConfig  `mapstructure:"limit" json:"limit" yaml:"limit"`
+	EmailConfig  *EmailConfig  `mapstructure:"email" json:"email" yaml:"email"`
+	OAuth2Config *OAuth2Config `mapstructure:"oauth2" json:"oauth2" yaml:"oauth2"`
+
+	SmsConfig       *SmsConfig       `mapstructure:"sms" json:"sms" yaml:"sms"`
+	SensitiveConfig *SensitiveConfig `mapstructure:"sensitive" json:"sensitive" yaml:"sensitive"`
+	CaptchaConfig   *CaptchaConfig   `mapstructure:"captcha" json:"captcha" yaml:"captcha"`
 }
 
 // NewConfig 读取配置文件
@@ -0,0 +1,24 @@
+package bootstrap
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/service/email"
+	"go.uber.org/zap"
+)
+
+// EmailConfig 邮件服务配置
+type EmailConfig struct {
+	Host     string `mapstructure:"host" json:"host" yaml:"host"`             // SMTP 服务器地址
+	Port     int    `mapstructure:"port" json:"port" yaml:"port"`             // 端口
+	Username string `mapstructure:"username" json:"username" yaml:"username"` // 用户名
+	Password string `mapstructure:"password" json:"password" yaml:"password"` // 密码
+	From     string `mapstructure:"from" json:"from" yaml:"from"`             // 发件人
+}
+
+// NewEmailService 没有配置 SMTP 服务器的时候使用内存实现，直接打印到控制台
+func NewEmailService(c *Config, l *zap.Logger) email.EmailService {
+	e := c.EmailConfig
+	if e == nil || e.Host == "" {
+		return email.NewMemoryService(l)
+	}
+	return email.NewSmtpService(e.Host, e.Port, e.Username, e.Password, e.From, l)
+}
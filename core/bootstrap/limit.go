@@ -1,10 +1,139 @@
 package bootstrap
 
+import (
+	"fmt"
+	ginratelimit "github.com/ChongYanOvO/little-blue-book/internal/handler/middleware/ratelimit"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"time"
+)
+
 type LimitConfig struct {
-	SmsLimitConfig *SmsLimitConfig `mapstructure:"sms" json:"sms" yaml:"sms"`
+	SmsLimitConfig  *SmsLimitConfig  `mapstructure:"sms" json:"sms" yaml:"sms"`
+	CodeLimitConfig *CodeLimitConfig `mapstructure:"code" json:"code" yaml:"code"`
+	HttpLimitConfig *HttpLimitConfig `mapstructure:"http" json:"http" yaml:"http"`
 }
 
 type SmsLimitConfig struct {
 	Interval int `mapstructure:"interval" json:"interval" yaml:"interval"`
 	Rate     int `mapstructure:"rate" json:"rate" yaml:"rate"`
 }
+
+// CodeLimitConfig 短信验证码的分层限流，每一层都是 Interval 内最多 Rate 次
+type CodeLimitConfig struct {
+	Phone   *WindowLimitConfig `mapstructure:"phone" json:"phone" yaml:"phone"`       // 每个手机号
+	Ip      *WindowLimitConfig `mapstructure:"ip" json:"ip" yaml:"ip"`                // 每个 IP
+	Biz     *WindowLimitConfig `mapstructure:"biz" json:"biz" yaml:"biz"`             // 每种业务全局
+	Captcha *WindowLimitConfig `mapstructure:"captcha" json:"captcha" yaml:"captcha"` // 每个 IP 和手机号超过之后需要图形验证码
+}
+
+type WindowLimitConfig struct {
+	Interval int `mapstructure:"interval" json:"interval" yaml:"interval"`
+	Rate     int `mapstructure:"rate" json:"rate" yaml:"rate"`
+}
+
+// NewCodeLimits 没有配置的层用默认值：每个手机号每天 10 条，每个 IP 每小时 20 条，每种业务每分钟 1000 条，
+// 同一个 IP 或者手机号每小时超过 3 条之后需要图形验证码
+func NewCodeLimits(c *Config, cmd redis.Cmdable) service.CodeLimits {
+	var cc CodeLimitConfig
+	if c.LimitConfig != nil && c.LimitConfig.CodeLimitConfig != nil {
+		cc = *c.LimitConfig.CodeLimitConfig
+	}
+	return service.CodeLimits{
+		Phone:   newWindowLimiter(cmd, cc.Phone, time.Hour*24, 10),
+		Ip:      newWindowLimiter(cmd, cc.Ip, time.Hour, 20),
+		Biz:     newWindowLimiter(cmd, cc.Biz, time.Minute, 1000),
+		Captcha: newWindowLimiter(cmd, cc.Captcha, time.Hour, 3),
+	}
+}
+
+func newWindowLimiter(cmd redis.Cmdable, c *WindowLimitConfig, interval time.Duration, rate int) ratelimit.Limiter {
+	if c != nil && c.Interval > 0 && c.Rate > 0 {
+		interval, rate = time.Duration(c.Interval), c.Rate
+	}
+	return ratelimit.NewRedisSlidingWindowLimiter(cmd, interval, rate)
+}
+
+// HttpLimitConfig 接口限流，每条规则单独计数
+type HttpLimitConfig struct {
+	FailOpen bool            `mapstructure:"fail-open" json:"fail-open" yaml:"fail-open"` // 限流器出错的时候是否放行，默认不放行
+	Rules    []HttpLimitRule `mapstructure:"rules" json:"rules" yaml:"rules"`
+}
+
+type HttpLimitRule struct {
+	Method    string `mapstructure:"method" json:"method" yaml:"method"`          // 为空的时候不区分方法
+	Path      string `mapstructure:"path" json:"path" yaml:"path"`                // 注册路由时的路径，比如 /articles/:id，为空的时候对所有接口生效
+	Key       string `mapstructure:"key" json:"key" yaml:"key"`                   // ip user route，默认 ip
+	Algorithm string `mapstructure:"algorithm" json:"algorithm" yaml:"algorithm"` // sliding-window token-bucket leaky-bucket，加上 local- 前缀是进程内的实现，默认 sliding-window
+	Interval  int    `mapstructure:"interval" json:"interval" yaml:"interval"`
+	Rate      int    `mapstructure:"rate" json:"rate" yaml:"rate"`
+	Capacity  int    `mapstructure:"capacity" json:"capacity" yaml:"capacity"` // 令牌桶和漏桶的容量，为 0 的时候和 Rate 一样
+}
+
+var httpLimitKeys = map[string]ginratelimit.KeyFunc{
+	"ip":    ginratelimit.KeyByIP,
+	"user":  ginratelimit.KeyByUser,
+	"route": ginratelimit.KeyByRoute,
+}
+
+// NewHttpLimitMiddlewares 每条规则一个中间件，配置不对的规则跳过。
+// 按用户限流的规则放在 after 里，要放在登录校验之后才能拿到用户 id，
+// 其他的规则放在 before 里，在登录校验之前就拦住，不用每个请求都去查登录态
+func NewHttpLimitMiddlewares(c *Config, cmd redis.Cmdable, l *zap.Logger) (before []gin.HandlerFunc, after []gin.HandlerFunc) {
+	if c.LimitConfig == nil || c.LimitConfig.HttpLimitConfig == nil {
+		return nil, nil
+	}
+	hc := c.LimitConfig.HttpLimitConfig
+	for _, rule := range hc.Rules {
+		if rule.Key == "" {
+			rule.Key = "ip"
+		}
+		if rule.Algorithm == "" {
+			rule.Algorithm = "sliding-window"
+		}
+		key, ok := httpLimitKeys[rule.Key]
+		limiter := newHttpLimiter(cmd, rule)
+		if !ok || limiter == nil {
+			l.Error("接口限流规则配置不正确", zap.Any("rule", rule))
+			continue
+		}
+		// 不同算法在 Redis 里的数据结构不一样，换算法的时候不能用同一个 key
+		prefix := fmt.Sprintf("http-limiter:%s:%s:%s", rule.Algorithm, rule.Method, rule.Path)
+		m := ginratelimit.NewBuilder(limiter).
+			Prefix(prefix).
+			Key(key).
+			Route(rule.Method, rule.Path).
+			FailOpen(hc.FailOpen).
+			Logger(l).
+			Build()
+		if rule.Key == "user" {
+			after = append(after, m)
+		} else {
+			before = append(before, m)
+		}
+	}
+	return before, after
+}
+
+func newHttpLimiter(cmd redis.Cmdable, rule HttpLimitRule) ratelimit.Limiter {
+	if rule.Interval <= 0 || rule.Rate <= 0 {
+		return nil
+	}
+	interval := time.Duration(rule.Interval)
+	switch rule.Algorithm {
+	case "sliding-window":
+		return ratelimit.NewRedisSlidingWindowLimiter(cmd, interval, rule.Rate)
+	case "token-bucket":
+		return ratelimit.NewRedisTokenBucketLimiter(cmd, interval, rule.Rate, rule.Capacity)
+	case "leaky-bucket":
+		return ratelimit.NewRedisLeakyBucketLimiter(cmd, interval, rule.Rate, rule.Capacity)
+	case "local-token-bucket":
+		return ratelimit.NewLocalTokenBucketLimiter(interval, rule.Rate, rule.Capacity)
+	case "local-leaky-bucket":
+		return ratelimit.NewLocalLeakyBucketLimiter(interval, rule.Rate, rule.Capacity)
+	}
+	return nil
+}
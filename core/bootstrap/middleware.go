@@ -3,26 +3,45 @@ package bootstrap
 import (
 	"context"
 	"github.com/ChongYanOvO/little-blue-book/internal/handler/middleware"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
 	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/middleware/accesslog"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"net/http"
+	"strings"
 )
 
-func NewMiddlewares(l *zap.Logger) []gin.HandlerFunc {
-	return []gin.HandlerFunc{
-		LoginMiddleWare(),
+// NewMiddlewares 按 ip 和路由的接口限流放在登录校验之前，按用户限流的放在登录校验之后才能拿到用户 id，
+// 日志和跨域放在最前面，被限流的请求也能记日志、带上跨域的响应头
+func NewMiddlewares(c *Config, cmd redis.Cmdable, l *zap.Logger, sessionSvc service.SessionService) []gin.HandlerFunc {
+	before, after := NewHttpLimitMiddlewares(c, cmd, l)
+	middlewares := []gin.HandlerFunc{
 		LoggerMiddleware(l),
 		CorsMiddleware(),
 	}
+	middlewares = append(middlewares, before...)
+	middlewares = append(middlewares, LoginMiddleWare(sessionSvc))
+	return append(middlewares, after...)
 }
 
 // LoginMiddleWare 登录中间件
-func LoginMiddleWare() gin.HandlerFunc {
-	return middleware.NewLoginBuilder().
+func LoginMiddleWare(sessionSvc service.SessionService) gin.HandlerFunc {
+	return middleware.NewLoginBuilder(sessionSvc).
 		IgnorePaths("/users/login").
 		IgnorePaths("/users/signup").
 		IgnorePaths("/users/login/code").
+		IgnorePaths("/users/password/reset/code").
+		IgnorePaths("/users/password/reset").
+		IgnorePaths("/users/email/verify/code").
+		IgnorePaths("/users/email/verify").
+		IgnorePaths("/oauth2/github/authurl").
+		IgnorePaths("/oauth2/github/callback").
+		IgnorePaths("/oauth2/wechat/authurl").
+		IgnorePaths("/oauth2/wechat/callback").
+		IgnorePaths("/captcha").
+		IgnorePaths("/sms/callback/:provider").
+		IgnorePaths("/sms/callback/:provider/:secret").
 		Build()
 }
 
@@ -30,6 +49,12 @@ func LoginMiddleWare() gin.HandlerFunc {
 func LoggerMiddleware(l *zap.Logger) gin.HandlerFunc {
 	return accesslog.
 		NewBuilder(func(ctx context.Context, log *accesslog.AccessLog) {
+			// 短信回执地址里带着密钥，不能打到日志里
+			if gc, ok := ctx.(*gin.Context); ok {
+				if secret := gc.Param("secret"); secret != "" {
+					log.Url = strings.ReplaceAll(log.Url, secret, "***")
+				}
+			}
 			l.Info("Http请求", zap.Any("日志", log))
 		}).
 		AllowRequestBody(true).
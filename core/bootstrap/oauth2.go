@@ -0,0 +1,44 @@
+package bootstrap
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/service/oauth2"
+	"net/http"
+	"time"
+)
+
+// OAuth2Config 第三方登录配置，没有配置 client id 的提供方不启用
+type OAuth2Config struct {
+	Github *GithubOAuth2Config `mapstructure:"github" json:"github" yaml:"github"`
+	Wechat *WechatOAuth2Config `mapstructure:"wechat" json:"wechat" yaml:"wechat"`
+}
+
+type GithubOAuth2Config struct {
+	ClientId     string `mapstructure:"client-id" json:"client-id" yaml:"client-id"`
+	ClientSecret string `mapstructure:"client-secret" json:"client-secret" yaml:"client-secret"`
+	RedirectURL  string `mapstructure:"redirect-url" json:"redirect-url" yaml:"redirect-url"`
+}
+
+type WechatOAuth2Config struct {
+	AppId       string `mapstructure:"app-id" json:"app-id" yaml:"app-id"`
+	AppSecret   string `mapstructure:"app-secret" json:"app-secret" yaml:"app-secret"`
+	RedirectURL string `mapstructure:"redirect-url" json:"redirect-url" yaml:"redirect-url"`
+}
+
+// NewOAuth2Providers 根据配置创建第三方登录提供方
+func NewOAuth2Providers(c *Config) []oauth2.Provider {
+	o := c.OAuth2Config
+	if o == nil {
+		return nil
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	var providers []oauth2.Provider
+	if o.Github != nil && o.Github.ClientId != "" {
+		providers = append(providers, oauth2.NewGithubProvider(
+			o.Github.ClientId, o.Github.ClientSecret, o.Github.RedirectURL, client))
+	}
+	if o.Wechat != nil && o.Wechat.AppId != "" {
+		providers = append(providers, oauth2.NewWechatProvider(
+			o.Wechat.AppId, o.Wechat.AppSecret, o.Wechat.RedirectURL, client))
+	}
+	return providers
+}
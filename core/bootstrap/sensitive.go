@@ -0,0 +1,97 @@
+package bootstrap
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/sensitive"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// SensitiveConfig 敏感词配置
+type SensitiveConfig struct {
+	Dict     string            `mapstructure:"dict" json:"dict" yaml:"dict"`             // 词库文件，一行一个词
+	Interval int               `mapstructure:"interval" json:"interval" yaml:"interval"` // 检查词库文件是否修改的间隔，单位纳秒
+	Policy   map[string]string `mapstructure:"policy" json:"policy" yaml:"policy"`       // 每种业务的处理策略 reject mask review
+}
+
+var sensitivePolicies = map[string]domain.SensitivePolicy{
+	"reject": domain.SensitivePolicyReject,
+	"mask":   domain.SensitivePolicyMask,
+	"review": domain.SensitivePolicyReview,
+}
+
+// NewSensitiveFilter 词库文件修改之后自动重新加载，没有配置词库的时候不过滤，返回的函数用来停止重新加载
+func NewSensitiveFilter(c *Config, l *zap.Logger) (sensitive.Filter, func()) {
+	f := sensitive.NewACFilter(nil)
+	s := c.SensitiveConfig
+	if s == nil || s.Dict == "" {
+		return f, func() {}
+	}
+	modTime, err := loadSensitiveDict(f, s.Dict)
+	if err != nil {
+		l.Error("加载敏感词库失败", zap.String("dict", s.Dict), zap.Error(err))
+	}
+	interval := time.Duration(s.Interval)
+	if interval <= 0 {
+		interval = time.Second * 30
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			info, er := os.Stat(s.Dict)
+			if er != nil || !info.ModTime().After(modTime) {
+				continue
+			}
+			if modTime, er = loadSensitiveDict(f, s.Dict); er != nil {
+				l.Error("重新加载敏感词库失败", zap.String("dict", s.Dict), zap.Error(er))
+				continue
+			}
+			l.Info("重新加载敏感词库", zap.String("dict", s.Dict))
+		}
+	}()
+	return f, cancel
+}
+
+// NewSensitivePolicies 不认识的策略按拒绝处理
+func NewSensitivePolicies(c *Config) service.SensitivePolicies {
+	policies := service.SensitivePolicies{}
+	if c.SensitiveConfig == nil {
+		return policies
+	}
+	for biz, name := range c.SensitiveConfig.Policy {
+		policy, ok := sensitivePolicies[name]
+		if !ok {
+			policy = domain.SensitivePolicyReject
+		}
+		policies[biz] = policy
+	}
+	return policies
+}
+
+func loadSensitiveDict(f sensitive.Filter, path string) (time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return time.Time{}, err
+	}
+	words, err := sensitive.ReadWords(file)
+	if err != nil {
+		return time.Time{}, err
+	}
+	f.Load(words)
+	return info.ModTime(), nil
+}
@@ -1,6 +1,7 @@
 package bootstrap
 
 import (
+	"fmt"
 	"github.com/ChongYanOvO/little-blue-book/internal/handler"
 	"github.com/gin-gonic/gin"
 )
@@ -9,18 +10,55 @@ import (
 type ServerConfig struct {
 	Host string `mapstructure:"host" json:"host" yaml:"host"`
 	Port int    `mapstructure:"port" json:"port" yaml:"port"`
+	// TrustedProxies 前面的反向代理地址，只有从这些地址来的请求才会读 RemoteIPHeaders 拿客户端 ip，
+	// 不配置的时候直接用连接的地址，避免客户端伪造 X-Forwarded-For 绕过按 ip 的限流
+	TrustedProxies  []string `mapstructure:"trusted-proxies" json:"trusted-proxies" yaml:"trusted-proxies"`
+	RemoteIPHeaders []string `mapstructure:"remote-ip-headers" json:"remote-ip-headers" yaml:"remote-ip-headers"`
 }
 
 type Server gin.Engine
 
 // NewServer 创建server
-func NewServer(middlewares []gin.HandlerFunc,
+func NewServer(c *Config,
+	middlewares []gin.HandlerFunc,
 	uh *handler.UserHandler,
-	ah *handler.ArticleHandler) *gin.Engine {
+	ah *handler.ArticleHandler,
+	oh *handler.OAuth2Handler,
+	ach *handler.AccountHandler,
+	adh *handler.AdminHandler,
+	fh *handler.FollowHandler,
+	feh *handler.FeedHandler,
+	ch *handler.CommentHandler,
+	nh *handler.NotificationHandler,
+	ph *handler.PushHandler,
+	mh *handler.MessageHandler,
+	bh *handler.BlockHandler,
+	rh *handler.ReportHandler,
+	sh *handler.SmsHandler,
+	cph *handler.CaptchaHandler) *gin.Engine {
 	server := gin.Default()
+	if err := server.SetTrustedProxies(c.ServerConfig.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("配置可信代理失败: %v", err))
+	}
+	if len(c.ServerConfig.RemoteIPHeaders) > 0 {
+		server.RemoteIPHeaders = c.ServerConfig.RemoteIPHeaders
+	}
 
 	server.Use(middlewares...)
 	uh.RegisterRoutes(server)
 	ah.RegisterRoutes(server)
+	oh.RegisterRoutes(server)
+	ach.RegisterRoutes(server)
+	adh.RegisterRoutes(server)
+	fh.RegisterRoutes(server)
+	feh.RegisterRoutes(server)
+	ch.RegisterRoutes(server)
+	nh.RegisterRoutes(server)
+	ph.RegisterRoutes(server)
+	mh.RegisterRoutes(server)
+	bh.RegisterRoutes(server)
+	rh.RegisterRoutes(server)
+	sh.RegisterRoutes(server)
+	cph.RegisterRoutes(server)
 	return server
 }
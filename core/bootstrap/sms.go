@@ -0,0 +1,174 @@
+package bootstrap
+
+import (
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	tencentsms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// SmsConfig 短信服务配置
+type SmsConfig struct {
+	MaxAttempts int                          `mapstructure:"max-attempts" json:"max-attempts" yaml:"max-attempts"` // 发送失败之后最多尝试的次数，包括第一次
+	Providers   []string                     `mapstructure:"providers" json:"providers" yaml:"providers"`          // 使用的服务商，多个的时候按熔断自动切换，为空的时候只打印到控制台
+	Templates   map[string]SmsTemplateConfig `mapstructure:"templates" json:"templates" yaml:"templates"`          // 逻辑模板，key 是 sms.TemplateLoginCode 这些
+	Tencent     *SmsTencentConfig            `mapstructure:"tencent" json:"tencent" yaml:"tencent"`
+	Aliyun      *SmsAliyunConfig             `mapstructure:"aliyun" json:"aliyun" yaml:"aliyun"`
+	Webhook     *SmsWebhookConfig            `mapstructure:"webhook" json:"webhook" yaml:"webhook"`
+	Callback    *SmsCallbackConfig           `mapstructure:"callback" json:"callback" yaml:"callback"` // 服务商推送送达回执
+}
+
+type SmsTencentConfig struct {
+	SecretId  string `mapstructure:"secret-id" json:"secret-id" yaml:"secret-id"`
+	SecretKey string `mapstructure:"secret-key" json:"secret-key" yaml:"secret-key"`
+	Region    string `mapstructure:"region" json:"region" yaml:"region"`
+	AppId     string `mapstructure:"app-id" json:"app-id" yaml:"app-id"`
+	Signature string `mapstructure:"signature" json:"signature" yaml:"signature"`
+}
+
+type SmsAliyunConfig struct {
+	Endpoint        string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"` // 为空的时候用阿里云的默认地址
+	AccessKeyId     string `mapstructure:"access-key-id" json:"access-key-id" yaml:"access-key-id"`
+	AccessKeySecret string `mapstructure:"access-key-secret" json:"access-key-secret" yaml:"access-key-secret"`
+	RegionId        string `mapstructure:"region-id" json:"region-id" yaml:"region-id"`
+	Signature       string `mapstructure:"signature" json:"signature" yaml:"signature"`
+}
+
+type SmsWebhookConfig struct {
+	Url       string `mapstructure:"url" json:"url" yaml:"url"`
+	Secret    string `mapstructure:"secret" json:"secret" yaml:"secret"` // 请求签名用的密钥
+	Signature string `mapstructure:"signature" json:"signature" yaml:"signature"`
+}
+
+// SmsCallbackConfig 每个服务商一个回执密钥，key 是服务商的名字。
+// 阿里云和腾讯云的回执地址是 /sms/callback/{provider}/{secret}，在服务商的控制台配置；
+// webhook 服务商的回执地址是 /sms/callback/webhook，请求头里带 X-Sms-Timestamp 和 X-Sms-Signature
+type SmsCallbackConfig struct {
+	Secrets map[string]string `mapstructure:"secrets" json:"secrets" yaml:"secrets"`
+}
+
+// SmsTemplateConfig 逻辑模板的参数个数和每个服务商对应的模板
+type SmsTemplateConfig struct {
+	Params    int                                  `mapstructure:"params" json:"params" yaml:"params"`
+	Providers map[string]SmsProviderTemplateConfig `mapstructure:"providers" json:"providers" yaml:"providers"`
+}
+
+type SmsProviderTemplateConfig struct {
+	Id         string   `mapstructure:"id" json:"id" yaml:"id"`
+	Signature  string   `mapstructure:"signature" json:"signature" yaml:"signature"`
+	ParamNames []string `mapstructure:"param-names" json:"param-names" yaml:"param-names"` // 按名字传参的服务商用
+}
+
+// NewSmsTemplateRegistry 没有配置模板的时候只有验证码模板
+func NewSmsTemplateRegistry(c *Config) sms.TemplateRegistry {
+	if c.SmsConfig == nil || len(c.SmsConfig.Templates) == 0 {
+		return sms.NewTemplateRegistry(map[string]sms.Template{
+			sms.TemplateLoginCode:     {Params: 1},
+			sms.TemplatePasswordReset: {Params: 1},
+		})
+	}
+	templates := make(map[string]sms.Template, len(c.SmsConfig.Templates))
+	for name, tc := range c.SmsConfig.Templates {
+		providers := make(map[string]sms.ProviderTemplate, len(tc.Providers))
+		for provider, pc := range tc.Providers {
+			providers[provider] = sms.ProviderTemplate{
+				Id:         pc.Id,
+				Signature:  pc.Signature,
+				ParamNames: pc.ParamNames,
+			}
+		}
+		templates[name] = sms.Template{
+			Params:    tc.Params,
+			Providers: providers,
+		}
+	}
+	return sms.NewTemplateRegistry(templates)
+}
+
+// NewSmsService 发送失败的短信落库之后后台重试，没有配置的时候最多尝试 5 次，返回的函数用来停止后台重试
+func NewSmsService(c *Config, registry sms.TemplateRegistry, repo repository.SmsTaskRepository,
+	recordRepo repository.SmsRecordRepository, l *zap.Logger) (sms.SmsService, func()) {
+	maxAttempts := 5
+	if c.SmsConfig != nil && c.SmsConfig.MaxAttempts > 0 {
+		maxAttempts = c.SmsConfig.MaxAttempts
+	}
+	return sms.NewAsyncService(newSmsProvider(c, registry, recordRepo, l), repo, maxAttempts, l)
+}
+
+// NewSmsRecordService 回执密钥从配置里读，启用的服务商没有配置密钥的时候启动失败，不然回执永远更新不了
+func NewSmsRecordService(c *Config, repo repository.SmsRecordRepository, l *zap.Logger) service.SmsRecordService {
+	secrets := map[string]string{}
+	if c.SmsConfig == nil {
+		return service.NewSmsRecordService(repo, secrets, l)
+	}
+	if c.SmsConfig.Callback != nil {
+		secrets = c.SmsConfig.Callback.Secrets
+	}
+	for _, name := range c.SmsConfig.Providers {
+		if secrets[name] == "" {
+			panic(fmt.Sprintf("短信服务商 %s 没有配置回执密钥 sms.callback.secrets.%s", name, name))
+		}
+	}
+	return service.NewSmsRecordService(repo, secrets, l)
+}
+
+// newSmsProvider 按配置创建服务商，配置不全的服务商跳过，每个服务商单独记录发送结果
+func newSmsProvider(c *Config, registry sms.TemplateRegistry, recordRepo repository.SmsRecordRepository,
+	l *zap.Logger) sms.SmsService {
+	var providers []sms.SmsService
+	if c.SmsConfig != nil {
+		client := &http.Client{Timeout: time.Second * 5}
+		for _, name := range c.SmsConfig.Providers {
+			svc := newNamedSmsProvider(c.SmsConfig, name, client, registry)
+			if svc == nil {
+				l.Error("短信服务商配置不完整", zap.String("provider", name))
+				continue
+			}
+			providers = append(providers, sms.NewAuditService(svc, name, recordRepo, l))
+		}
+	}
+	switch len(providers) {
+	case 0:
+		return sms.NewAuditService(sms.NewMemoryService(registry, l), sms.ProviderMemory, recordRepo, l)
+	case 1:
+		return providers[0]
+	default:
+		return sms.NewCircuitBreakerService(providers, sms.DefaultBreakerConfig(), l)
+	}
+}
+
+func newNamedSmsProvider(c *SmsConfig, name string, client *http.Client, registry sms.TemplateRegistry) sms.SmsService {
+	switch name {
+	case sms.ProviderTencent:
+		tc := c.Tencent
+		if tc == nil || tc.SecretId == "" {
+			return nil
+		}
+		tencentClient, err := tencentsms.NewClient(common.NewCredential(tc.SecretId, tc.SecretKey),
+			tc.Region, profile.NewClientProfile())
+		if err != nil {
+			return nil
+		}
+		return sms.NewTencentSmsService(tencentClient, tc.AppId, tc.Signature, registry)
+	case sms.ProviderAliyun:
+		ac := c.Aliyun
+		if ac == nil || ac.AccessKeyId == "" {
+			return nil
+		}
+		return sms.NewAliyunSmsService(client, ac.Endpoint, ac.AccessKeyId, ac.AccessKeySecret,
+			ac.RegionId, ac.Signature, registry)
+	case sms.ProviderWebhook:
+		wc := c.Webhook
+		if wc == nil || wc.Url == "" {
+			return nil
+		}
+		return sms.NewWebhookSmsService(client, wc.Url, wc.Secret, wc.Signature, registry)
+	}
+	return nil
+}
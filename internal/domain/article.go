@@ -7,6 +7,8 @@ const (
 	ArticleStatusUnpublished
 	ArticleStatusPublished
 	ArticleStatusPrivate
+	// ArticleStatusTakenDown 被管理员下架，作者不能再修改或重新发布
+	ArticleStatusTakenDown
 )
 
 func (s ArticleStates) ToUint8() uint8 {
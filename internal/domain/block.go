@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+type BlockType uint8
+
+const (
+	BlockTypeUnknown BlockType = iota
+	// BlockTypeMute 屏蔽，看不到对方的内容，对方不受影响
+	BlockTypeMute
+	// BlockTypeBlock 拉黑，在屏蔽的基础上对方不能再关注、评论、私信自己
+	BlockTypeBlock
+)
+
+func (t BlockType) ToUint8() uint8 {
+	return uint8(t)
+}
+
+type BlockRelation struct {
+	Uid    int64
+	Target int64
+	Type   BlockType
+	Ctime  time.Time
+}
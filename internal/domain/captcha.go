@@ -0,0 +1,15 @@
+package domain
+
+// Captcha 图形验证码，答案只保存在服务端
+type Captcha struct {
+	Id     string
+	Answer string
+	// Image PNG 图片
+	Image []byte
+}
+
+// CaptchaAnswer 用户提交的图形验证码
+type CaptchaAnswer struct {
+	Id    string
+	Value string
+}
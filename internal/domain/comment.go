@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Comment 评论，RootId 为 0 的是根评论，回复的 RootId 指向所在的根评论，ParentId 指向直接回复的评论
+type Comment struct {
+	Id       int64
+	Uid      int64
+	Biz      string
+	BizId    int64
+	RootId   int64
+	ParentId int64
+	Content  string
+	// ReplyCount 根评论下面的回复数，只有根评论有
+	ReplyCount int64
+	LikeCount  int64
+	Ctime      time.Time
+}
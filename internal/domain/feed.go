@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// FeedCursor 关注流的分页游标，按发布时间和文章 id 倒序，零值表示从最新的开始
+type FeedCursor struct {
+	Ctime     int64
+	ArticleId int64
+}
+
+// FeedItem 关注流里的一篇文章
+type FeedItem struct {
+	Article Article
+	// Ctime 文章第一次发布的时间
+	Ctime time.Time
+}
+
+// Before 游标按发布时间和文章 id 倒序，排在 o 后面返回 true
+func (c FeedCursor) Before(o FeedCursor) bool {
+	if c.Ctime != o.Ctime {
+		return c.Ctime < o.Ctime
+	}
+	return c.ArticleId < o.ArticleId
+}
+
+// Cursor 以这一条作为下一页的游标
+func (f FeedItem) Cursor() FeedCursor {
+	return FeedCursor{
+		Ctime:     f.Ctime.UnixMilli(),
+		ArticleId: f.Article.Id,
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// FollowRelation 关注关系，Follower 关注了 Followee
+type FollowRelation struct {
+	Follower int64
+	Followee int64
+	Ctime    time.Time
+}
+
+// FollowStatics 用户的关注统计
+type FollowStatics struct {
+	// Followers 粉丝数
+	Followers int64
+	// Followees 关注数
+	Followees int64
+}
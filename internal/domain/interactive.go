@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	BizArticle = "article"
+	BizComment = "comment"
+	BizUser    = "user"
+)
+
+// Interactive 阅读、点赞、评论等计数
+type Interactive struct {
+	Biz          string
+	BizId        int64
+	ReadCount    int64
+	LikeCount    int64
+	CommentCount int64
+}
+
+// Like 用户的点赞记录
+type Like struct {
+	Biz   string
+	BizId int64
+	Ctime time.Time
+}
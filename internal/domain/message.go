@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Message 私信
+type Message struct {
+	Id       int64
+	Sender   int64
+	Receiver int64
+	Content  string
+	Ctime    time.Time
+}
+
+// Conversation 用户视角的会话，每个人各有一份，Unread 是自己没读的条数
+type Conversation struct {
+	Uid         int64
+	Peer        int64
+	LastMessage Message
+	Unread      int64
+	Utime       time.Time
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type NotificationType uint8
+
+const (
+	NotificationTypeUnknown NotificationType = iota
+	// NotificationTypeLike 内容被点赞
+	NotificationTypeLike
+	// NotificationTypeComment 文章被评论
+	NotificationTypeComment
+	// NotificationTypeReply 评论被回复
+	NotificationTypeReply
+	// NotificationTypeFollow 被关注
+	NotificationTypeFollow
+)
+
+func (t NotificationType) ToUint8() uint8 {
+	return uint8(t)
+}
+
+// Notification 站内通知，同一个对象上未读的同类通知合并成一条，
+// LastActor 是最近一次触发的用户，ActorCount 是合并进来的次数，用来展示 "A 等 10 人赞了你的文章"
+type Notification struct {
+	Id         int64
+	Uid        int64
+	Type       NotificationType
+	Biz        string
+	BizId      int64
+	LastActor  int64
+	ActorCount int64
+	Read       bool
+	Ctime      time.Time
+	Utime      time.Time
+}
@@ -0,0 +1,11 @@
+package domain
+
+// OAuth2Info 第三方登录换取到的用户信息
+type OAuth2Info struct {
+	// Provider 提供方，例如 github、wechat
+	Provider string
+	// OpenId 用户在提供方的唯一标识
+	OpenId string
+	Name   string
+	Email  string
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "encoding/json"
+
+// PushEvent 推送给在线用户的实时事件，Data 是已经序列化好的 JSON
+type PushEvent struct {
+	Uid  int64           `json:"uid"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+const (
+	// PushTypeNotification 有新的站内通知
+	PushTypeNotification = "notification"
+	// PushTypeMessage 收到新私信
+	PushTypeMessage = "message"
+)
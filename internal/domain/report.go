@@ -0,0 +1,87 @@
+package domain
+
+import "time"
+
+type ReportStatus uint8
+
+const (
+	ReportStatusUnknown ReportStatus = iota
+	// ReportStatusPending 等待审核员认领
+	ReportStatusPending
+	// ReportStatusClaimed 已被审核员认领，只有认领的人可以处理
+	ReportStatusClaimed
+	ReportStatusResolved
+)
+
+func (s ReportStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+type ReportAction uint8
+
+const (
+	ReportActionUnknown ReportAction = iota
+	// ReportActionDismiss 驳回，不处理举报对象
+	ReportActionDismiss
+	// ReportActionHideArticle 下架文章，作者不能再修改或重新发布
+	ReportActionHideArticle
+	// ReportActionDeleteComment 删除评论和它下面的回复
+	ReportActionDeleteComment
+)
+
+func (a ReportAction) ToUint8() uint8 {
+	return uint8(a)
+}
+
+// Valid 处理方式要和举报对象对得上
+func (a ReportAction) Valid(biz string) bool {
+	switch a {
+	case ReportActionDismiss:
+		return true
+	case ReportActionHideArticle:
+		return biz == BizArticle
+	case ReportActionDeleteComment:
+		return biz == BizComment
+	default:
+		return false
+	}
+}
+
+type Report struct {
+	Id int64
+	// Reporter 为 0 的是命中敏感词之后系统自动提交的
+	Reporter  int64
+	Biz       string
+	BizId     int64
+	Reason    string
+	Status    ReportStatus
+	Moderator int64
+	Action    ReportAction
+	Ctime     time.Time
+	Utime     time.Time
+}
+
+type ModerationOperation uint8
+
+const (
+	ModerationOperationUnknown ModerationOperation = iota
+	ModerationOperationClaim
+	ModerationOperationResolve
+)
+
+func (o ModerationOperation) ToUint8() uint8 {
+	return uint8(o)
+}
+
+// ModerationLog 审核员的操作记录，只增不改
+type ModerationLog struct {
+	Id        int64
+	Moderator int64
+	ReportId  int64
+	Biz       string
+	BizId     int64
+	Operation ModerationOperation
+	Action    ReportAction
+	Remark    string
+	Ctime     time.Time
+}
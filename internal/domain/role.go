@@ -0,0 +1,50 @@
+package domain
+
+const (
+	// RoleAdmin 管理员，拥有全部权限
+	RoleAdmin = "admin"
+	// RoleModerator 内容审核员，可以封禁用户、下架文章和处理举报
+	RoleModerator = "moderator"
+)
+
+const (
+	PermissionUserBan         = "user:ban"
+	PermissionArticleTakedown = "article:takedown"
+	PermissionRoleGrant       = "role:grant"
+	PermissionReportHandle    = "report:handle"
+	PermissionSmsQuery        = "sms:query"
+)
+
+// rolePermissions 角色拥有的权限，普通用户没有角色
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermissionUserBan,
+		PermissionArticleTakedown,
+		PermissionRoleGrant,
+		PermissionReportHandle,
+		PermissionSmsQuery,
+	},
+	RoleModerator: {
+		PermissionUserBan,
+		PermissionArticleTakedown,
+		PermissionReportHandle,
+	},
+}
+
+// RoleExists 判断角色是否存在
+func RoleExists(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission 任意一个角色拥有该权限即可
+func HasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
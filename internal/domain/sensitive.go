@@ -0,0 +1,20 @@
+package domain
+
+type SensitivePolicy uint8
+
+const (
+	// SensitivePolicyReject 拒绝保存，把命中的位置返回给前端
+	SensitivePolicyReject SensitivePolicy = iota + 1
+	// SensitivePolicyMask 敏感词替换成 * 之后保存
+	SensitivePolicyMask
+	// SensitivePolicyReview 原样保存，内容公开之后以系统的身份提交到举报审核队列
+	SensitivePolicyReview
+)
+
+// SensitiveHit 命中的敏感词，Field 是命中的字段，Start 和 End 是按字符算的位置，左闭右开
+type SensitiveHit struct {
+	Field string
+	Word  string
+	Start int
+	End   int
+}
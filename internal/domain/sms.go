@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+type SmsTaskStatus uint8
+
+const (
+	SmsTaskStatusUnknown SmsTaskStatus = iota
+	// SmsTaskStatusWaiting 等待重试
+	SmsTaskStatusWaiting
+	// SmsTaskStatusSending 被某个实例抢占，正在发送
+	SmsTaskStatusSending
+	SmsTaskStatusSuccess
+	// SmsTaskStatusFailed 重试次数用完还是失败
+	SmsTaskStatusFailed
+)
+
+func (s SmsTaskStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+// SmsTask 发送失败之后落库等待重试的短信
+type SmsTask struct {
+	Id       int64
+	Tpl      string
+	Args     []string
+	Numbers  []string
+	Status   SmsTaskStatus
+	Attempts int
+	// NextTime 下一次重试的时间
+	NextTime time.Time
+	// LastError 最后一次失败的原因
+	LastError string
+	Ctime     time.Time
+	Utime     time.Time
+}
+
+type SmsRecordStatus uint8
+
+const (
+	SmsRecordStatusUnknown SmsRecordStatus = iota
+	// SmsRecordStatusFailed 服务商没有接受
+	SmsRecordStatusFailed
+	// SmsRecordStatusSent 服务商已经接受，等待回执
+	SmsRecordStatusSent
+	SmsRecordStatusDelivered
+	// SmsRecordStatusUndelivered 回执显示没有送达
+	SmsRecordStatusUndelivered
+)
+
+func (s SmsRecordStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+// SmsRecord 每次调用服务商发送短信的记录，一个号码一条，不保存完整的手机号
+type SmsRecord struct {
+	Id       int64
+	Provider string
+	Tpl      string
+	// Phone 打码之后的手机号
+	Phone string
+	// PhoneHash 按手机号查询和匹配回执用
+	PhoneHash string
+	// RequestId 服务商返回的请求 id，回执用它对应到这条记录
+	RequestId string
+	Latency   time.Duration
+	Status    SmsRecordStatus
+	// Error 发送失败或者没有送达的原因
+	Error        string
+	DeliveryTime time.Time
+	Ctime        time.Time
+	Utime        time.Time
+}
+
+// SmsReceipt 服务商的送达回执
+type SmsReceipt struct {
+	RequestId string
+	Phone     string
+	Delivered bool
+	Error     string
+	Time      time.Time
+}
+
+// MaskPhone 只保留前三位和后四位
+func MaskPhone(phone string) string {
+	runes := []rune(phone)
+	if len(runes) <= 7 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:3]) + strings.Repeat("*", len(runes)-7) + string(runes[len(runes)-4:])
+}
+
+// HashPhone 按手机号查询发送记录用，数据库里不保存明文，国内号码带不带 +86 是同一个
+func HashPhone(phone string) string {
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(phone, "+86")))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,9 +1,35 @@
 package domain
 
+type UserStatus uint8
+
+const (
+	UserStatusNormal UserStatus = iota
+	// UserStatusBanned 被管理员封禁
+	UserStatusBanned
+)
+
 // User 用户业务对象
 type User struct {
 	Id       int64
 	Email    string
 	Password string
 	Phone    string
+	// Verified 邮箱或手机号已经验证过归属
+	Verified bool
+	// GithubId GitHub 登录绑定的用户 id
+	GithubId string
+	// WechatOpenId 微信登录绑定的 openid
+	WechatOpenId string
+	Status       UserStatus
+}
+
+// UserArchive 用户个人数据导出
+type UserArchive struct {
+	User     User
+	Articles []Article
+	Likes    []Like
+	Comments []Comment
+	// Follows 关注别人和被别人关注的关系
+	Follows  []FollowRelation
+	Messages []Message
 }
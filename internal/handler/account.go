@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+const bizDeleteAccount = "delete_account"
+
+var _ Handler = (*AccountHandler)(nil)
+
+type AccountHandler struct {
+	svc     service.AccountService
+	userSvc service.UserService
+	codeSvc service.CodeService
+	logger  *zap.Logger
+}
+
+func NewAccountHandler(svc service.AccountService, userSvc service.UserService, codeSvc service.CodeService, l *zap.Logger) *AccountHandler {
+	return &AccountHandler{
+		svc:     svc,
+		userSvc: userSvc,
+		codeSvc: codeSvc,
+		logger:  l,
+	}
+}
+
+func (ach *AccountHandler) RegisterRoutes(server *gin.Engine) {
+	ug := server.Group("/users")
+	ug.POST("/delete/code", wrapper.WrapperBodyWitJwt[vo.SendDeleteAccountCodeRequest](ach.logger, ach.SendDeleteCode))
+	ug.POST("/delete", wrapper.WrapperBodyWitJwt[vo.DeleteAccountRequest](ach.logger, ach.Delete))
+	ug.GET("/export", ach.Export)
+}
+
+// SendDeleteCode 没有设置密码的用户注销账号前获取验证码，优先发送到手机号
+func (ach *AccountHandler) SendDeleteCode(ctx *gin.Context, req vo.SendDeleteAccountCodeRequest, uc *jwt.UserClaims) (result.Result, error) {
+	u, err := ach.userSvc.Profile(ctx, uc.Uid)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	switch {
+	case u.Phone != "":
+		err = ach.codeSvc.Send(ctx, bizDeleteAccount, u.Phone, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest))
+	case u.Email != "":
+		err = ach.codeSvc.SendByEmail(ctx, bizDeleteAccount, u.Email, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest))
+	default:
+		return result.FailWithMsg("请先绑定手机号或邮箱"), nil
+	}
+	return sendCodeResult(err)
+}
+
+// Delete 注销账号，设置过密码的用户校验密码，否则校验验证码
+func (ach *AccountHandler) Delete(ctx *gin.Context, req vo.DeleteAccountRequest, uc *jwt.UserClaims) (result.Result, error) {
+	u, err := ach.userSvc.Profile(ctx, uc.Uid)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	switch {
+	case req.Password != "":
+		err = ach.userSvc.VerifyPassword(ctx, uc.Uid, req.Password)
+		if errors.Is(err, service.ErrInvalidPassword) {
+			return result.FailWithMsg("密码错误"), nil
+		}
+		if err != nil {
+			return result.FailWithMsg("系统异常"), err
+		}
+	case req.Code != "":
+		target := u.Phone
+		if target == "" {
+			target = u.Email
+		}
+		if res, ok, err := verifyCode(ctx, ach.codeSvc, bizDeleteAccount, target, req.Code); !ok {
+			return res, err
+		}
+	default:
+		return result.FailWithMsg("请输入密码或验证码"), nil
+	}
+	if err = ach.svc.Delete(ctx, uc.Uid); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("注销账号成功"), nil
+}
+
+// Export 把个人资料、文章、点赞、评论、关注和私信记录打包成 zip 下载
+func (ach *AccountHandler) Export(ctx *gin.Context) {
+	uc, err := jwt.ExtractJwtClaims(ctx)
+	if err != nil || uc == nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	archive, err := ach.svc.Export(ctx, uc.Uid)
+	if err != nil {
+		ach.logger.Error("导出个人数据失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
+	}
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=little-blue-book-%d.zip", uc.Uid))
+	ctx.Status(http.StatusOK)
+	if err = ach.writeArchive(ctx.Writer, archive); err != nil {
+		// 响应头已经发出去了，只能记录日志
+		ach.logger.Error("写入导出文件失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+	}
+}
+
+func (ach *AccountHandler) writeArchive(w http.ResponseWriter, archive domain.UserArchive) error {
+	zw := zip.NewWriter(w)
+	files := map[string]any{
+		"profile.json": vo.ProfileArchiveVo{
+			Id:           archive.User.Id,
+			Email:        archive.User.Email,
+			Phone:        archive.User.Phone,
+			Verified:     archive.User.Verified,
+			GithubId:     archive.User.GithubId,
+			WechatOpenId: archive.User.WechatOpenId,
+		},
+		"articles.json": slice.Map[domain.Article, vo.ArticleArchiveVo](archive.Articles, func(idx int, src domain.Article) vo.ArticleArchiveVo {
+			return vo.ArticleArchiveVo{
+				Id:      src.Id,
+				Title:   src.Title,
+				Content: src.Content,
+				Status:  src.Status.ToUint8(),
+			}
+		}),
+		"likes.json": slice.Map[domain.Like, vo.LikeArchiveVo](archive.Likes, func(idx int, src domain.Like) vo.LikeArchiveVo {
+			return vo.LikeArchiveVo{
+				Biz:   src.Biz,
+				BizId: src.BizId,
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		}),
+		"comments.json": slice.Map[domain.Comment, vo.CommentArchiveVo](archive.Comments, func(idx int, src domain.Comment) vo.CommentArchiveVo {
+			return vo.CommentArchiveVo{
+				Id:       src.Id,
+				Biz:      src.Biz,
+				BizId:    src.BizId,
+				ParentId: src.ParentId,
+				Content:  src.Content,
+				Ctime:    src.Ctime.Format(time.DateTime),
+			}
+		}),
+		"follows.json": slice.Map[domain.FollowRelation, vo.FollowArchiveVo](archive.Follows, func(idx int, src domain.FollowRelation) vo.FollowArchiveVo {
+			return vo.FollowArchiveVo{
+				Follower: src.Follower,
+				Followee: src.Followee,
+				Ctime:    src.Ctime.Format(time.DateTime),
+			}
+		}),
+		"messages.json": slice.Map[domain.Message, vo.MessageArchiveVo](archive.Messages, func(idx int, src domain.Message) vo.MessageArchiveVo {
+			return vo.MessageArchiveVo{
+				Id:       src.Id,
+				Sender:   src.Sender,
+				Receiver: src.Receiver,
+				Content:  src.Content,
+				Ctime:    src.Ctime.Format(time.DateTime),
+			}
+		}),
+	}
+	for name, data := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
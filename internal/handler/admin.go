@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/middleware"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var _ Handler = (*AdminHandler)(nil)
+
+// AdminHandler 管理后台，每个分组单独校验权限
+type AdminHandler struct {
+	userSvc    service.UserService
+	articleSvc service.ArticleService
+	roleSvc    service.RoleService
+	sessionSvc service.SessionService
+	logger     *zap.Logger
+}
+
+func NewAdminHandler(userSvc service.UserService,
+	articleSvc service.ArticleService,
+	roleSvc service.RoleService,
+	sessionSvc service.SessionService,
+	l *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		userSvc:    userSvc,
+		articleSvc: articleSvc,
+		roleSvc:    roleSvc,
+		sessionSvc: sessionSvc,
+		logger:     l,
+	}
+}
+
+func (adh *AdminHandler) RegisterRoutes(server *gin.Engine) {
+	ag := server.Group("/admin")
+
+	ug := ag.Group("/users", middleware.NewPermissionBuilder(domain.PermissionUserBan).Build())
+	ug.POST("/ban", wrapper.WrapperBodyWitJwt[vo.BanUserRequest](adh.logger, adh.BanUser))
+	ug.POST("/unban", wrapper.WrapperBodyWitJwt[vo.UnbanUserRequest](adh.logger, adh.UnbanUser))
+
+	atg := ag.Group("/articles", middleware.NewPermissionBuilder(domain.PermissionArticleTakedown).Build())
+	atg.POST("/takedown", wrapper.WrapperBodyWitJwt[vo.TakedownArticleRequest](adh.logger, adh.TakedownArticle))
+
+	rg := ag.Group("/roles", middleware.NewPermissionBuilder(domain.PermissionRoleGrant).Build())
+	rg.POST("/grant", wrapper.WrapperBodyWitJwt[vo.GrantRoleRequest](adh.logger, adh.GrantRole))
+	rg.POST("/revoke", wrapper.WrapperBodyWitJwt[vo.RevokeRoleRequest](adh.logger, adh.RevokeRole))
+}
+
+// BanUser 封禁用户，已经登录的设备全部下线
+func (adh *AdminHandler) BanUser(ctx *gin.Context, req vo.BanUserRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if req.Uid == uc.Uid {
+		return result.FailWithMsg("不能封禁自己"), nil
+	}
+	err := adh.userSvc.Ban(ctx, req.Uid)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return result.FailWithMsg("用户不存在"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	if _, err = adh.sessionSvc.Revoke(ctx, req.Uid); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	adh.logger.Info("封禁用户", zap.Int64("operator", uc.Uid), zap.Int64("uid", req.Uid))
+	return result.SuccessWithMsg("封禁用户成功"), nil
+}
+
+func (adh *AdminHandler) UnbanUser(ctx *gin.Context, req vo.UnbanUserRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := adh.userSvc.Unban(ctx, req.Uid)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return result.FailWithMsg("用户不存在"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	adh.logger.Info("解封用户", zap.Int64("operator", uc.Uid), zap.Int64("uid", req.Uid))
+	return result.SuccessWithMsg("解封用户成功"), nil
+}
+
+func (adh *AdminHandler) TakedownArticle(ctx *gin.Context, req vo.TakedownArticleRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := adh.articleSvc.Takedown(ctx, req.Id)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		return result.FailWithMsg("文章不存在"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	adh.logger.Info("下架文章", zap.Int64("operator", uc.Uid), zap.Int64("articleId", req.Id))
+	return result.SuccessWithMsg("下架文章成功"), nil
+}
+
+func (adh *AdminHandler) GrantRole(ctx *gin.Context, req vo.GrantRoleRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := adh.roleSvc.Grant(ctx, req.Uid, req.Role)
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		return result.FailWithMsg("角色不存在"), nil
+	case errors.Is(err, service.ErrUserNotFound):
+		return result.FailWithMsg("用户不存在"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	adh.logger.Info("授予角色", zap.Int64("operator", uc.Uid), zap.Int64("uid", req.Uid), zap.String("role", req.Role))
+	return result.SuccessWithMsg("授予角色成功"), nil
+}
+
+func (adh *AdminHandler) RevokeRole(ctx *gin.Context, req vo.RevokeRoleRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if req.Uid == uc.Uid && req.Role == domain.RoleAdmin {
+		return result.FailWithMsg("不能收回自己的管理员角色"), nil
+	}
+	err := adh.roleSvc.Revoke(ctx, req.Uid, req.Role)
+	if errors.Is(err, service.ErrRoleNotFound) {
+		return result.FailWithMsg("角色不存在"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	adh.logger.Info("收回角色", zap.Int64("operator", uc.Uid), zap.Int64("uid", req.Uid), zap.String("role", req.Role))
+	return result.SuccessWithMsg("收回角色成功"), nil
+}
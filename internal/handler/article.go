@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"github.com/ChongYanOvO/little-blue-book/internal/domain"
 	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
 	"github.com/ChongYanOvO/little-blue-book/internal/service"
@@ -18,12 +19,15 @@ type ArticleHandler struct {
 	logger         *zap.Logger
 	svc            service.ArticleService
 	interactiveSvc service.InteractiveService
+	feedSvc        service.FeedService
 }
 
-func NewArticleHandler(svc service.ArticleService, interactiveSvc service.InteractiveService, l *zap.Logger) *ArticleHandler {
+func NewArticleHandler(svc service.ArticleService, interactiveSvc service.InteractiveService, feedSvc service.FeedService,
+	l *zap.Logger) *ArticleHandler {
 	return &ArticleHandler{
 		svc:            svc,
 		interactiveSvc: interactiveSvc,
+		feedSvc:        feedSvc,
 		logger:         l,
 	}
 }
@@ -45,6 +49,9 @@ func (ah *ArticleHandler) Save(ctx *gin.Context, req vo.CreateArticleRequest, uc
 			Id: uc.Uid,
 		},
 	})
+	if res, ok := sensitiveResult(err); ok {
+		return res, nil
+	}
 	if err != nil {
 		ah.logger.Error("保存文章失败", zap.Error(err))
 		return result.FailWithMsg("保存文章失败"), err
@@ -68,6 +75,9 @@ func (ah *ArticleHandler) Edit(ctx *gin.Context, req vo.EditArticleRequest, uc *
 			Id: uc.Uid,
 		},
 	})
+	if res, ok := sensitiveResult(err); ok {
+		return res, nil
+	}
 	if err != nil {
 		return result.FailWithMsg("编辑文章失败"), err
 	}
@@ -83,15 +93,26 @@ func (ah *ArticleHandler) Publish(ctx *gin.Context, req vo.PublishArticleRequest
 			Id: uc.Uid,
 		},
 	})
+	if errors.Is(err, service.ErrUserUnverified) {
+		return result.FailWithMsg("请先完成邮箱验证再发布文章"), nil
+	}
+	if res, ok := sensitiveResult(err); ok {
+		return res, nil
+	}
+	if errors.Is(err, service.ErrArticleNotFound) {
+		return result.FailWithMsg("文章不存在或已被下架"), nil
+	}
 	if err != nil {
 		ah.logger.Error("发布文章失败", zap.Error(err))
 		return result.FailWithMsg("发布文章失败"), err
 	}
+
+	ah.feedSvc.Fanout(articleId)
 	return result.SuccessWithData("发布文章成功", articleId), err
 }
 
 func (ah *ArticleHandler) List(ctx *gin.Context, req vo.ListArticleRequest, uc *jwt.UserClaims) (result.Result, error) {
-	articles, err := ah.svc.List(ctx, req.Offset, req.Limit)
+	articles, err := ah.svc.List(ctx, uc.Uid, req.Offset, req.Limit)
 	if err != nil {
 		ah.logger.Error("获取文章列表失败", zap.Error(err))
 		return result.FailWithMsg("获取文章列表失败"), err
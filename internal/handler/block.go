@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ Handler = (*BlockHandler)(nil)
+
+type BlockHandler struct {
+	svc    service.BlockService
+	logger *zap.Logger
+}
+
+func NewBlockHandler(svc service.BlockService, l *zap.Logger) *BlockHandler {
+	return &BlockHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (bh *BlockHandler) RegisterRoutes(server *gin.Engine) {
+	bg := server.Group("/blocks")
+	bg.POST("/block", wrapper.WrapperBodyWitJwt[vo.BlockRequest](bh.logger, bh.Block))
+	bg.POST("/unblock", wrapper.WrapperBodyWitJwt[vo.BlockRequest](bh.logger, bh.Unblock))
+	bg.POST("/mute", wrapper.WrapperBodyWitJwt[vo.BlockRequest](bh.logger, bh.Mute))
+	bg.POST("/unmute", wrapper.WrapperBodyWitJwt[vo.BlockRequest](bh.logger, bh.Unmute))
+	bg.POST("/list", wrapper.WrapperBodyWitJwt[vo.ListBlockRequest](bh.logger, bh.List))
+}
+
+func (bh *BlockHandler) Block(ctx *gin.Context, req vo.BlockRequest, uc *jwt.UserClaims) (result.Result, error) {
+	return bh.result(bh.svc.Block(ctx, uc.Uid, req.Target), "拉黑成功")
+}
+
+func (bh *BlockHandler) Unblock(ctx *gin.Context, req vo.BlockRequest, uc *jwt.UserClaims) (result.Result, error) {
+	return bh.result(bh.svc.Unblock(ctx, uc.Uid, req.Target), "取消拉黑成功")
+}
+
+func (bh *BlockHandler) Mute(ctx *gin.Context, req vo.BlockRequest, uc *jwt.UserClaims) (result.Result, error) {
+	return bh.result(bh.svc.Mute(ctx, uc.Uid, req.Target), "屏蔽成功")
+}
+
+func (bh *BlockHandler) Unmute(ctx *gin.Context, req vo.BlockRequest, uc *jwt.UserClaims) (result.Result, error) {
+	return bh.result(bh.svc.Unmute(ctx, uc.Uid, req.Target), "取消屏蔽成功")
+}
+
+func (bh *BlockHandler) List(ctx *gin.Context, req vo.ListBlockRequest, uc *jwt.UserClaims) (result.Result, error) {
+	typ := domain.BlockType(req.Type)
+	if typ != domain.BlockTypeMute && typ != domain.BlockTypeBlock {
+		return result.FailWithMsg("类型不合法"), nil
+	}
+	relations, err := bh.svc.List(ctx, uc.Uid, typ, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取列表成功", slice.Map[domain.BlockRelation, vo.BlockVo](relations,
+		func(idx int, src domain.BlockRelation) vo.BlockVo {
+			return vo.BlockVo{
+				Uid:   src.Target,
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		})), nil
+}
+
+func (bh *BlockHandler) result(err error, msg string) (result.Result, error) {
+	switch {
+	case errors.Is(err, service.ErrBlockSelf):
+		return result.FailWithMsg("不能拉黑或屏蔽自己"), nil
+	case errors.Is(err, service.ErrUserNotFound):
+		return result.FailWithMsg("用户不存在"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg(msg), nil
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/base64"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+var _ Handler = (*CaptchaHandler)(nil)
+
+// CaptchaHandler 图形验证码，不需要登录
+type CaptchaHandler struct {
+	svc    service.CaptchaService
+	logger *zap.Logger
+}
+
+func NewCaptchaHandler(svc service.CaptchaService, l *zap.Logger) *CaptchaHandler {
+	return &CaptchaHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (ch *CaptchaHandler) RegisterRoutes(server *gin.Engine) {
+	server.GET("/captcha", ch.Generate)
+}
+
+// Generate 每次请求生成一个新的图形验证码
+func (ch *CaptchaHandler) Generate(ctx *gin.Context) {
+	c, err := ch.svc.Generate(ctx)
+	if err != nil {
+		ch.logger.Error("生成图形验证码失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, result.SuccessWithData("获取图形验证码成功", vo.CaptchaVo{
+		Id:    c.Id,
+		Image: "data:image/png;base64," + base64.StdEncoding.EncodeToString(c.Image),
+	}))
+}
+
+func captchaAnswer(req vo.CaptchaRequest) domain.CaptchaAnswer {
+	return domain.CaptchaAnswer{
+		Id:    req.CaptchaId,
+		Value: req.Captcha,
+	}
+}
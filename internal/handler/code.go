@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/errs"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/gin-gonic/gin"
+)
+
+// sendCodeResult 把发送验证码的结果转换成返回给前端的结果
+func sendCodeResult(err error) (result.Result, error) {
+	if res, ok := codeLimitResult(err); ok {
+		return res, nil
+	}
+	if errors.Is(err, service.ErrCodeSendTooMany) {
+		return result.FailWithMsg("验证码发送太频繁"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("验证码发送失败"), err
+	}
+	return result.SuccessWithMsg("验证码发送成功"), nil
+}
+
+// codeLimitResult 触发分层限流或者风控的时候带上错误码，前端按错误码提示用户，
+// 需要图形验证码的时候前端调用 /captcha 获取之后重新发送
+func codeLimitResult(err error) (result.Result, bool) {
+	switch {
+	case errors.Is(err, service.ErrCaptchaRequired):
+		return result.FailWithCode(errs.UserCaptchaRequired, "请先完成图形验证码"), true
+	case errors.Is(err, service.ErrInvalidCaptcha):
+		return result.FailWithCode(errs.UserInvalidCaptcha, "图形验证码错误，请重新获取"), true
+	case errors.Is(err, service.ErrCodePhoneLimited):
+		return result.FailWithCode(errs.UserCodePhoneLimited, "该手机号今日获取验证码次数已达上限"), true
+	case errors.Is(err, service.ErrCodeEmailLimited):
+		return result.FailWithCode(errs.UserCodeEmailLimited, "该邮箱今日获取验证码次数已达上限"), true
+	case errors.Is(err, service.ErrCodeIpLimited):
+		return result.FailWithCode(errs.UserCodeIpLimited, "当前网络获取验证码次数过多，请稍后再试"), true
+	case errors.Is(err, service.ErrCodeBizLimited):
+		return result.FailWithCode(errs.UserCodeBizLimited, "验证码发送繁忙，请稍后再试"), true
+	}
+	return result.Result{}, false
+}
+
+// verifyCode 校验验证码，没有通过的时候返回给前端的结果
+func verifyCode(ctx *gin.Context, codeSvc service.CodeService, biz, target, code string) (result.Result, bool, error) {
+	ok, err := codeSvc.Verify(ctx, biz, target, code)
+	if errors.Is(err, service.ErrCodeVerifyTooManyTimes) {
+		return result.FailWithMsg("验证次数太多，请重新获取验证码"), false, nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), false, err
+	}
+	if !ok {
+		return result.FailWithMsg("验证码错误"), false, nil
+	}
+	return result.Result{}, true, nil
+}
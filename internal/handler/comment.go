@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ Handler = (*CommentHandler)(nil)
+
+type CommentHandler struct {
+	svc    service.CommentService
+	logger *zap.Logger
+}
+
+func NewCommentHandler(svc service.CommentService, l *zap.Logger) *CommentHandler {
+	return &CommentHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (ch *CommentHandler) RegisterRoutes(server *gin.Engine) {
+	cg := server.Group("/comments")
+	cg.POST("/create", wrapper.WrapperBodyWitJwt[vo.CreateCommentRequest](ch.logger, ch.Create))
+	cg.POST("/list", wrapper.WrapperBodyWitJwt[vo.ListCommentRequest](ch.logger, ch.List))
+	cg.POST("/replies", wrapper.WrapperBodyWitJwt[vo.ListReplyRequest](ch.logger, ch.Replies))
+	cg.POST("/delete", wrapper.WrapperBodyWitJwt[vo.CommentIdRequest](ch.logger, ch.Delete))
+	cg.POST("/like", wrapper.WrapperBodyWitJwt[vo.CommentIdRequest](ch.logger, ch.Like))
+	cg.POST("/unlike", wrapper.WrapperBodyWitJwt[vo.CommentIdRequest](ch.logger, ch.CancelLike))
+}
+
+func (ch *CommentHandler) Create(ctx *gin.Context, req vo.CreateCommentRequest, uc *jwt.UserClaims) (result.Result, error) {
+	id, err := ch.svc.Create(ctx, domain.Comment{
+		Uid:      uc.Uid,
+		Biz:      req.Biz,
+		BizId:    req.BizId,
+		ParentId: req.ParentId,
+		Content:  req.Content,
+	})
+	if res, ok := sensitiveResult(err); ok {
+		return res, nil
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidComment):
+		return result.FailWithMsg("评论内容不合法"), nil
+	case errors.Is(err, service.ErrArticleNotFound):
+		return result.FailWithMsg("文章不存在"), nil
+	case errors.Is(err, service.ErrCommentNotFound):
+		return result.FailWithMsg("回复的评论不存在"), nil
+	case errors.Is(err, service.ErrBlocked):
+		return result.FailWithMsg("对方已将你拉黑"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("评论成功", id), nil
+}
+
+func (ch *CommentHandler) List(ctx *gin.Context, req vo.ListCommentRequest, uc *jwt.UserClaims) (result.Result, error) {
+	comments, err := ch.svc.Roots(ctx, uc.Uid, req.Biz, req.BizId, req.MaxId, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取评论列表成功", ch.toVos(comments)), nil
+}
+
+func (ch *CommentHandler) Replies(ctx *gin.Context, req vo.ListReplyRequest, uc *jwt.UserClaims) (result.Result, error) {
+	comments, err := ch.svc.Replies(ctx, uc.Uid, req.RootId, req.MinId, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取回复列表成功", ch.toVos(comments)), nil
+}
+
+func (ch *CommentHandler) Delete(ctx *gin.Context, req vo.CommentIdRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := ch.svc.Delete(ctx, uc.Uid, req.Id)
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound):
+		return result.FailWithMsg("评论不存在"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("删除评论成功"), nil
+}
+
+func (ch *CommentHandler) Like(ctx *gin.Context, req vo.CommentIdRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := ch.svc.Like(ctx, uc.Uid, req.Id)
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound):
+		return result.FailWithMsg("评论不存在"), nil
+	case err != nil:
+		return result.FailWithMsg("点赞失败"), err
+	}
+	return result.SuccessWithMsg("点赞成功"), nil
+}
+
+func (ch *CommentHandler) CancelLike(ctx *gin.Context, req vo.CommentIdRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if err := ch.svc.CancelLike(ctx, uc.Uid, req.Id); err != nil {
+		return result.FailWithMsg("取消点赞失败"), err
+	}
+	return result.SuccessWithMsg("取消点赞成功"), nil
+}
+
+func (ch *CommentHandler) toVos(comments []domain.Comment) []vo.CommentVo {
+	return slice.Map[domain.Comment, vo.CommentVo](comments, func(idx int, src domain.Comment) vo.CommentVo {
+		return vo.CommentVo{
+			Id:         src.Id,
+			Uid:        src.Uid,
+			RootId:     src.RootId,
+			ParentId:   src.ParentId,
+			Content:    src.Content,
+			ReplyCount: src.ReplyCount,
+			LikeCount:  src.LikeCount,
+			Ctime:      src.Ctime.Format(time.DateTime),
+		}
+	})
+}
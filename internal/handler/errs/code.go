@@ -0,0 +1,31 @@
+package errs
+
+// 用户模块的错误码，前端根据错误码展示提示，不要依赖提示文案
+const (
+	// UserInvalidInput 参数错误
+	UserInvalidInput = 401001
+	// UserInvalidCredentials 账号或密码错误，不区分账号是否存在
+	UserInvalidCredentials = 401002
+	// UserLoginLocked 登录失败次数太多，Data 是剩余锁定秒数
+	UserLoginLocked = 401003
+	// UserBanned 账号已被封禁
+	UserBanned = 401004
+	// UserInvalidCode 验证码错误或者已经失效
+	UserInvalidCode = 401005
+	// UserCodeSendTooMany 验证码发送太频繁
+	UserCodeSendTooMany = 401006
+	// UserCodePhoneLimited 该手机号今天获取验证码的次数用完了
+	UserCodePhoneLimited = 401007
+	// UserCodeIpLimited 当前 IP 获取验证码的次数用完了
+	UserCodeIpLimited = 401008
+	// UserCodeBizLimited 验证码整体发送量太大，稍后再试
+	UserCodeBizLimited = 401009
+	// UserCaptchaRequired 发送验证码触发了风控，需要带上图形验证码
+	UserCaptchaRequired = 401010
+	// UserInvalidCaptcha 图形验证码错误或者已经失效
+	UserInvalidCaptcha = 401011
+	// UserCodeEmailLimited 该邮箱今天获取验证码的次数用完了
+	UserCodeEmailLimited = 401012
+	// UserInternalServerError 系统异常
+	UserInternalServerError = 501001
+)
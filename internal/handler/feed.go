@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var _ Handler = (*FeedHandler)(nil)
+
+type FeedHandler struct {
+	svc    service.FeedService
+	logger *zap.Logger
+}
+
+func NewFeedHandler(svc service.FeedService, l *zap.Logger) *FeedHandler {
+	return &FeedHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (fh *FeedHandler) RegisterRoutes(server *gin.Engine) {
+	fg := server.Group("/feed")
+	fg.POST("/list", wrapper.WrapperBodyWitJwt[vo.FeedRequest](fh.logger, fh.List))
+}
+
+// List 关注的作者发布的文章
+func (fh *FeedHandler) List(ctx *gin.Context, req vo.FeedRequest, uc *jwt.UserClaims) (result.Result, error) {
+	var cursor domain.FeedCursor
+	if req.Cursor != "" {
+		if _, err := fmt.Sscanf(req.Cursor, "%d_%d", &cursor.Ctime, &cursor.ArticleId); err != nil {
+			return result.FailWithMsg("游标不正确"), nil
+		}
+	}
+	items, next, err := fh.svc.Feed(ctx, uc.Uid, cursor, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("获取关注流失败"), err
+	}
+	res := vo.FeedVo{
+		Articles: slice.Map[domain.FeedItem, vo.ArticleVo](items, func(idx int, src domain.FeedItem) vo.ArticleVo {
+			return vo.ArticleVo{
+				Id:         src.Article.Id,
+				Title:      src.Article.Title,
+				Abstract:   src.Article.Abstract(),
+				Content:    src.Article.Content,
+				AuthorId:   src.Article.Author.Id,
+				AuthorName: src.Article.Author.Name,
+				Status:     src.Article.Status.ToUint8(),
+			}
+		}),
+	}
+	if next != (domain.FeedCursor{}) {
+		res.Cursor = fmt.Sprintf("%d_%d", next.Ctime, next.ArticleId)
+	}
+	return result.SuccessWithData("获取关注流成功", res), nil
+}
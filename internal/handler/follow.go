@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ Handler = (*FollowHandler)(nil)
+
+type FollowHandler struct {
+	svc    service.FollowService
+	logger *zap.Logger
+}
+
+func NewFollowHandler(svc service.FollowService, l *zap.Logger) *FollowHandler {
+	return &FollowHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (fh *FollowHandler) RegisterRoutes(server *gin.Engine) {
+	fg := server.Group("/follow")
+	fg.POST("/add", wrapper.WrapperBodyWitJwt[vo.FollowRequest](fh.logger, fh.Follow))
+	fg.POST("/cancel", wrapper.WrapperBodyWitJwt[vo.UnfollowRequest](fh.logger, fh.Unfollow))
+	fg.POST("/followers", wrapper.WrapperBodyWitJwt[vo.ListFollowRequest](fh.logger, fh.Followers))
+	fg.POST("/followees", wrapper.WrapperBodyWitJwt[vo.ListFollowRequest](fh.logger, fh.Followees))
+}
+
+func (fh *FollowHandler) Follow(ctx *gin.Context, req vo.FollowRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := fh.svc.Follow(ctx, uc.Uid, req.Followee)
+	switch {
+	case errors.Is(err, service.ErrFollowSelf):
+		return result.FailWithMsg("不能关注自己"), nil
+	case errors.Is(err, service.ErrUserNotFound):
+		return result.FailWithMsg("用户不存在"), nil
+	case errors.Is(err, service.ErrBlocked):
+		return result.FailWithMsg("对方已将你拉黑"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("关注成功"), nil
+}
+
+func (fh *FollowHandler) Unfollow(ctx *gin.Context, req vo.UnfollowRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if err := fh.svc.Unfollow(ctx, uc.Uid, req.Followee); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("取消关注成功"), nil
+}
+
+// Followers 粉丝列表
+func (fh *FollowHandler) Followers(ctx *gin.Context, req vo.ListFollowRequest, uc *jwt.UserClaims) (result.Result, error) {
+	uid := req.Uid
+	if uid == 0 {
+		uid = uc.Uid
+	}
+	relations, err := fh.svc.Followers(ctx, uid, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取粉丝列表成功", slice.Map[domain.FollowRelation, vo.FollowVo](relations,
+		func(idx int, src domain.FollowRelation) vo.FollowVo {
+			return vo.FollowVo{
+				Uid:   src.Follower,
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		})), nil
+}
+
+// Followees 关注列表
+func (fh *FollowHandler) Followees(ctx *gin.Context, req vo.ListFollowRequest, uc *jwt.UserClaims) (result.Result, error) {
+	uid := req.Uid
+	if uid == 0 {
+		uid = uc.Uid
+	}
+	relations, err := fh.svc.Followees(ctx, uid, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取关注列表成功", slice.Map[domain.FollowRelation, vo.FollowVo](relations,
+		func(idx int, src domain.FollowRelation) vo.FollowVo {
+			return vo.FollowVo{
+				Uid:   src.Followee,
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		})), nil
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// setJwtToken 签发携带当前会话版本和角色的 token
+func setJwtToken(ctx *gin.Context, sessionSvc service.SessionService, roleSvc service.RoleService, u domain.User) error {
+	version, err := sessionSvc.Version(ctx, u.Id)
+	if err != nil {
+		return err
+	}
+	roles, err := roleSvc.Roles(ctx, u.Id)
+	if err != nil {
+		return err
+	}
+	return jwt.SetJwtToken(ctx, jwt.UserClaims{
+		Uid:     u.Id,
+		Email:   u.Email,
+		Version: version,
+		Roles:   roles,
+	})
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ Handler = (*MessageHandler)(nil)
+
+type MessageHandler struct {
+	svc    service.MessageService
+	logger *zap.Logger
+}
+
+func NewMessageHandler(svc service.MessageService, l *zap.Logger) *MessageHandler {
+	return &MessageHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (mh *MessageHandler) RegisterRoutes(server *gin.Engine) {
+	mg := server.Group("/messages")
+	mg.POST("/send", wrapper.WrapperBodyWitJwt[vo.SendMessageRequest](mh.logger, mh.Send))
+	mg.POST("/conversations", wrapper.WrapperBodyWitJwt[vo.ListConversationRequest](mh.logger, mh.Conversations))
+	mg.POST("/list", wrapper.WrapperBodyWitJwt[vo.ListMessageRequest](mh.logger, mh.List))
+	mg.POST("/read", wrapper.WrapperBodyWitJwt[vo.MarkMessageReadRequest](mh.logger, mh.MarkRead))
+}
+
+func (mh *MessageHandler) Send(ctx *gin.Context, req vo.SendMessageRequest, uc *jwt.UserClaims) (result.Result, error) {
+	id, err := mh.svc.Send(ctx, domain.Message{
+		Sender:   uc.Uid,
+		Receiver: req.Receiver,
+		Content:  req.Content,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidMessage):
+		return result.FailWithMsg("私信内容不合法"), nil
+	case errors.Is(err, service.ErrMessageSelf):
+		return result.FailWithMsg("不能给自己发私信"), nil
+	case errors.Is(err, service.ErrMessageTooFrequent):
+		return result.FailWithMsg("发送太频繁，请稍后再试"), nil
+	case errors.Is(err, service.ErrBlocked):
+		return result.FailWithMsg("对方已将你拉黑"), nil
+	case errors.Is(err, service.ErrBlockingPeer):
+		return result.FailWithMsg("你已将对方拉黑，解除拉黑之后才能发私信"), nil
+	case errors.Is(err, service.ErrUserNotFound):
+		return result.FailWithMsg("用户不存在"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("发送成功", id), nil
+}
+
+func (mh *MessageHandler) Conversations(ctx *gin.Context, req vo.ListConversationRequest, uc *jwt.UserClaims) (result.Result, error) {
+	conversations, err := mh.svc.Conversations(ctx, uc.Uid, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取会话列表成功", slice.Map[domain.Conversation, vo.ConversationVo](conversations,
+		func(idx int, src domain.Conversation) vo.ConversationVo {
+			return vo.ConversationVo{
+				Peer:        src.Peer,
+				LastMessage: mh.toVo(idx, src.LastMessage),
+				Unread:      src.Unread,
+				Utime:       src.Utime.Format(time.DateTime),
+			}
+		})), nil
+}
+
+func (mh *MessageHandler) List(ctx *gin.Context, req vo.ListMessageRequest, uc *jwt.UserClaims) (result.Result, error) {
+	messages, err := mh.svc.Messages(ctx, uc.Uid, req.Peer, req.MaxId, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取私信列表成功", slice.Map[domain.Message, vo.MessageVo](messages, mh.toVo)), nil
+}
+
+func (mh *MessageHandler) MarkRead(ctx *gin.Context, req vo.MarkMessageReadRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if err := mh.svc.MarkRead(ctx, uc.Uid, req.Peer); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("标记已读成功"), nil
+}
+
+func (mh *MessageHandler) toVo(idx int, src domain.Message) vo.MessageVo {
+	return vo.MessageVo{
+		Id:       src.Id,
+		Sender:   src.Sender,
+		Receiver: src.Receiver,
+		Content:  src.Content,
+		Ctime:    src.Ctime.Format(time.DateTime),
+	}
+}
@@ -1,22 +1,26 @@
 package middleware
 
 import (
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
 	jwt2 "github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"net/http"
 	"time"
 )
 
 // LoginBuilder JWT 登录校验
 type LoginBuilder struct {
-	paths []string
+	paths      []string
+	sessionSvc service.SessionService
 }
 
-func NewLoginBuilder() *LoginBuilder {
-	return &LoginBuilder{}
+func NewLoginBuilder(sessionSvc service.SessionService) *LoginBuilder {
+	return &LoginBuilder{
+		sessionSvc: sessionSvc,
+	}
 }
 
+// IgnorePaths 不需要登录的路径，也可以是注册路由时的路径，比如 /sms/callback/:provider
 func (l *LoginBuilder) IgnorePaths(path string) *LoginBuilder {
 	l.paths = append(l.paths, path)
 	return l
@@ -25,7 +29,7 @@ func (l *LoginBuilder) IgnorePaths(path string) *LoginBuilder {
 func (l *LoginBuilder) Build() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		for _, path := range l.paths {
-			if ctx.Request.URL.Path == path {
+			if ctx.Request.URL.Path == path || ctx.FullPath() == path {
 				ctx.Next()
 				return
 			}
@@ -38,14 +42,20 @@ func (l *LoginBuilder) Build() gin.HandlerFunc {
 		}
 
 		uc, err := jwt2.ExtractJwtClaims(ctx)
-		if err != nil {
+		if err != nil || uc == nil {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
+
+		// 修改密码等操作之后，旧的 token 即使没过期也不能再用
+		if err = l.sessionSvc.Check(ctx, uc.Uid, uc.Version); err != nil {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
 		now := time.Now()
 		if uc.ExpiresAt.Sub(now) < time.Minute {
-			uc.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
-			if err := jwt2.SetJwtToken(ctx, uc.Uid, uc.Email); err != nil {
+			if err := jwt2.SetJwtToken(ctx, *uc); err != nil {
 				ctx.AbortWithStatus(http.StatusUnauthorized)
 				return
 			}
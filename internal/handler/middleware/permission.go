@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	jwt2 "github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// PermissionBuilder 根据 token 携带的角色校验权限，需要放在登录校验之后
+type PermissionBuilder struct {
+	permission string
+}
+
+func NewPermissionBuilder(permission string) *PermissionBuilder {
+	return &PermissionBuilder{
+		permission: permission,
+	}
+}
+
+func (p *PermissionBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uc, err := jwt2.ExtractJwtClaims(ctx)
+		if err != nil || uc == nil {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !domain.HasPermission(uc.Roles, p.permission) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
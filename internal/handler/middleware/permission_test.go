@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPermissionBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name     string
+		roles    []string
+		noToken  bool
+		wantCode int
+	}{
+		{
+			name:     "管理员有权限",
+			roles:    []string{domain.RoleAdmin},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "审核员没有授权权限",
+			roles:    []string{domain.RoleModerator},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "普通用户没有权限",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "没有token",
+			noToken:  true,
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.New()
+			server.GET("/admin", NewPermissionBuilder(domain.PermissionRoleGrant).Build(), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/admin", nil)
+			require.NoError(t, err)
+			if !tc.noToken {
+				// 借用 SetJwtToken 签发 token，再放到请求头里
+				tokenCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+				require.NoError(t, jwt.SetJwtToken(tokenCtx, jwt.UserClaims{Uid: 1, Roles: tc.roles}))
+				req.Header.Set(jwt.AccessHeader, tokenCtx.Writer.Header().Get(jwt.AccessHeader))
+			}
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+		})
+	}
+}
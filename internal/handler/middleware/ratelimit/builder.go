@@ -1,32 +1,58 @@
 package ratelimit
 
 import (
-	_ "embed"
 	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
-	"log"
+	"go.uber.org/zap"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 )
 
-type Builder struct {
-	prefix   string
-	cmd      redis.Cmdable
-	interval time.Duration
-	// 阈值
-	rate int
+// KeyFunc 从请求里取出限流对象
+type KeyFunc func(ctx *gin.Context) string
+
+// KeyByIP 按客户端 IP 限流
+func KeyByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// KeyByUser 按登录用户限流，没有登录的请求按 IP，需要放在登录校验之后
+func KeyByUser(ctx *gin.Context) string {
+	uc, err := jwt.ExtractJwtClaims(ctx)
+	if err != nil || uc == nil {
+		return KeyByIP(ctx)
+	}
+	return "uid:" + strconv.FormatInt(uc.Uid, 10)
 }
 
-//go:embed slide_window.lua
-var luaScript string
+// KeyByRoute 所有人共用一个路由的额度，路由是方法加上注册路由时的路径
+func KeyByRoute(ctx *gin.Context) string {
+	return "route:" + ctx.Request.Method + ctx.FullPath()
+}
 
-func NewBuilder(cmd redis.Cmdable, interval time.Duration, rate int) *Builder {
+// Builder 接口限流，限流对象是 prefix 加上 key，prefix 区分不同的规则。
+// 限流器实现了 ratelimit.DetailLimiter 的时候会设置 X-RateLimit-* 响应头
+type Builder struct {
+	limiter ratelimit.Limiter
+	prefix  string
+	key     KeyFunc
+	method  string
+	path    string
+	// failOpen 限流器出错的时候是否放行
+	failOpen bool
+	logger   *zap.Logger
+}
+
+func NewBuilder(limiter ratelimit.Limiter) *Builder {
 	return &Builder{
-		cmd:      cmd,
-		prefix:   "ip-limiter",
-		interval: interval,
-		rate:     rate,
+		limiter: limiter,
+		prefix:  "http-limiter",
+		key:     KeyByIP,
+		logger:  zap.NewNop(),
 	}
 }
 
@@ -35,18 +61,53 @@ func (b *Builder) Prefix(prefix string) *Builder {
 	return b
 }
 
+func (b *Builder) Key(key KeyFunc) *Builder {
+	b.key = key
+	return b
+}
+
+// Route 只对这个路由生效，path 是注册路由时的路径，比如 /articles/:id，method 为空的时候不区分方法
+func (b *Builder) Route(method string, path string) *Builder {
+	b.method = method
+	b.path = path
+	return b
+}
+
+func (b *Builder) FailOpen(failOpen bool) *Builder {
+	b.failOpen = failOpen
+	return b
+}
+
+func (b *Builder) Logger(l *zap.Logger) *Builder {
+	b.logger = l
+	return b
+}
+
 func (b *Builder) Build() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		limited, err := b.limit(ctx)
+		if !b.match(ctx) {
+			ctx.Next()
+			return
+		}
+		key := fmt.Sprintf("%s:%s", b.prefix, b.key(ctx))
+		res, err := b.limit(ctx, key)
 		if err != nil {
-			log.Println(err)
-			// 这一步很有意思，就是如果这边出错了
-			// 要怎么办？
+			b.logger.Error("限流器异常", zap.String("key", key), zap.Bool("failOpen", b.failOpen), zap.Error(err))
+			if b.failOpen {
+				ctx.Next()
+				return
+			}
 			ctx.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
-		if limited {
-			log.Println(err)
+		if res.Limit > 0 {
+			ctx.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			ctx.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			ctx.Header("X-RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+		}
+		if res.Limited {
+			b.logger.Warn("触发限流", zap.String("key", key))
+			ctx.Header("Retry-After", strconv.Itoa(max(seconds(res.RetryAfter), 1)))
 			ctx.AbortWithStatus(http.StatusTooManyRequests)
 			return
 		}
@@ -54,8 +115,22 @@ func (b *Builder) Build() gin.HandlerFunc {
 	}
 }
 
-func (b *Builder) limit(ctx *gin.Context) (bool, error) {
-	key := fmt.Sprintf("%s:%s", b.prefix, ctx.ClientIP())
-	return b.cmd.Eval(ctx, luaScript, []string{key},
-		b.interval.Milliseconds(), b.rate, time.Now().UnixMilli()).Bool()
+func (b *Builder) match(ctx *gin.Context) bool {
+	if b.method != "" && b.method != ctx.Request.Method {
+		return false
+	}
+	return b.path == "" || b.path == ctx.FullPath()
+}
+
+func (b *Builder) limit(ctx *gin.Context, key string) (ratelimit.Result, error) {
+	if l, ok := b.limiter.(ratelimit.DetailLimiter); ok {
+		return l.LimitDetail(ctx, key)
+	}
+	limited, err := b.limiter.Limit(ctx, key)
+	return ratelimit.Result{Limited: limited}, err
+}
+
+// seconds 响应头里的时间都是秒，向上取整
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
 }
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ratelimit"
+	limitmock "github.com/ChongYanOvO/little-blue-book/pkg/ratelimit/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(ctl *gomock.Controller) ratelimit.Limiter
+		builder     func(b *Builder) *Builder
+		method      string
+		path        string
+		wantCode    int
+		wantHeaders map[string]string
+	}{
+		{
+			name: "没有触发限流",
+			mock: func(ctl *gomock.Controller) ratelimit.Limiter {
+				l := limitmock.NewMockLimiter(ctl)
+				l.EXPECT().Limit(gomock.Any(), "http-limiter:ip:192.0.2.1").Return(false, nil)
+				return l
+			},
+			method:   http.MethodGet,
+			path:     "/articles/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "触发限流，带上剩余额度和重试时间",
+			mock: func(ctl *gomock.Controller) ratelimit.Limiter {
+				l := ratelimit.NewLocalTokenBucketLimiter(time.Second*10, 1, 1)
+				_, err := l.Limit(context.Background(), "login:route:POST/users/login")
+				require.NoError(t, err)
+				return l
+			},
+			builder: func(b *Builder) *Builder {
+				return b.Prefix("login").Key(KeyByRoute).Route(http.MethodPost, "/users/login")
+			},
+			method:   http.MethodPost,
+			path:     "/users/login",
+			wantCode: http.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				"X-RateLimit-Limit":     "1",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "10",
+				"Retry-After":           "10",
+			},
+		},
+		{
+			name: "按路由限流，不同路由的额度分开",
+			mock: func(ctl *gomock.Controller) ratelimit.Limiter {
+				l := limitmock.NewMockLimiter(ctl)
+				l.EXPECT().Limit(gomock.Any(), "http-limiter:route:GET/articles/:id").Return(false, nil)
+				return l
+			},
+			builder: func(b *Builder) *Builder {
+				return b.Key(KeyByRoute)
+			},
+			method:   http.MethodGet,
+			path:     "/articles/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "不是这个路由的请求不限流",
+			mock: func(ctl *gomock.Controller) ratelimit.Limiter {
+				return limitmock.NewMockLimiter(ctl)
+			},
+			builder: func(b *Builder) *Builder {
+				return b.Route(http.MethodPost, "/users/login")
+			},
+			method:   http.MethodGet,
+			path:     "/articles/1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "限流器出错默认不放行",
+			mock: func(ctl *gomock.Controller) ratelimit.Limiter {
+				l := limitmock.NewMockLimiter(ctl)
+				l.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, errors.New("redis error"))
+				return l
+			},
+			method:   http.MethodGet,
+			path:     "/articles/1",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "限流器出错配置了放行",
+			mock: func(ctl *gomock.Controller) ratelimit.Limiter {
+				l := limitmock.NewMockLimiter(ctl)
+				l.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, errors.New("redis error"))
+				return l
+			},
+			builder: func(b *Builder) *Builder {
+				return b.FailOpen(true)
+			},
+			method:   http.MethodGet,
+			path:     "/articles/1",
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			b := NewBuilder(tc.mock(ctl))
+			if tc.builder != nil {
+				b = tc.builder(b)
+			}
+			server := gin.New()
+			server.Use(b.Build())
+			ok := func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			}
+			server.GET("/articles/:id", ok)
+			server.POST("/users/login", ok)
+
+			req, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+			req.RemoteAddr = "192.0.2.1:1234"
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			for k, v := range tc.wantHeaders {
+				assert.Equal(t, v, resp.Header().Get(k), k)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+var _ Handler = (*NotificationHandler)(nil)
+
+type NotificationHandler struct {
+	svc    service.NotificationService
+	logger *zap.Logger
+}
+
+func NewNotificationHandler(svc service.NotificationService, l *zap.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (nh *NotificationHandler) RegisterRoutes(server *gin.Engine) {
+	ng := server.Group("/notifications")
+	ng.POST("/list", wrapper.WrapperBodyWitJwt[vo.ListNotificationRequest](nh.logger, nh.List))
+	ng.GET("/unread", nh.UnreadCount)
+	ng.POST("/read", wrapper.WrapperBodyWitJwt[vo.MarkReadRequest](nh.logger, nh.MarkRead))
+	ng.POST("/read/all", nh.MarkAllRead)
+}
+
+func (nh *NotificationHandler) List(ctx *gin.Context, req vo.ListNotificationRequest, uc *jwt.UserClaims) (result.Result, error) {
+	notifications, err := nh.svc.List(ctx, uc.Uid, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取通知列表成功", slice.Map[domain.Notification, vo.NotificationVo](notifications,
+		func(idx int, src domain.Notification) vo.NotificationVo {
+			return vo.NotificationVo{
+				Id:         src.Id,
+				Type:       src.Type.ToUint8(),
+				Biz:        src.Biz,
+				BizId:      src.BizId,
+				LastActor:  src.LastActor,
+				ActorCount: src.ActorCount,
+				Read:       src.Read,
+				Utime:      src.Utime.Format(time.DateTime),
+			}
+		})), nil
+}
+
+// UnreadCount 未读通知数
+func (nh *NotificationHandler) UnreadCount(ctx *gin.Context) {
+	uc, err := jwt.ExtractJwtClaims(ctx)
+	if err != nil || uc == nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	cnt, err := nh.svc.UnreadCount(ctx, uc.Uid)
+	if err != nil {
+		nh.logger.Error("查询未读通知数失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
+	}
+	ctx.JSON(http.StatusOK, result.SuccessWithData("获取未读通知数成功", cnt))
+}
+
+func (nh *NotificationHandler) MarkRead(ctx *gin.Context, req vo.MarkReadRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if err := nh.svc.MarkRead(ctx, uc.Uid, req.Ids); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("标记已读成功"), nil
+}
+
+// MarkAllRead 全部标记已读，不需要请求体
+func (nh *NotificationHandler) MarkAllRead(ctx *gin.Context) {
+	uc, err := jwt.ExtractJwtClaims(ctx)
+	if err != nil || uc == nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if err = nh.svc.MarkAllRead(ctx, uc.Uid); err != nil {
+		nh.logger.Error("全部标记已读失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
+	}
+	ctx.JSON(http.StatusOK, result.SuccessWithMsg("标记已读成功"))
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/errs"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+var _ Handler = (*OAuth2Handler)(nil)
+
+// oauth2NonceCookie 发起授权的浏览器的标识，回调的时候要和 state 对得上
+const oauth2NonceCookie = "oauth2_nonce"
+
+// OAuth2Handler 第三方登录
+// 前端拿到第三方回调的 code 和 state 之后，调用 callback 登录或者 bind 绑定
+type OAuth2Handler struct {
+	svc        service.OAuth2Service
+	userSvc    service.UserService
+	sessionSvc service.SessionService
+	roleSvc    service.RoleService
+	logger     *zap.Logger
+}
+
+func NewOAuth2Handler(svc service.OAuth2Service, userSvc service.UserService, sessionSvc service.SessionService,
+	roleSvc service.RoleService, l *zap.Logger) *OAuth2Handler {
+	return &OAuth2Handler{
+		svc:        svc,
+		userSvc:    userSvc,
+		sessionSvc: sessionSvc,
+		roleSvc:    roleSvc,
+		logger:     l,
+	}
+}
+
+func (oh *OAuth2Handler) RegisterRoutes(server *gin.Engine) {
+	og := server.Group("/oauth2/:provider")
+	og.GET("/authurl", oh.AuthURL)
+	og.POST("/callback", wrapper.WrapperBody[vo.OAuth2CallbackRequest](oh.logger, oh.Callback))
+	og.POST("/bind", wrapper.WrapperBodyWitJwt[vo.OAuth2CallbackRequest](oh.logger, oh.Bind))
+}
+
+// AuthURL 获取第三方授权页面地址
+func (oh *OAuth2Handler) AuthURL(ctx *gin.Context) {
+	url, nonce, err := oh.svc.AuthURL(ctx, ctx.Param("provider"))
+	if errors.Is(err, service.ErrOAuth2ProviderNotFound) {
+		ctx.JSON(http.StatusOK, result.FailWithMsg("不支持的登录方式"))
+		return
+	}
+	if err != nil {
+		oh.logger.Error("构造授权地址失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
+	}
+	// 和 state 的有效期一样，只在回调和绑定的接口上带
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oauth2NonceCookie, nonce, 600, "/oauth2", "", ctx.Request.TLS != nil, true)
+	ctx.JSON(http.StatusOK, result.SuccessWithData("获取授权地址成功", url))
+}
+
+// Callback 第三方登录，没有绑定过的第三方账号会自动注册
+func (oh *OAuth2Handler) Callback(ctx *gin.Context, req vo.OAuth2CallbackRequest) (result.Result, error) {
+	info, err := oh.verify(ctx, req)
+	if res, ok := oh.verifyFailed(err); ok {
+		return res, nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	u, err := oh.userSvc.FindOrCreateByOAuth2(ctx, info)
+	if errors.Is(err, service.ErrUserBanned) {
+		return result.FailWithCode(errs.UserBanned, "账号已被封禁"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	if err = setJwtToken(ctx, oh.sessionSvc, oh.roleSvc, u); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("登录成功"), nil
+}
+
+// Bind 已登录用户绑定第三方账号
+func (oh *OAuth2Handler) Bind(ctx *gin.Context, req vo.OAuth2CallbackRequest, uc *jwt.UserClaims) (result.Result, error) {
+	info, err := oh.verify(ctx, req)
+	if res, ok := oh.verifyFailed(err); ok {
+		return res, nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	err = oh.userSvc.BindOAuth2(ctx, uc.Uid, info)
+	if errors.Is(err, service.ErrUserDuplicateOAuth2) {
+		return result.FailWithMsg("该第三方账号已经绑定了其它用户"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("绑定成功"), nil
+}
+
+// verify 校验 state 的时候带上发起授权时写到 cookie 里的 nonce
+func (oh *OAuth2Handler) verify(ctx *gin.Context, req vo.OAuth2CallbackRequest) (domain.OAuth2Info, error) {
+	nonce, _ := ctx.Cookie(oauth2NonceCookie)
+	return oh.svc.Verify(ctx, ctx.Param("provider"), req.Code, req.State, nonce)
+}
+
+// verifyFailed 可以直接告诉前端的授权失败原因
+func (oh *OAuth2Handler) verifyFailed(err error) (result.Result, bool) {
+	switch {
+	case errors.Is(err, service.ErrOAuth2ProviderNotFound):
+		return result.FailWithMsg("不支持的登录方式"), true
+	case errors.Is(err, service.ErrOAuth2InvalidState):
+		return result.FailWithMsg("授权已过期，请重新登录"), true
+	case errors.Is(err, service.ErrOAuth2InvalidCode):
+		return result.FailWithMsg("授权码无效，请重新登录"), true
+	default:
+		return result.Result{}, false
+	}
+}
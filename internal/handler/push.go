@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+// pushHeartbeatInterval 心跳间隔，防止代理把空闲连接断掉
+const pushHeartbeatInterval = time.Second * 30
+
+var _ Handler = (*PushHandler)(nil)
+
+type PushHandler struct {
+	svc    service.PushService
+	logger *zap.Logger
+}
+
+func NewPushHandler(svc service.PushService, l *zap.Logger) *PushHandler {
+	return &PushHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (ph *PushHandler) RegisterRoutes(server *gin.Engine) {
+	pg := server.Group("/push")
+	pg.GET("/stream", ph.Stream)
+}
+
+// Stream SSE 长连接，登录校验和其他接口一样走 jwt
+func (ph *PushHandler) Stream(ctx *gin.Context) {
+	uc, err := jwt.ExtractJwtClaims(ctx)
+	if err != nil || uc == nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	events, cancel, err := ph.svc.Register(uc.Uid)
+	if errors.Is(err, service.ErrTooManyConnections) {
+		ctx.JSON(http.StatusTooManyRequests, result.FailWithMsg("连接数过多"))
+		return
+	}
+	if err != nil {
+		ph.logger.Error("注册推送连接失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
+	}
+	defer cancel()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// 关掉 nginx 的缓冲，不然事件会攒着一起发
+	ctx.Header("X-Accel-Buffering", "no")
+	heartbeat := time.NewTicker(pushHeartbeatInterval)
+	defer heartbeat.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(evt.Type, evt.Data)
+			return true
+		case <-heartbeat.C:
+			ctx.SSEvent("ping", "")
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/middleware"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ Handler = (*ReportHandler)(nil)
+
+// ReportHandler 用户举报和审核员处理举报，处理举报的接口挂在 /admin 下面
+type ReportHandler struct {
+	svc    service.ReportService
+	logger *zap.Logger
+}
+
+func NewReportHandler(svc service.ReportService, l *zap.Logger) *ReportHandler {
+	return &ReportHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (rh *ReportHandler) RegisterRoutes(server *gin.Engine) {
+	rg := server.Group("/reports")
+	rg.POST("/create", wrapper.WrapperBodyWitJwt[vo.CreateReportRequest](rh.logger, rh.Create))
+
+	ag := server.Group("/admin/reports", middleware.NewPermissionBuilder(domain.PermissionReportHandle).Build())
+	ag.POST("/list", wrapper.WrapperBodyWitJwt[vo.ListReportRequest](rh.logger, rh.List))
+	ag.POST("/claim", wrapper.WrapperBodyWitJwt[vo.ClaimReportRequest](rh.logger, rh.Claim))
+	ag.POST("/resolve", wrapper.WrapperBodyWitJwt[vo.ResolveReportRequest](rh.logger, rh.Resolve))
+	ag.POST("/logs", wrapper.WrapperBodyWitJwt[vo.ListModerationLogRequest](rh.logger, rh.Logs))
+}
+
+func (rh *ReportHandler) Create(ctx *gin.Context, req vo.CreateReportRequest, uc *jwt.UserClaims) (result.Result, error) {
+	id, err := rh.svc.Report(ctx, domain.Report{
+		Reporter: uc.Uid,
+		Biz:      req.Biz,
+		BizId:    req.BizId,
+		Reason:   req.Reason,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidReport):
+		return result.FailWithMsg("举报理由不能为空且不能超过200字"), nil
+	case errors.Is(err, service.ErrReportDuplicate):
+		return result.FailWithMsg("已经举报过了，请等待处理"), nil
+	case errors.Is(err, service.ErrArticleNotFound):
+		return result.FailWithMsg("文章不存在"), nil
+	case errors.Is(err, service.ErrCommentNotFound):
+		return result.FailWithMsg("评论不存在"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("举报成功", id), nil
+}
+
+func (rh *ReportHandler) List(ctx *gin.Context, req vo.ListReportRequest, uc *jwt.UserClaims) (result.Result, error) {
+	status := domain.ReportStatus(req.Status)
+	if status < domain.ReportStatusPending || status > domain.ReportStatusResolved {
+		return result.FailWithMsg("状态不合法"), nil
+	}
+	reports, err := rh.svc.List(ctx, status, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取举报列表成功", slice.Map[domain.Report, vo.ReportVo](reports,
+		func(idx int, src domain.Report) vo.ReportVo {
+			return vo.ReportVo{
+				Id:        src.Id,
+				Reporter:  src.Reporter,
+				Biz:       src.Biz,
+				BizId:     src.BizId,
+				Reason:    src.Reason,
+				Status:    src.Status.ToUint8(),
+				Moderator: src.Moderator,
+				Action:    src.Action.ToUint8(),
+				Ctime:     src.Ctime.Format(time.DateTime),
+				Utime:     src.Utime.Format(time.DateTime),
+			}
+		})), nil
+}
+
+func (rh *ReportHandler) Claim(ctx *gin.Context, req vo.ClaimReportRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := rh.svc.Claim(ctx, req.Id, uc.Uid)
+	switch {
+	case errors.Is(err, service.ErrReportNotFound):
+		return result.FailWithMsg("举报不存在"), nil
+	case errors.Is(err, service.ErrReportClaimed):
+		return result.FailWithMsg("举报已被认领或已结案"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	rh.logger.Info("认领举报", zap.Int64("operator", uc.Uid), zap.Int64("reportId", req.Id))
+	return result.SuccessWithMsg("认领举报成功"), nil
+}
+
+func (rh *ReportHandler) Resolve(ctx *gin.Context, req vo.ResolveReportRequest, uc *jwt.UserClaims) (result.Result, error) {
+	err := rh.svc.Resolve(ctx, req.Id, uc.Uid, domain.ReportAction(req.Action), req.Remark)
+	switch {
+	case errors.Is(err, service.ErrReportNotFound):
+		return result.FailWithMsg("举报不存在"), nil
+	case errors.Is(err, service.ErrReportNotClaimed):
+		return result.FailWithMsg("请先认领该举报"), nil
+	case errors.Is(err, service.ErrReportClaimExpired):
+		return result.FailWithMsg("认领已过期，请重新认领"), nil
+	case errors.Is(err, service.ErrInvalidReportAction):
+		return result.FailWithMsg("处理方式不合法"), nil
+	case err != nil:
+		return result.FailWithMsg("系统异常"), err
+	}
+	rh.logger.Info("处理举报", zap.Int64("operator", uc.Uid), zap.Int64("reportId", req.Id), zap.Uint8("action", req.Action))
+	return result.SuccessWithMsg("处理举报成功"), nil
+}
+
+func (rh *ReportHandler) Logs(ctx *gin.Context, req vo.ListModerationLogRequest, uc *jwt.UserClaims) (result.Result, error) {
+	logs, err := rh.svc.Logs(ctx, req.ReportId, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithData("获取审核记录成功", slice.Map[domain.ModerationLog, vo.ModerationLogVo](logs,
+		func(idx int, src domain.ModerationLog) vo.ModerationLogVo {
+			return vo.ModerationLogVo{
+				Id:        src.Id,
+				Moderator: src.Moderator,
+				ReportId:  src.ReportId,
+				Biz:       src.Biz,
+				BizId:     src.BizId,
+				Operation: src.Operation.ToUint8(),
+				Action:    src.Action.ToUint8(),
+				Remark:    src.Remark,
+				Ctime:     src.Ctime.Format(time.DateTime),
+			}
+		})), nil
+}
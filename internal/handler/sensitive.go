@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/chongyanovo/zkit/slice"
+)
+
+// sensitiveResult 内容命中敏感词被拒绝的时候，把命中的位置返回给前端标出来
+func sensitiveResult(err error) (result.Result, bool) {
+	var se *service.SensitiveError
+	if !errors.As(err, &se) {
+		return result.Result{}, false
+	}
+	return result.FailWithData("内容包含敏感词", slice.Map[domain.SensitiveHit, vo.SensitiveHitVo](se.Hits,
+		func(idx int, src domain.SensitiveHit) vo.SensitiveHitVo {
+			return vo.SensitiveHitVo{
+				Field: src.Field,
+				Word:  src.Word,
+				Start: src.Start,
+				End:   src.End,
+			}
+		})), true
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/middleware"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
+	"github.com/ChongYanOvO/little-blue-book/internal/service"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
+	"github.com/chongyanovo/zkit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+var _ Handler = (*SmsHandler)(nil)
+
+// SmsHandler 服务商的送达回执和管理员查询短信发送记录
+type SmsHandler struct {
+	svc    service.SmsRecordService
+	logger *zap.Logger
+}
+
+func NewSmsHandler(svc service.SmsRecordService, l *zap.Logger) *SmsHandler {
+	return &SmsHandler{
+		svc:    svc,
+		logger: l,
+	}
+}
+
+func (sh *SmsHandler) RegisterRoutes(server *gin.Engine) {
+	server.POST("/sms/callback/:provider", sh.Callback)
+	server.POST("/sms/callback/:provider/:secret", sh.Callback)
+
+	ag := server.Group("/admin/sms", middleware.NewPermissionBuilder(domain.PermissionSmsQuery).Build())
+	ag.POST("/records", wrapper.WrapperBodyWitJwt[vo.ListSmsRecordRequest](sh.logger, sh.Records))
+}
+
+// Callback 服务商推送送达回执，返回非 200 的时候服务商会重新推送。
+// 阿里云和腾讯云在控制台配置 /sms/callback/{provider}/{secret}，webhook 服务商在请求头里带签名
+func (sh *SmsHandler) Callback(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.Status(http.StatusBadRequest)
+		return
+	}
+	n, err := sh.svc.Receipt(ctx, provider, service.SmsCallbackAuth{
+		Secret:    ctx.Param("secret"),
+		Timestamp: ctx.GetHeader(sms.WebhookTimestampHeader),
+		Signature: ctx.GetHeader(sms.WebhookSignatureHeader),
+	}, body)
+	switch {
+	case errors.Is(err, service.ErrSmsReceiptProviderNotFound):
+		ctx.Status(http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrSmsCallbackUnauthorized):
+		ctx.Status(http.StatusUnauthorized)
+		return
+	case errors.Is(err, service.ErrInvalidSmsReceipt):
+		sh.logger.Warn("短信回执格式错误", zap.String("provider", provider), zap.Error(err))
+		ctx.Status(http.StatusBadRequest)
+		return
+	case err != nil:
+		sh.logger.Error("处理短信回执失败", zap.String("provider", provider), zap.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	sh.logger.Info("收到短信回执", zap.String("provider", provider), zap.Int("updated", n))
+	// 服务商要求按它们的格式回复，否则会认为推送失败
+	switch provider {
+	case sms.ProviderAliyun:
+		ctx.JSON(http.StatusOK, gin.H{"code": 0, "msg": "接收成功"})
+	case sms.ProviderTencent:
+		ctx.JSON(http.StatusOK, gin.H{"result": 0, "errmsg": "OK"})
+	default:
+		ctx.JSON(http.StatusOK, result.SuccessWithMsg("接收成功"))
+	}
+}
+
+func (sh *SmsHandler) Records(ctx *gin.Context, req vo.ListSmsRecordRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if req.Phone == "" {
+		return result.FailWithMsg("手机号不能为空"), nil
+	}
+	records, err := sh.svc.List(ctx, req.Phone, req.Offset, req.Limit)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	sh.logger.Info("查询短信发送记录", zap.Int64("operator", uc.Uid), zap.String("phone", domain.MaskPhone(req.Phone)))
+	return result.SuccessWithData("获取短信发送记录成功", slice.Map[domain.SmsRecord, vo.SmsRecordVo](records,
+		func(idx int, src domain.SmsRecord) vo.SmsRecordVo {
+			var deliveryTime string
+			if !src.DeliveryTime.IsZero() {
+				deliveryTime = src.DeliveryTime.Format(time.DateTime)
+			}
+			return vo.SmsRecordVo{
+				Id:           src.Id,
+				Provider:     src.Provider,
+				Tpl:          src.Tpl,
+				Phone:        src.Phone,
+				RequestId:    src.RequestId,
+				Latency:      src.Latency.Milliseconds(),
+				Status:       src.Status.ToUint8(),
+				Error:        src.Error,
+				DeliveryTime: deliveryTime,
+				Ctime:        src.Ctime.Format(time.DateTime),
+			}
+		})), nil
+}
@@ -3,8 +3,12 @@ package handler
 import (
 	"errors"
 	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/errs"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/vo"
 	"github.com/ChongYanOvO/little-blue-book/internal/service"
 	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/wrapper"
 	regexp "github.com/dlclark/regexp2"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -14,6 +18,11 @@ import (
 const (
 	emailRegexPattern    = "^\\w+([-+.]\\w+)*@\\w+([-.]\\w+)*\\.\\w+([-.]\\w+)*$"
 	passwordRegexPattern = `^(?=.*[A-Za-z])(?=.*\d)(?=.*[$@$!%*#?&])[A-Za-z\d$@$!%*#?&]{8,}$`
+	phoneRegexPattern    = `^1[3-9]\d{9}$`
+
+	bizResetPassword = "reset_password"
+	bizVerifyEmail   = "verify_email"
+	bizBind          = "bind"
 )
 
 type LoginReq struct {
@@ -26,17 +35,29 @@ var _ Handler = (*UserHandler)(nil)
 type UserHandler struct {
 	svc            service.UserService
 	codeSvc        service.CodeService
+	sessionSvc     service.SessionService
+	roleSvc        service.RoleService
+	attemptSvc     service.LoginAttemptService
+	followSvc      service.FollowService
 	emailRegExp    *regexp.Regexp
 	passwordRegExp *regexp.Regexp
+	phoneRegExp    *regexp.Regexp
 	logger         *zap.Logger
 }
 
-func NewUserHandler(svc service.UserService, codeSvc service.CodeService, l *zap.Logger) *UserHandler {
+func NewUserHandler(svc service.UserService, codeSvc service.CodeService, sessionSvc service.SessionService,
+	roleSvc service.RoleService, attemptSvc service.LoginAttemptService, followSvc service.FollowService,
+	l *zap.Logger) *UserHandler {
 	return &UserHandler{
 		svc:            svc,
 		codeSvc:        codeSvc,
+		sessionSvc:     sessionSvc,
+		roleSvc:        roleSvc,
+		attemptSvc:     attemptSvc,
+		followSvc:      followSvc,
 		emailRegExp:    regexp.MustCompile(emailRegexPattern, regexp.None),
 		passwordRegExp: regexp.MustCompile(passwordRegexPattern, regexp.None),
+		phoneRegExp:    regexp.MustCompile(phoneRegexPattern, regexp.None),
 		logger:         l,
 	}
 }
@@ -49,6 +70,15 @@ func (uh *UserHandler) RegisterRoutes(server *gin.Engine) {
 	ug.GET("/profile", uh.Profile)
 	ug.PUT("/login/code", uh.SendLoginSmsCode)
 	ug.POST("/login/code", uh.LoginSms)
+	ug.POST("/password/change", wrapper.WrapperBodyWitJwt[vo.ChangePasswordRequest](uh.logger, uh.ChangePassword))
+	ug.POST("/password/reset/code", wrapper.WrapperBody[vo.SendResetPasswordCodeRequest](uh.logger, uh.SendResetPasswordCode))
+	ug.POST("/password/reset", wrapper.WrapperBody[vo.ResetPasswordRequest](uh.logger, uh.ResetPassword))
+	ug.POST("/email/verify/code", wrapper.WrapperBody[vo.SendVerifyEmailCodeRequest](uh.logger, uh.SendVerifyEmailCode))
+	ug.POST("/email/verify", wrapper.WrapperBody[vo.VerifyEmailRequest](uh.logger, uh.VerifyEmail))
+	ug.POST("/phone/code", wrapper.WrapperBodyWitJwt[vo.SendBindPhoneCodeRequest](uh.logger, uh.SendBindPhoneCode))
+	ug.POST("/phone/bind", wrapper.WrapperBodyWitJwt[vo.BindPhoneRequest](uh.logger, uh.BindPhone))
+	ug.POST("/email/code", wrapper.WrapperBodyWitJwt[vo.SendBindEmailCodeRequest](uh.logger, uh.SendBindEmailCode))
+	ug.POST("/email/bind", wrapper.WrapperBodyWitJwt[vo.BindEmailRequest](uh.logger, uh.BindEmail))
 }
 
 func (uh *UserHandler) SignUp(ctx *gin.Context) {
@@ -105,48 +135,89 @@ func (uh *UserHandler) SignUp(ctx *gin.Context) {
 		return
 	}
 
+	// 验证码发送失败不影响注册，被限流或者要求图形验证码的时候用户可以重新获取
+	if err = uh.codeSvc.SendByEmail(ctx, bizVerifyEmail, req.Email, ctx.ClientIP(), domain.CaptchaAnswer{}); err != nil {
+		uh.logger.Error("发送邮箱验证码失败", zap.Error(err))
+	}
 	ctx.String(http.StatusOK, "注册成功")
 }
 
-// Login 用户登录接口
+// Login 用户登录接口，按账号和 IP 统计失败次数，失败太多会被锁定一段时间
 func (uh *UserHandler) Login(ctx *gin.Context) {
 	var req LoginReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.String(http.StatusOK, "参数解析错误")
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInvalidInput, "参数解析错误"))
+		return
+	}
+	ip := ctx.ClientIP()
+	remain, err := uh.attemptSvc.Check(ctx, req.Email, ip)
+	if errors.Is(err, service.ErrLoginLocked) {
+		ctx.JSON(http.StatusOK, result.FailWithCodeData(errs.UserLoginLocked,
+			"登录失败次数太多，请稍后再试", int64(remain.Seconds())))
 		return
 	}
-	user, err := uh.svc.Login(ctx, req.Email, req.Password)
 	if err != nil {
-		if errors.Is(err, service.ErrUserDuplicateEmail) {
-			ctx.String(http.StatusOK, "用户名或密码错误")
-		} else {
-			ctx.String(http.StatusOK, "系统异常")
-		}
-	} else {
-		if err := jwt.SetJwtToken(ctx, user.Id, user.Email); err != nil {
-			uh.logger.Error("jwt设置错误")
-			return
+		uh.logger.Error("查询登录锁定状态失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "系统异常"))
+		return
+	}
+	user, err := uh.svc.Login(ctx, req.Email, req.Password)
+	switch {
+	case errors.Is(err, service.ErrInvalidUserOrEmail), errors.Is(err, service.ErrUserNotFound):
+		if er := uh.attemptSvc.Fail(ctx, req.Email, ip); er != nil {
+			uh.logger.Error("记录登录失败次数失败", zap.Error(er))
 		}
-		ctx.String(http.StatusOK, "登录成功")
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInvalidCredentials, "用户名或密码错误"))
+		return
+	case errors.Is(err, service.ErrUserBanned):
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserBanned, "账号已被封禁"))
+		return
+	case err != nil:
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "系统异常"))
+		return
 	}
-
+	if err = uh.attemptSvc.Succeed(ctx, req.Email); err != nil {
+		uh.logger.Error("清除登录失败次数失败", zap.Error(err))
+	}
+	if err = setJwtToken(ctx, uh.sessionSvc, uh.roleSvc, user); err != nil {
+		uh.logger.Error("jwt设置错误", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "系统异常"))
+		return
+	}
+	ctx.JSON(http.StatusOK, result.SuccessWithMsg("登录成功"))
 }
 
 func (uh *UserHandler) Edit(ctx *gin.Context) {
 
 }
 
-// Profile 用户详情
+// Profile 用户详情，带上粉丝数和关注数
 func (uh *UserHandler) Profile(ctx *gin.Context) {
 	uc, err := jwt.ExtractJwtClaims(ctx)
-	if err != nil {
-		ctx.String(http.StatusOK, "系统异常")
+	if err != nil || uc == nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
 	}
 	user, err := uh.svc.Profile(ctx, uc.Uid)
 	if err != nil {
-		ctx.String(http.StatusOK, "系统异常")
+		uh.logger.Error("查询用户详情失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
+	}
+	statics, err := uh.followSvc.Statics(ctx, uc.Uid)
+	if err != nil {
+		uh.logger.Error("查询关注统计失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithMsg("系统异常"))
+		return
 	}
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, result.SuccessWithData("获取用户详情成功", vo.ProfileVo{
+		Id:        user.Id,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		Verified:  user.Verified,
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}))
 }
 
 // SendLoginSmsCode 登录验证码发送
@@ -154,18 +225,26 @@ func (uh *UserHandler) SendLoginSmsCode(ctx *gin.Context) {
 	const biz = "login"
 	type Request struct {
 		Phone string `json:"phone"`
+		vo.CaptchaRequest
 	}
 	var req Request
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
-	err := uh.codeSvc.Send(ctx, biz, req.Phone)
-	if err != nil {
-		ctx.String(http.StatusOK, "登录验证码发送失败")
-	} else {
-		ctx.String(http.StatusOK, "登录验证码发送成功")
+	err := uh.codeSvc.Send(ctx, biz, req.Phone, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest))
+	if res, ok := codeLimitResult(err); ok {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrCodeSendTooMany):
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserCodeSendTooMany, "验证码发送太频繁"))
+	case err != nil:
+		uh.logger.Error("登录验证码发送失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "登录验证码发送失败"))
+	default:
+		ctx.JSON(http.StatusOK, result.SuccessWithMsg("登录验证码发送成功"))
 	}
-
 }
 
 // LoginSms 登录验证码校验
@@ -179,21 +258,272 @@ func (uh *UserHandler) LoginSms(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
+	// 每个验证码只能试几次，但是可以不停地重新获取，所以和密码登录一样按手机号和 IP 统计失败次数
+	ip := ctx.ClientIP()
+	remain, err := uh.attemptSvc.Check(ctx, req.Phone, ip)
+	if errors.Is(err, service.ErrLoginLocked) {
+		ctx.JSON(http.StatusOK, result.FailWithCodeData(errs.UserLoginLocked,
+			"登录失败次数太多，请稍后再试", int64(remain.Seconds())))
+		return
+	}
+	if err != nil {
+		uh.logger.Error("查询登录锁定状态失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "系统异常"))
+		return
+	}
 	ok, err := uh.codeSvc.Verify(ctx, biz, req.Phone, req.Code)
-	if !ok {
-		ctx.String(http.StatusOK, "验证码错误")
-	} else if err != nil {
-		ctx.String(http.StatusOK, "系统异常")
-	} else {
-		u, err := uh.svc.FindOrCreate(ctx, req.Phone)
+	switch {
+	case errors.Is(err, service.ErrCodeVerifyTooManyTimes), err == nil && !ok:
+		if er := uh.attemptSvc.Fail(ctx, req.Phone, ip); er != nil {
+			uh.logger.Error("记录登录失败次数失败", zap.Error(er))
+		}
 		if err != nil {
-			ctx.String(http.StatusOK, "系统异常")
+			ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInvalidCode, "验证次数太多，请重新获取验证码"))
 			return
 		}
-		if err := jwt.SetJwtToken(ctx, u.Id, u.Email); err != nil {
-			uh.logger.Error("jwt设置错误")
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInvalidCode, "验证码错误"))
+		return
+	case err != nil:
+		uh.logger.Error("校验登录验证码失败", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "系统异常"))
+		return
+	}
+	if err = uh.attemptSvc.Succeed(ctx, req.Phone); err != nil {
+		uh.logger.Error("清除登录失败次数失败", zap.Error(err))
+	}
+	u, err := uh.svc.FindOrCreate(ctx, req.Phone)
+	if errors.Is(err, service.ErrUserBanned) {
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserBanned, "账号已被封禁"))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "系统异常"))
+		return
+	}
+	if err = setJwtToken(ctx, uh.sessionSvc, uh.roleSvc, u); err != nil {
+		uh.logger.Error("jwt设置错误", zap.Error(err))
+		ctx.JSON(http.StatusOK, result.FailWithCode(errs.UserInternalServerError, "系统异常"))
+		return
+	}
+	ctx.JSON(http.StatusOK, result.SuccessWithMsg("登录成功"))
+}
+
+// ChangePassword 修改密码，成功之后其它设备上的登录全部失效，当前设备换发新的 token
+func (uh *UserHandler) ChangePassword(ctx *gin.Context, req vo.ChangePasswordRequest, uc *jwt.UserClaims) (result.Result, error) {
+	if msg, ok := uh.checkPassword(req.Password, req.ConfirmPassword); !ok {
+		return result.FailWithMsg(msg), nil
+	}
+	err := uh.svc.ChangePassword(ctx, uc.Uid, req.OldPassword, req.Password)
+	if errors.Is(err, service.ErrInvalidPassword) {
+		return result.FailWithMsg("旧密码错误"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	version, err := uh.sessionSvc.Revoke(ctx, uc.Uid)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	if err = jwt.SetJwtToken(ctx, jwt.UserClaims{
+		Uid:     uc.Uid,
+		Email:   uc.Email,
+		Version: version,
+		Roles:   uc.Roles,
+	}); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("修改密码成功"), nil
+}
+
+// SendResetPasswordCode 忘记密码时发送验证码，手机号和邮箱二选一
+func (uh *UserHandler) SendResetPasswordCode(ctx *gin.Context, req vo.SendResetPasswordCodeRequest) (result.Result, error) {
+	var err error
+	switch {
+	case req.Phone != "":
+		err = uh.codeSvc.Send(ctx, bizResetPassword, req.Phone, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest))
+	case req.Email != "":
+		isEmail, er := uh.emailRegExp.MatchString(req.Email)
+		if er != nil {
+			return result.FailWithMsg("系统错误"), er
+		}
+		if !isEmail {
+			return result.FailWithMsg("邮箱不正确"), nil
+		}
+		err = uh.codeSvc.SendByEmail(ctx, bizResetPassword, req.Email, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest))
+	default:
+		return result.FailWithMsg("请输入手机号或邮箱"), nil
+	}
+	return sendCodeResult(err)
+}
+
+// ResetPassword 通过验证码重置密码，成功之后所有设备上的登录全部失效
+func (uh *UserHandler) ResetPassword(ctx *gin.Context, req vo.ResetPasswordRequest) (result.Result, error) {
+	if msg, ok := uh.checkPassword(req.Password, req.ConfirmPassword); !ok {
+		return result.FailWithMsg(msg), nil
+	}
+	target := req.Phone
+	if target == "" {
+		target = req.Email
+	}
+	if target == "" {
+		return result.FailWithMsg("请输入手机号或邮箱"), nil
+	}
+	if res, ok, err := verifyCode(ctx, uh.codeSvc, bizResetPassword, target, req.Code); !ok {
+		return res, err
+	}
+	u, err := uh.svc.ResetPassword(ctx, domain.User{
+		Phone: req.Phone,
+		Email: req.Email,
+	}, req.Password)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return result.FailWithMsg("用户不存在"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	if _, err = uh.sessionSvc.Revoke(ctx, u.Id); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("重置密码成功"), nil
+}
+
+// SendVerifyEmailCode 重新发送邮箱验证码
+func (uh *UserHandler) SendVerifyEmailCode(ctx *gin.Context, req vo.SendVerifyEmailCodeRequest) (result.Result, error) {
+	isEmail, err := uh.emailRegExp.MatchString(req.Email)
+	if err != nil {
+		return result.FailWithMsg("系统错误"), err
+	}
+	if !isEmail {
+		return result.FailWithMsg("邮箱不正确"), nil
+	}
+	return sendCodeResult(uh.codeSvc.SendByEmail(ctx, bizVerifyEmail, req.Email, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest)))
+}
+
+// VerifyEmail 校验邮箱验证码，通过之后用户才可以发布文章
+func (uh *UserHandler) VerifyEmail(ctx *gin.Context, req vo.VerifyEmailRequest) (result.Result, error) {
+	if res, ok, err := verifyCode(ctx, uh.codeSvc, bizVerifyEmail, req.Email, req.Code); !ok {
+		return res, err
+	}
+	err := uh.svc.VerifyEmail(ctx, req.Email)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return result.FailWithMsg("用户不存在"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("邮箱验证成功"), nil
+}
+
+// SendBindPhoneCode 发送绑定手机号的验证码，更换手机号时新旧手机号都需要获取
+func (uh *UserHandler) SendBindPhoneCode(ctx *gin.Context, req vo.SendBindPhoneCodeRequest, uc *jwt.UserClaims) (result.Result, error) {
+	isPhone, err := uh.phoneRegExp.MatchString(req.Phone)
+	if err != nil {
+		return result.FailWithMsg("系统错误"), err
+	}
+	if !isPhone {
+		return result.FailWithMsg("手机号不正确"), nil
+	}
+	return sendCodeResult(uh.codeSvc.Send(ctx, bizBind, req.Phone, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest)))
+}
+
+// BindPhone 绑定手机号，已经有手机号的用户需要先校验原手机号的验证码，
+// 原验证码错误的时候不会消耗新手机号的验证码
+func (uh *UserHandler) BindPhone(ctx *gin.Context, req vo.BindPhoneRequest, uc *jwt.UserClaims) (result.Result, error) {
+	isPhone, err := uh.phoneRegExp.MatchString(req.Phone)
+	if err != nil {
+		return result.FailWithMsg("系统错误"), err
+	}
+	if !isPhone {
+		return result.FailWithMsg("手机号不正确"), nil
+	}
+	u, err := uh.svc.Profile(ctx, uc.Uid)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	if u.Phone == req.Phone {
+		return result.FailWithMsg("已经绑定了该手机号"), nil
+	}
+	if u.Phone != "" {
+		if res, ok, err := verifyCode(ctx, uh.codeSvc, bizBind, u.Phone, req.OldCode); !ok {
+			return res, err
+		}
+	}
+	if res, ok, err := verifyCode(ctx, uh.codeSvc, bizBind, req.Phone, req.Code); !ok {
+		return res, err
+	}
+	err = uh.svc.BindPhone(ctx, uc.Uid, req.Phone)
+	if errors.Is(err, service.ErrUserDuplicatePhone) {
+		return result.FailWithMsg("该手机号已被其他账号使用"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("绑定手机号成功"), nil
+}
+
+// SendBindEmailCode 发送绑定邮箱的验证码，更换邮箱时新旧邮箱都需要获取
+func (uh *UserHandler) SendBindEmailCode(ctx *gin.Context, req vo.SendBindEmailCodeRequest, uc *jwt.UserClaims) (result.Result, error) {
+	isEmail, err := uh.emailRegExp.MatchString(req.Email)
+	if err != nil {
+		return result.FailWithMsg("系统错误"), err
+	}
+	if !isEmail {
+		return result.FailWithMsg("邮箱不正确"), nil
+	}
+	return sendCodeResult(uh.codeSvc.SendByEmail(ctx, bizBind, req.Email, ctx.ClientIP(), captchaAnswer(req.CaptchaRequest)))
+}
+
+// BindEmail 绑定邮箱，已经有邮箱的用户需要先校验原邮箱的验证码，
+// 成功之后换发带新邮箱的 token
+func (uh *UserHandler) BindEmail(ctx *gin.Context, req vo.BindEmailRequest, uc *jwt.UserClaims) (result.Result, error) {
+	isEmail, err := uh.emailRegExp.MatchString(req.Email)
+	if err != nil {
+		return result.FailWithMsg("系统错误"), err
+	}
+	if !isEmail {
+		return result.FailWithMsg("邮箱不正确"), nil
+	}
+	u, err := uh.svc.Profile(ctx, uc.Uid)
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	if u.Email == req.Email {
+		return result.FailWithMsg("已经绑定了该邮箱"), nil
+	}
+	if u.Email != "" {
+		if res, ok, err := verifyCode(ctx, uh.codeSvc, bizBind, u.Email, req.OldCode); !ok {
+			return res, err
 		}
-		ctx.String(http.StatusOK, "登录成功")
 	}
+	if res, ok, err := verifyCode(ctx, uh.codeSvc, bizBind, req.Email, req.Code); !ok {
+		return res, err
+	}
+	err = uh.svc.BindEmail(ctx, uc.Uid, req.Email)
+	if errors.Is(err, service.ErrUserDuplicateEmail) {
+		return result.FailWithMsg("该邮箱已被其他账号使用"), nil
+	}
+	if err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	claims := *uc
+	claims.Email = req.Email
+	if err = jwt.SetJwtToken(ctx, claims); err != nil {
+		return result.FailWithMsg("系统异常"), err
+	}
+	return result.SuccessWithMsg("绑定邮箱成功"), nil
+}
 
+// checkPassword 校验两次输入的密码以及密码格式，返回给前端的提示信息
+func (uh *UserHandler) checkPassword(password, confirmPassword string) (string, bool) {
+	if password != confirmPassword {
+		return "两次输入的密码不一致", false
+	}
+	isPassword, err := uh.passwordRegExp.MatchString(password)
+	if err != nil {
+		return "系统错误", false
+	}
+	if !isPassword {
+		return "密码必须包含数字、特殊字符，并且长度不能小于 8 位", false
+	}
+	return "", true
 }
@@ -2,17 +2,23 @@ package handler
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/handler/errs"
 	"github.com/ChongYanOvO/little-blue-book/internal/service"
 	svcmock "github.com/ChongYanOvO/little-blue-book/internal/service/mock"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/jwt"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ginx/result"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestUserHandler_SignUp(t *testing.T) {
@@ -162,7 +168,10 @@ func TestUserHandler_SignUp(t *testing.T) {
 			ctl := gomock.NewController(t)
 			defer ctl.Finish()
 			server := gin.Default()
-			h := NewUserHandler(tc.mock(ctl), nil, nil)
+			cs := svcmock.NewMockCodeService(ctl)
+			cs.EXPECT().SendByEmail(gomock.Any(), "verify_email", "test@gmail.com", gomock.Any(), gomock.Any()).
+				Return(nil).AnyTimes()
+			h := NewUserHandler(tc.mock(ctl), cs, nil, nil, nil, nil, nil)
 			h.RegisterRoutes(server)
 
 			request, err := http.NewRequest(tc.requestMethod, tc.requestUrl, bytes.NewBuffer(tc.requestBody))
@@ -181,11 +190,12 @@ func TestUserHandler_Login(t *testing.T) {
 	testCases := []struct {
 		name          string
 		mock          func(ctl *gomock.Controller) service.UserService
+		attemptMock   func(ctl *gomock.Controller) service.LoginAttemptService
 		requestMethod string
 		requestUrl    string
 		requestBody   []byte
 		wantCode      int
-		wantBody      string
+		wantBody      result.Result
 	}{
 		{
 			name: "登录成功",
@@ -198,6 +208,12 @@ func TestUserHandler_Login(t *testing.T) {
 					}, nil)
 				return us
 			},
+			attemptMock: func(ctl *gomock.Controller) service.LoginAttemptService {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "test@gmail.com", gomock.Any()).Return(time.Duration(0), nil)
+				as.EXPECT().Succeed(gomock.Any(), "test@gmail.com").Return(nil)
+				return as
+			},
 			requestMethod: http.MethodPost,
 			requestUrl:    "/users/login",
 			requestBody: []byte(
@@ -206,7 +222,7 @@ func TestUserHandler_Login(t *testing.T) {
 					"password": "1qaz@WSX"
 				}`),
 			wantCode: http.StatusOK,
-			wantBody: "登录成功",
+			wantBody: result.SuccessWithMsg("登录成功"),
 		},
 		{
 			name: "参数解析错误",
@@ -214,6 +230,9 @@ func TestUserHandler_Login(t *testing.T) {
 				us := svcmock.NewMockUserService(ctl)
 				return us
 			},
+			attemptMock: func(ctl *gomock.Controller) service.LoginAttemptService {
+				return svcmock.NewMockLoginAttemptService(ctl)
+			},
 			requestMethod: http.MethodPost,
 			requestUrl:    "/users/login",
 			requestBody: []byte(
@@ -221,16 +240,22 @@ func TestUserHandler_Login(t *testing.T) {
 					"email": "test@gmail.com",
 				}`),
 			wantCode: http.StatusOK,
-			wantBody: "参数解析错误",
+			wantBody: result.FailWithCode(errs.UserInvalidInput, "参数解析错误"),
 		},
 		{
 			name: "用户名或密码错误",
 			mock: func(ctl *gomock.Controller) service.UserService {
 				us := svcmock.NewMockUserService(ctl)
 				us.EXPECT().Login(gomock.Any(), "test@gmail.com", "1qaz@WSX").
-					Return(domain.User{}, service.ErrUserDuplicateEmail)
+					Return(domain.User{}, service.ErrInvalidUserOrEmail)
 				return us
 			},
+			attemptMock: func(ctl *gomock.Controller) service.LoginAttemptService {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "test@gmail.com", gomock.Any()).Return(time.Duration(0), nil)
+				as.EXPECT().Fail(gomock.Any(), "test@gmail.com", gomock.Any()).Return(nil)
+				return as
+			},
 			requestMethod: http.MethodPost,
 			requestUrl:    "/users/login",
 			requestBody: []byte(
@@ -239,7 +264,7 @@ func TestUserHandler_Login(t *testing.T) {
 					"password": "1qaz@WSX"
 				}`),
 			wantCode: http.StatusOK,
-			wantBody: "用户名或密码错误",
+			wantBody: result.FailWithCode(errs.UserInvalidCredentials, "用户名或密码错误"),
 		},
 		{
 			name: "用户不存在",
@@ -249,6 +274,12 @@ func TestUserHandler_Login(t *testing.T) {
 					Return(domain.User{}, service.ErrUserNotFound)
 				return us
 			},
+			attemptMock: func(ctl *gomock.Controller) service.LoginAttemptService {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "test@gmail.com", gomock.Any()).Return(time.Duration(0), nil)
+				as.EXPECT().Fail(gomock.Any(), "test@gmail.com", gomock.Any()).Return(nil)
+				return as
+			},
 			requestMethod: http.MethodPost,
 			requestUrl:    "/users/login",
 			requestBody: []byte(
@@ -257,7 +288,52 @@ func TestUserHandler_Login(t *testing.T) {
 					"password": "1qaz@WSX"
 				}`),
 			wantCode: http.StatusOK,
-			wantBody: "系统异常",
+			wantBody: result.FailWithCode(errs.UserInvalidCredentials, "用户名或密码错误"),
+		},
+		{
+			name: "失败次数太多被锁定",
+			mock: func(ctl *gomock.Controller) service.UserService {
+				return svcmock.NewMockUserService(ctl)
+			},
+			attemptMock: func(ctl *gomock.Controller) service.LoginAttemptService {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "test@gmail.com", gomock.Any()).
+					Return(time.Minute, service.ErrLoginLocked)
+				return as
+			},
+			requestMethod: http.MethodPost,
+			requestUrl:    "/users/login",
+			requestBody: []byte(
+				`{
+					"email": "test@gmail.com",
+					"password": "1qaz@WSX"
+				}`),
+			wantCode: http.StatusOK,
+			// 经过 JSON 之后数字都是 float64
+			wantBody: result.FailWithCodeData(errs.UserLoginLocked, "登录失败次数太多，请稍后再试", float64(60)),
+		},
+		{
+			name: "账号已被封禁",
+			mock: func(ctl *gomock.Controller) service.UserService {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Login(gomock.Any(), "test@gmail.com", "1qaz@WSX").
+					Return(domain.User{}, service.ErrUserBanned)
+				return us
+			},
+			attemptMock: func(ctl *gomock.Controller) service.LoginAttemptService {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "test@gmail.com", gomock.Any()).Return(time.Duration(0), nil)
+				return as
+			},
+			requestMethod: http.MethodPost,
+			requestUrl:    "/users/login",
+			requestBody: []byte(
+				`{
+					"email": "test@gmail.com",
+					"password": "1qaz@WSX"
+				}`),
+			wantCode: http.StatusOK,
+			wantBody: result.FailWithCode(errs.UserBanned, "账号已被封禁"),
 		},
 	}
 	for _, tc := range testCases {
@@ -265,7 +341,11 @@ func TestUserHandler_Login(t *testing.T) {
 			ctl := gomock.NewController(t)
 			defer ctl.Finish()
 			server := gin.Default()
-			h := NewUserHandler(tc.mock(ctl), nil, nil)
+			ss := svcmock.NewMockSessionService(ctl)
+			ss.EXPECT().Version(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+			rs := svcmock.NewMockRoleService(ctl)
+			rs.EXPECT().Roles(gomock.Any(), gomock.Any()).Return([]string{}, nil).AnyTimes()
+			h := NewUserHandler(tc.mock(ctl), nil, ss, rs, tc.attemptMock(ctl), nil, zap.NewNop())
 			h.RegisterRoutes(server)
 
 			request, err := http.NewRequest(tc.requestMethod, tc.requestUrl, bytes.NewBuffer(tc.requestBody))
@@ -273,9 +353,267 @@ func TestUserHandler_Login(t *testing.T) {
 			require.NoError(t, err)
 			response := httptest.NewRecorder()
 			server.ServeHTTP(response, request)
-			assert.Equal(t, http.StatusOK, tc.wantCode)
-			assert.Equal(t, tc.wantBody, response.Body.String())
+			assert.Equal(t, tc.wantCode, response.Code)
+			var res result.Result
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &res))
+			assert.Equal(t, tc.wantBody, res)
+		})
+
+	}
+}
+
+// bearerToken 签发测试用的 token
+func bearerToken(t *testing.T, uc jwt.UserClaims) string {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	require.NoError(t, jwt.SetJwtToken(ctx, uc))
+	return ctx.Writer.Header().Get(jwt.AccessHeader)
+}
+
+func TestUserHandler_BindPhone(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctl *gomock.Controller) (service.UserService, service.CodeService)
+		body     string
+		wantBody result.Result
+	}{
+		{
+			name: "首次绑定手机号",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				us.EXPECT().BindPhone(gomock.Any(), int64(1), "13800000001").Return(nil)
+				cs := svcmock.NewMockCodeService(ctl)
+				cs.EXPECT().Verify(gomock.Any(), "bind", "13800000001", "123456").Return(true, nil)
+				return us, cs
+			},
+			body:     `{"phone":"13800000001","code":"123456"}`,
+			wantBody: result.SuccessWithMsg("绑定手机号成功"),
+		},
+		{
+			name: "更换手机号先校验原手机号",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1, Phone: "13800000000"}, nil)
+				us.EXPECT().BindPhone(gomock.Any(), int64(1), "13800000001").Return(nil)
+				cs := svcmock.NewMockCodeService(ctl)
+				gomock.InOrder(
+					cs.EXPECT().Verify(gomock.Any(), "bind", "13800000000", "654321").Return(true, nil),
+					cs.EXPECT().Verify(gomock.Any(), "bind", "13800000001", "123456").Return(true, nil),
+				)
+				return us, cs
+			},
+			body:     `{"phone":"13800000001","code":"123456","oldCode":"654321"}`,
+			wantBody: result.SuccessWithMsg("绑定手机号成功"),
+		},
+		{
+			name: "原手机号验证码错误不消耗新验证码",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1, Phone: "13800000000"}, nil)
+				cs := svcmock.NewMockCodeService(ctl)
+				cs.EXPECT().Verify(gomock.Any(), "bind", "13800000000", "000000").Return(false, nil)
+				return us, cs
+			},
+			body:     `{"phone":"13800000001","code":"123456","oldCode":"000000"}`,
+			wantBody: result.FailWithMsg("验证码错误"),
+		},
+		{
+			name: "手机号格式错误",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				return svcmock.NewMockUserService(ctl), svcmock.NewMockCodeService(ctl)
+			},
+			body:     `{"phone":"1380000","code":"123456"}`,
+			wantBody: result.FailWithMsg("手机号不正确"),
+		},
+		{
+			name: "手机号已被其他账号使用",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				us.EXPECT().BindPhone(gomock.Any(), int64(1), "13800000001").Return(service.ErrUserDuplicatePhone)
+				cs := svcmock.NewMockCodeService(ctl)
+				cs.EXPECT().Verify(gomock.Any(), "bind", "13800000001", "123456").Return(true, nil)
+				return us, cs
+			},
+			body:     `{"phone":"13800000001","code":"123456"}`,
+			wantBody: result.FailWithMsg("该手机号已被其他账号使用"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			server := gin.Default()
+			us, cs := tc.mock(ctl)
+			h := NewUserHandler(us, cs, nil, nil, nil, nil, zap.NewNop())
+			h.RegisterRoutes(server)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/phone/bind", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(jwt.AccessHeader, bearerToken(t, jwt.UserClaims{Uid: 1}))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			var res result.Result
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &res))
+			assert.Equal(t, tc.wantBody, res)
 		})
+	}
+}
+
+func TestUserHandler_BindEmail(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctl *gomock.Controller) (service.UserService, service.CodeService)
+		body      string
+		wantBody  result.Result
+		wantEmail string
+	}{
+		{
+			name: "更换邮箱之后换发 token",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1, Email: "old@gmail.com"}, nil)
+				us.EXPECT().BindEmail(gomock.Any(), int64(1), "new@gmail.com").Return(nil)
+				cs := svcmock.NewMockCodeService(ctl)
+				gomock.InOrder(
+					cs.EXPECT().Verify(gomock.Any(), "bind", "old@gmail.com", "654321").Return(true, nil),
+					cs.EXPECT().Verify(gomock.Any(), "bind", "new@gmail.com", "123456").Return(true, nil),
+				)
+				return us, cs
+			},
+			body:      `{"email":"new@gmail.com","code":"123456","oldCode":"654321"}`,
+			wantBody:  result.SuccessWithMsg("绑定邮箱成功"),
+			wantEmail: "new@gmail.com",
+		},
+		{
+			name: "原邮箱验证码错误",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1, Email: "old@gmail.com"}, nil)
+				cs := svcmock.NewMockCodeService(ctl)
+				cs.EXPECT().Verify(gomock.Any(), "bind", "old@gmail.com", "000000").Return(false, nil)
+				return us, cs
+			},
+			body:     `{"email":"new@gmail.com","code":"123456","oldCode":"000000"}`,
+			wantBody: result.FailWithMsg("验证码错误"),
+		},
+		{
+			name: "邮箱格式错误",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				return svcmock.NewMockUserService(ctl), svcmock.NewMockCodeService(ctl)
+			},
+			body:     `{"email":"new","code":"123456"}`,
+			wantBody: result.FailWithMsg("邮箱不正确"),
+		},
+		{
+			name: "已经绑定了该邮箱",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService) {
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1, Email: "new@gmail.com"}, nil)
+				return us, svcmock.NewMockCodeService(ctl)
+			},
+			body:     `{"email":"new@gmail.com","code":"123456"}`,
+			wantBody: result.FailWithMsg("已经绑定了该邮箱"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			server := gin.Default()
+			us, cs := tc.mock(ctl)
+			h := NewUserHandler(us, cs, nil, nil, nil, nil, zap.NewNop())
+			h.RegisterRoutes(server)
 
+			request, err := http.NewRequest(http.MethodPost, "/users/email/bind", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(jwt.AccessHeader, bearerToken(t, jwt.UserClaims{Uid: 1, Email: "old@gmail.com", Version: 3}))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			var res result.Result
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &res))
+			assert.Equal(t, tc.wantBody, res)
+			if tc.wantEmail == "" {
+				assert.Empty(t, response.Header().Get(jwt.AccessHeader))
+				return
+			}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx.Request.Header.Set(jwt.AccessHeader, response.Header().Get(jwt.AccessHeader))
+			uc, err := jwt.ExtractJwtClaims(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantEmail, uc.Email)
+			assert.Equal(t, int64(3), uc.Version)
+		})
+	}
+}
+
+func TestUserHandler_LoginSms(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctl *gomock.Controller) (service.UserService, service.CodeService, service.LoginAttemptService)
+		wantBody result.Result
+	}{
+		{
+			name: "登录成功清除失败次数",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService, service.LoginAttemptService) {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "13800000000", gomock.Any()).Return(time.Duration(0), nil)
+				as.EXPECT().Succeed(gomock.Any(), "13800000000").Return(nil)
+				cs := svcmock.NewMockCodeService(ctl)
+				cs.EXPECT().Verify(gomock.Any(), "login", "13800000000", "123456").Return(true, nil)
+				us := svcmock.NewMockUserService(ctl)
+				us.EXPECT().FindOrCreate(gomock.Any(), "13800000000").Return(domain.User{Id: 1}, nil)
+				return us, cs, as
+			},
+			wantBody: result.SuccessWithMsg("登录成功"),
+		},
+		{
+			name: "验证码错误记录失败次数",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService, service.LoginAttemptService) {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "13800000000", gomock.Any()).Return(time.Duration(0), nil)
+				as.EXPECT().Fail(gomock.Any(), "13800000000", gomock.Any()).Return(nil)
+				cs := svcmock.NewMockCodeService(ctl)
+				cs.EXPECT().Verify(gomock.Any(), "login", "13800000000", "123456").Return(false, nil)
+				return svcmock.NewMockUserService(ctl), cs, as
+			},
+			wantBody: result.FailWithCode(errs.UserInvalidCode, "验证码错误"),
+		},
+		{
+			name: "失败次数太多被锁定",
+			mock: func(ctl *gomock.Controller) (service.UserService, service.CodeService, service.LoginAttemptService) {
+				as := svcmock.NewMockLoginAttemptService(ctl)
+				as.EXPECT().Check(gomock.Any(), "13800000000", gomock.Any()).Return(time.Minute, service.ErrLoginLocked)
+				return svcmock.NewMockUserService(ctl), svcmock.NewMockCodeService(ctl), as
+			},
+			wantBody: result.FailWithCodeData(errs.UserLoginLocked, "登录失败次数太多，请稍后再试", float64(60)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			server := gin.Default()
+			ss := svcmock.NewMockSessionService(ctl)
+			ss.EXPECT().Version(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+			rs := svcmock.NewMockRoleService(ctl)
+			rs.EXPECT().Roles(gomock.Any(), gomock.Any()).Return([]string{}, nil).AnyTimes()
+			us, cs, as := tc.mock(ctl)
+			h := NewUserHandler(us, cs, ss, rs, as, nil, zap.NewNop())
+			h.RegisterRoutes(server)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/code",
+				bytes.NewBufferString(`{"phone":"13800000000","code":"123456"}`))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			var res result.Result
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &res))
+			assert.Equal(t, tc.wantBody, res)
+		})
 	}
 }
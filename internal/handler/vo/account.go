@@ -0,0 +1,57 @@
+package vo
+
+type DeleteAccountRequest struct {
+	// Password 设置过密码的用户使用密码校验身份
+	Password string `json:"password"`
+	// Code 没有密码的用户使用手机号或邮箱验证码校验身份
+	Code string `json:"code"`
+}
+
+type ProfileArchiveVo struct {
+	Id           int64  `json:"id"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Verified     bool   `json:"verified"`
+	GithubId     string `json:"github_id"`
+	WechatOpenId string `json:"wechat_open_id"`
+}
+
+type ArticleArchiveVo struct {
+	Id      int64  `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Status  uint8  `json:"status"`
+}
+
+type LikeArchiveVo struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"biz_id"`
+	Ctime string `json:"ctime"`
+}
+
+type CommentArchiveVo struct {
+	Id       int64  `json:"id"`
+	Biz      string `json:"biz"`
+	BizId    int64  `json:"biz_id"`
+	ParentId int64  `json:"parent_id"`
+	Content  string `json:"content"`
+	Ctime    string `json:"ctime"`
+}
+
+type FollowArchiveVo struct {
+	Follower int64  `json:"follower"`
+	Followee int64  `json:"followee"`
+	Ctime    string `json:"ctime"`
+}
+
+type MessageArchiveVo struct {
+	Id       int64  `json:"id"`
+	Sender   int64  `json:"sender"`
+	Receiver int64  `json:"receiver"`
+	Content  string `json:"content"`
+	Ctime    string `json:"ctime"`
+}
+
+type SendDeleteAccountCodeRequest struct {
+	CaptchaRequest
+}
@@ -0,0 +1,23 @@
+package vo
+
+type BanUserRequest struct {
+	Uid int64 `json:"uid"`
+}
+
+type UnbanUserRequest struct {
+	Uid int64 `json:"uid"`
+}
+
+type TakedownArticleRequest struct {
+	Id int64 `json:"id"`
+}
+
+type GrantRoleRequest struct {
+	Uid  int64  `json:"uid"`
+	Role string `json:"role"`
+}
+
+type RevokeRoleRequest struct {
+	Uid  int64  `json:"uid"`
+	Role string `json:"role"`
+}
@@ -0,0 +1,17 @@
+package vo
+
+type BlockRequest struct {
+	Target int64 `json:"target"`
+}
+
+type ListBlockRequest struct {
+	// Type 1 屏蔽 2 拉黑
+	Type   uint8 `json:"type"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type BlockVo struct {
+	Uid   int64  `json:"uid"`
+	Ctime string `json:"ctime"`
+}
@@ -0,0 +1,13 @@
+package vo
+
+// CaptchaRequest 发验证码触发风控之后需要带上图形验证码，没有触发的时候可以不传
+type CaptchaRequest struct {
+	CaptchaId string `json:"captchaId"`
+	Captcha   string `json:"captcha"`
+}
+
+type CaptchaVo struct {
+	Id string `json:"id"`
+	// Image data URL 格式的 PNG 图片，可以直接放到 img 标签里
+	Image string `json:"image"`
+}
@@ -0,0 +1,39 @@
+package vo
+
+type CreateCommentRequest struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	// ParentId 回复的评论，不传就是根评论
+	ParentId int64  `json:"parentId"`
+	Content  string `json:"content"`
+}
+
+type ListCommentRequest struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	// MaxId 上一页最后一条评论的 id，第一页不传
+	MaxId int64 `json:"maxId"`
+	Limit int   `json:"limit"`
+}
+
+type ListReplyRequest struct {
+	RootId int64 `json:"rootId"`
+	// MinId 上一页最后一条回复的 id，第一页不传
+	MinId int64 `json:"minId"`
+	Limit int   `json:"limit"`
+}
+
+type CommentIdRequest struct {
+	Id int64 `json:"id"`
+}
+
+type CommentVo struct {
+	Id         int64  `json:"id"`
+	Uid        int64  `json:"uid"`
+	RootId     int64  `json:"rootId"`
+	ParentId   int64  `json:"parentId"`
+	Content    string `json:"content"`
+	ReplyCount int64  `json:"replyCount"`
+	LikeCount  int64  `json:"likeCount"`
+	Ctime      string `json:"ctime"`
+}
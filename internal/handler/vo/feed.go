@@ -0,0 +1,13 @@
+package vo
+
+type FeedRequest struct {
+	// Cursor 上一页返回的游标，第一页不传
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type FeedVo struct {
+	Articles []ArticleVo `json:"articles"`
+	// Cursor 下一页的游标，为空表示没有更多了
+	Cursor string `json:"cursor"`
+}
@@ -0,0 +1,21 @@
+package vo
+
+type FollowRequest struct {
+	Followee int64 `json:"followee"`
+}
+
+type UnfollowRequest struct {
+	Followee int64 `json:"followee"`
+}
+
+type ListFollowRequest struct {
+	// Uid 查看谁的列表，不传就是自己
+	Uid    int64 `json:"uid"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type FollowVo struct {
+	Uid   int64  `json:"uid"`
+	Ctime string `json:"ctime"`
+}
@@ -0,0 +1,37 @@
+package vo
+
+type SendMessageRequest struct {
+	Receiver int64  `json:"receiver"`
+	Content  string `json:"content"`
+}
+
+type ListConversationRequest struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+type ListMessageRequest struct {
+	Peer int64 `json:"peer"`
+	// MaxId 上一页最后一条私信的 id，第一页不传
+	MaxId int64 `json:"maxId"`
+	Limit int   `json:"limit"`
+}
+
+type MarkMessageReadRequest struct {
+	Peer int64 `json:"peer"`
+}
+
+type MessageVo struct {
+	Id       int64  `json:"id"`
+	Sender   int64  `json:"sender"`
+	Receiver int64  `json:"receiver"`
+	Content  string `json:"content"`
+	Ctime    string `json:"ctime"`
+}
+
+type ConversationVo struct {
+	Peer        int64     `json:"peer"`
+	LastMessage MessageVo `json:"lastMessage"`
+	Unread      int64     `json:"unread"`
+	Utime       string    `json:"utime"`
+}
@@ -0,0 +1,23 @@
+package vo
+
+type ListNotificationRequest struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+type MarkReadRequest struct {
+	Ids []int64 `json:"ids"`
+}
+
+type NotificationVo struct {
+	Id   int64 `json:"id"`
+	Type uint8 `json:"type"`
+	// Biz BizId 通知对应的对象，点赞和评论是文章或者评论，关注是用户自己
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	// LastActor ActorCount 用来展示 "A 等 N 人"
+	LastActor  int64  `json:"lastActor"`
+	ActorCount int64  `json:"actorCount"`
+	Read       bool   `json:"read"`
+	Utime      string `json:"utime"`
+}
@@ -0,0 +1,58 @@
+package vo
+
+type CreateReportRequest struct {
+	Biz    string `json:"biz"`
+	BizId  int64  `json:"bizId"`
+	Reason string `json:"reason"`
+}
+
+type ListReportRequest struct {
+	// Status 1 待处理 2 已认领 3 已结案
+	Status uint8 `json:"status"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type ClaimReportRequest struct {
+	Id int64 `json:"id"`
+}
+
+type ResolveReportRequest struct {
+	Id int64 `json:"id"`
+	// Action 1 驳回 2 下架文章 3 删除评论
+	Action uint8  `json:"action"`
+	Remark string `json:"remark"`
+}
+
+type ListModerationLogRequest struct {
+	// ReportId 为 0 的时候返回所有记录
+	ReportId int64 `json:"reportId"`
+	Offset   int   `json:"offset"`
+	Limit    int   `json:"limit"`
+}
+
+type ReportVo struct {
+	Id        int64  `json:"id"`
+	Reporter  int64  `json:"reporter"`
+	Biz       string `json:"biz"`
+	BizId     int64  `json:"bizId"`
+	Reason    string `json:"reason"`
+	Status    uint8  `json:"status"`
+	Moderator int64  `json:"moderator"`
+	Action    uint8  `json:"action"`
+	Ctime     string `json:"ctime"`
+	Utime     string `json:"utime"`
+}
+
+type ModerationLogVo struct {
+	Id        int64  `json:"id"`
+	Moderator int64  `json:"moderator"`
+	ReportId  int64  `json:"reportId"`
+	Biz       string `json:"biz"`
+	BizId     int64  `json:"bizId"`
+	// Operation 1 认领 2 结案
+	Operation uint8  `json:"operation"`
+	Action    uint8  `json:"action"`
+	Remark    string `json:"remark"`
+	Ctime     string `json:"ctime"`
+}
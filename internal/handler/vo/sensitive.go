@@ -0,0 +1,9 @@
+package vo
+
+// SensitiveHitVo 命中的敏感词，start 和 end 按字符算，左闭右开
+type SensitiveHitVo struct {
+	Field string `json:"field"`
+	Word  string `json:"word"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
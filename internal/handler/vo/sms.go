@@ -0,0 +1,22 @@
+package vo
+
+type ListSmsRecordRequest struct {
+	Phone  string `json:"phone"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type SmsRecordVo struct {
+	Id        int64  `json:"id"`
+	Provider  string `json:"provider"`
+	Tpl       string `json:"tpl"`
+	Phone     string `json:"phone"`
+	RequestId string `json:"requestId"`
+	// Latency 调用服务商的耗时，毫秒
+	Latency int64 `json:"latency"`
+	// Status 1 发送失败 2 已发送 3 已送达 4 未送达
+	Status       uint8  `json:"status"`
+	Error        string `json:"error"`
+	DeliveryTime string `json:"deliveryTime"`
+	Ctime        string `json:"ctime"`
+}
@@ -0,0 +1,69 @@
+package vo
+
+type ChangePasswordRequest struct {
+	OldPassword     string `json:"oldPassword"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
+
+type SendResetPasswordCodeRequest struct {
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+	CaptchaRequest
+}
+
+type ResetPasswordRequest struct {
+	Phone           string `json:"phone"`
+	Email           string `json:"email"`
+	Code            string `json:"code"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
+
+type SendVerifyEmailCodeRequest struct {
+	Email string `json:"email"`
+	CaptchaRequest
+}
+
+type VerifyEmailRequest struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+type OAuth2CallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+type SendBindPhoneCodeRequest struct {
+	Phone string `json:"phone"`
+	CaptchaRequest
+}
+
+type BindPhoneRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+	// OldCode 更换手机号的时候，原手机号收到的验证码
+	OldCode string `json:"oldCode"`
+}
+
+type SendBindEmailCodeRequest struct {
+	Email string `json:"email"`
+	CaptchaRequest
+}
+
+type BindEmailRequest struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+	// OldCode 更换邮箱的时候，原邮箱收到的验证码
+	OldCode string `json:"oldCode"`
+}
+
+type ProfileVo struct {
+	Id        int64  `json:"id"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	Verified  bool   `json:"verified"`
+	Followers int64  `json:"followers"`
+	Followees int64  `json:"followees"`
+}
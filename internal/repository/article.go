@@ -9,11 +9,17 @@ import (
 	"go.uber.org/zap"
 )
 
+var ErrArticleNotFound = article.ErrArticleNotFound
+
 type ArticleRepository interface {
 	Create(ctx context.Context, article *domain.Article) (int64, error)
 	Update(ctx context.Context, article *domain.Article) error
 	Sync(ctx context.Context, article *domain.Article) (int64, error)
-	List(ctx context.Context, offset int, limit int) ([]domain.Article, error)
+	// List 文章列表，excludeAuthors 是要去掉的作者，在查询里过滤，分页不会被打乱
+	List(ctx context.Context, offset int, limit int, excludeAuthors []int64) ([]domain.Article, error)
+	ListByAuthor(ctx context.Context, authorId int64) ([]domain.Article, error)
+	UpdateStatus(ctx context.Context, id int64, status domain.ArticleStates) error
+	FindPublishedById(ctx context.Context, id int64) (domain.Article, error)
 }
 
 type ArticleRepositoryImpl struct {
@@ -51,14 +57,16 @@ func (repo *ArticleRepositoryImpl) Sync(ctx context.Context, article *domain.Art
 	return repo.dao.Sync(ctx, *domain2entity(article))
 }
 
-func (repo *ArticleRepositoryImpl) List(ctx context.Context, offset int, limit int) ([]domain.Article, error) {
-	if offset == 0 && limit <= 100 {
+func (repo *ArticleRepositoryImpl) List(ctx context.Context, offset int, limit int, excludeAuthors []int64) ([]domain.Article, error) {
+	// 缓存的第一页是所有人共用的，有要去掉的作者时不能用
+	cacheable := offset == 0 && limit <= 100 && len(excludeAuthors) == 0
+	if cacheable {
 		data, err := repo.cache.GetFirstPage(ctx)
 		if err == nil {
 			return data, err
 		}
 	}
-	articles, err := repo.dao.List(ctx, offset, limit)
+	articles, err := repo.dao.List(ctx, offset, limit, excludeAuthors)
 	if err != nil {
 		repo.logger.Error("查询文章列表失败", zap.Error(err))
 		return nil, err
@@ -67,14 +75,43 @@ func (repo *ArticleRepositoryImpl) List(ctx context.Context, offset int, limit i
 	data := slice.Map[article.Article, domain.Article](articles, func(idx int, src article.Article) domain.Article {
 		return *entity2domain(&src)
 	})
-	go func() {
-		if err := repo.cache.SetFirstPage(ctx, data); err != nil {
-			repo.logger.Error("文章列表缓存回写失败", zap.Error(err))
-		}
-	}()
+	if cacheable {
+		go func() {
+			if err := repo.cache.SetFirstPage(ctx, data); err != nil {
+				repo.logger.Error("文章列表缓存回写失败", zap.Error(err))
+			}
+		}()
+	}
+
 	return data, nil
 }
 
+func (repo *ArticleRepositoryImpl) ListByAuthor(ctx context.Context, authorId int64) ([]domain.Article, error) {
+	articles, err := repo.dao.ListByAuthor(ctx, authorId)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[article.Article, domain.Article](articles, func(idx int, src article.Article) domain.Article {
+		return *entity2domain(&src)
+	}), nil
+}
+
+func (repo *ArticleRepositoryImpl) UpdateStatus(ctx context.Context, id int64, status domain.ArticleStates) error {
+	defer func() {
+		repo.cache.DeleteFirstPage(ctx)
+	}()
+	return repo.dao.UpdateStatus(ctx, id, status.ToUint8())
+}
+
+func (repo *ArticleRepositoryImpl) FindPublishedById(ctx context.Context, id int64) (domain.Article, error) {
+	a, err := repo.dao.FindPublishedById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	src := article.Article(a)
+	return *entity2domain(&src), nil
+}
+
 func domain2entity(a *domain.Article) *article.Article {
 	return &article.Article{
 		Id:       a.Id,
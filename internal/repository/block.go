@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"time"
+)
+
+type BlockRepository interface {
+	// Add 屏蔽或者拉黑
+	Add(ctx context.Context, uid, target int64, typ domain.BlockType) error
+	// Remove 取消屏蔽或者取消拉黑
+	Remove(ctx context.Context, uid, target int64, typ domain.BlockType) error
+	List(ctx context.Context, uid int64, typ domain.BlockType, offset, limit int) ([]domain.BlockRelation, error)
+	// Relations 用户屏蔽和拉黑的所有人，优先走缓存，列表过滤的时候每个请求只查一次
+	Relations(ctx context.Context, uid int64) (map[int64]domain.BlockType, error)
+	// IsBlocked uid 是否拉黑了 target
+	IsBlocked(ctx context.Context, uid, target int64) (bool, error)
+}
+
+type BlockRepositoryImpl struct {
+	dao    dao.BlockDao
+	cache  cache.BlockCache
+	logger *zap.Logger
+}
+
+func NewBlockRepository(d dao.BlockDao, c cache.BlockCache, l *zap.Logger) BlockRepository {
+	return &BlockRepositoryImpl{
+		dao:    d,
+		cache:  c,
+		logger: l,
+	}
+}
+
+func (repo *BlockRepositoryImpl) Add(ctx context.Context, uid, target int64, typ domain.BlockType) error {
+	if err := repo.dao.Upsert(ctx, uid, target, typ.ToUint8()); err != nil {
+		return err
+	}
+	return repo.cache.Delete(ctx, uid)
+}
+
+func (repo *BlockRepositoryImpl) Remove(ctx context.Context, uid, target int64, typ domain.BlockType) error {
+	if err := repo.dao.Delete(ctx, uid, target, typ.ToUint8()); err != nil {
+		return err
+	}
+	return repo.cache.Delete(ctx, uid)
+}
+
+func (repo *BlockRepositoryImpl) List(ctx context.Context, uid int64, typ domain.BlockType, offset, limit int) ([]domain.BlockRelation, error) {
+	blocks, err := repo.dao.FindByType(ctx, uid, typ.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.UserBlock, domain.BlockRelation](blocks, func(idx int, src dao.UserBlock) domain.BlockRelation {
+		return domain.BlockRelation{
+			Uid:    src.Uid,
+			Target: src.Target,
+			Type:   domain.BlockType(src.Type),
+			Ctime:  time.UnixMilli(src.CreateTime),
+		}
+	}), nil
+}
+
+func (repo *BlockRepositoryImpl) Relations(ctx context.Context, uid int64) (map[int64]domain.BlockType, error) {
+	relations, err := repo.cache.Get(ctx, uid)
+	if err == nil {
+		return relations, nil
+	}
+	if !errors.Is(err, cache.ErrKeyNotExist) {
+		repo.logger.Error("查询拉黑缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	blocks, err := repo.dao.FindAll(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	relations = make(map[int64]domain.BlockType, len(blocks))
+	for _, b := range blocks {
+		// 两种关系都有的时候按拉黑算，拉黑包含了屏蔽的效果
+		if typ := domain.BlockType(b.Type); typ > relations[b.Target] {
+			relations[b.Target] = typ
+		}
+	}
+	if err = repo.cache.Set(ctx, uid, relations); err != nil {
+		repo.logger.Error("回写拉黑缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	return relations, nil
+}
+
+func (repo *BlockRepositoryImpl) IsBlocked(ctx context.Context, uid, target int64) (bool, error) {
+	relations, err := repo.Relations(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+	return relations[target] == domain.BlockTypeBlock, nil
+}
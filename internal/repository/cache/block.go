@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// blockPlaceholder 没有屏蔽任何人的时候也写一个占位字段，避免每次都查数据库
+const blockPlaceholder = "0"
+
+type BlockCache interface {
+	// Get 用户屏蔽和拉黑的所有人，不存在时返回 ErrKeyNotExist
+	Get(ctx context.Context, uid int64) (map[int64]domain.BlockType, error)
+	Set(ctx context.Context, uid int64, relations map[int64]domain.BlockType) error
+	Delete(ctx context.Context, uid int64) error
+}
+
+type RedisBlockCache struct {
+	redis      redis.Cmdable
+	expiration time.Duration
+	logger     *zap.Logger
+}
+
+func NewRedisBlockCache(r redis.Cmdable, l *zap.Logger) BlockCache {
+	return &RedisBlockCache{
+		redis:      r,
+		expiration: time.Minute * 30,
+		logger:     l,
+	}
+}
+
+func (cache *RedisBlockCache) Get(ctx context.Context, uid int64) (map[int64]domain.BlockType, error) {
+	data, err := cache.redis.HGetAll(ctx, cache.generateKey(uid)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrKeyNotExist
+	}
+	relations := make(map[int64]domain.BlockType, len(data))
+	for field, val := range data {
+		if field == blockPlaceholder {
+			continue
+		}
+		target, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		typ, _ := strconv.ParseUint(val, 10, 8)
+		relations[target] = domain.BlockType(typ)
+	}
+	return relations, nil
+}
+
+func (cache *RedisBlockCache) Set(ctx context.Context, uid int64, relations map[int64]domain.BlockType) error {
+	key := cache.generateKey(uid)
+	values := make([]any, 0, len(relations)*2+2)
+	values = append(values, blockPlaceholder, 0)
+	for target, typ := range relations {
+		values = append(values, strconv.FormatInt(target, 10), typ.ToUint8())
+	}
+	_, err := cache.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, values...)
+		pipe.Expire(ctx, key, cache.expiration)
+		return nil
+	})
+	return err
+}
+
+func (cache *RedisBlockCache) Delete(ctx context.Context, uid int64) error {
+	return cache.redis.Del(ctx, cache.generateKey(uid)).Err()
+}
+
+func (cache *RedisBlockCache) generateKey(uid int64) string {
+	return fmt.Sprintf("user:block:%d", uid)
+}
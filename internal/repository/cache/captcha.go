@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"time"
+)
+
+var ErrCaptchaNotExist = errors.New("图形验证码不存在或已过期")
+
+type CaptchaCache interface {
+	Set(ctx context.Context, id string, answer string) error
+	// Consume 取出并删除答案，不管对不对一个验证码只能用一次
+	Consume(ctx context.Context, id string) (string, error)
+}
+
+type RedisCaptchaCache struct {
+	redis      redis.Cmdable
+	expiration time.Duration
+	logger     *zap.Logger
+}
+
+func NewRedisCaptchaCache(r redis.Cmdable, l *zap.Logger) CaptchaCache {
+	return &RedisCaptchaCache{
+		redis:      r,
+		expiration: 5 * time.Minute,
+		logger:     l,
+	}
+}
+
+func (cache *RedisCaptchaCache) Set(ctx context.Context, id string, answer string) error {
+	return cache.redis.Set(ctx, cache.generateKey(id), answer, cache.expiration).Err()
+}
+
+func (cache *RedisCaptchaCache) Consume(ctx context.Context, id string) (string, error) {
+	answer, err := cache.redis.GetDel(ctx, cache.generateKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCaptchaNotExist
+	}
+	return answer, err
+}
+
+func (cache *RedisCaptchaCache) generateKey(id string) string {
+	return fmt.Sprintf("captcha:%s", id)
+}
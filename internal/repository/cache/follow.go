@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	fieldFollowers = "followers"
+	fieldFollowees = "followees"
+)
+
+type FollowCache interface {
+	// GetStatics 不存在时返回 ErrKeyNotExist
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error
+	// IncrFollowersIfPresent 缓存存在的时候才更新粉丝数
+	IncrFollowersIfPresent(ctx context.Context, uid int64, delta int64) error
+	// IncrFolloweesIfPresent 缓存存在的时候才更新关注数
+	IncrFolloweesIfPresent(ctx context.Context, uid int64, delta int64) error
+	// DelStatics 删除统计缓存，下次查询的时候重新从数据库统计
+	DelStatics(ctx context.Context, uids ...int64) error
+}
+
+type RedisFollowCache struct {
+	redis      redis.Cmdable
+	expiration time.Duration
+	logger     *zap.Logger
+}
+
+func NewRedisFollowCache(r redis.Cmdable, l *zap.Logger) FollowCache {
+	return &RedisFollowCache{
+		redis:      r,
+		expiration: time.Minute * 15,
+		logger:     l,
+	}
+}
+
+func (cache *RedisFollowCache) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	data, err := cache.redis.HGetAll(ctx, cache.generateKey(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if len(data) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+	followers, _ := strconv.ParseInt(data[fieldFollowers], 10, 64)
+	followees, _ := strconv.ParseInt(data[fieldFollowees], 10, 64)
+	return domain.FollowStatics{
+		Followers: followers,
+		Followees: followees,
+	}, nil
+}
+
+func (cache *RedisFollowCache) SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error {
+	key := cache.generateKey(uid)
+	_, err := cache.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, fieldFollowers, statics.Followers, fieldFollowees, statics.Followees)
+		pipe.Expire(ctx, key, cache.expiration)
+		return nil
+	})
+	return err
+}
+
+func (cache *RedisFollowCache) IncrFollowersIfPresent(ctx context.Context, uid int64, delta int64) error {
+	return cache.redis.Eval(ctx, luaInteractiveIncrease, []string{cache.generateKey(uid)}, fieldFollowers, delta).Err()
+}
+
+func (cache *RedisFollowCache) IncrFolloweesIfPresent(ctx context.Context, uid int64, delta int64) error {
+	return cache.redis.Eval(ctx, luaInteractiveIncrease, []string{cache.generateKey(uid)}, fieldFollowees, delta).Err()
+}
+
+func (cache *RedisFollowCache) DelStatics(ctx context.Context, uids ...int64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		keys = append(keys, cache.generateKey(uid))
+	}
+	return cache.redis.Del(ctx, keys...).Err()
+}
+
+func (cache *RedisFollowCache) generateKey(uid int64) string {
+	return fmt.Sprintf("follow:statics:%d", uid)
+}
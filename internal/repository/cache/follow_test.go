@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRedisFollowCache_IncrIfPresent(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := NewRedisFollowCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), nil)
+	ctx := context.Background()
+
+	// 缓存不存在的时候不创建，避免只有一个字段的残缺缓存
+	require.NoError(t, c.IncrFollowersIfPresent(ctx, 1, 1))
+	assert.False(t, mr.Exists("follow:statics:1"))
+	_, err := c.GetStatics(ctx, 1)
+	assert.Equal(t, ErrKeyNotExist, err)
+
+	require.NoError(t, c.SetStatics(ctx, 1, domain.FollowStatics{Followers: 10, Followees: 5}))
+	require.NoError(t, c.IncrFollowersIfPresent(ctx, 1, 1))
+	require.NoError(t, c.IncrFolloweesIfPresent(ctx, 1, -1))
+	statics, err := c.GetStatics(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FollowStatics{Followers: 11, Followees: 4}, statics)
+	assert.Equal(t, time.Minute*15, mr.TTL("follow:statics:1"))
+
+	require.NoError(t, c.DelStatics(ctx, 1, 2))
+	assert.False(t, mr.Exists("follow:statics:1"))
+}
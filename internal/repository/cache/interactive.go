@@ -10,6 +10,7 @@ import (
 
 type InteractiveCache interface {
 	IncreaseReadCountIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrLikeCountIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error
 }
 
 type RedisInteractiveCache struct {
@@ -24,6 +25,10 @@ func (r *RedisInteractiveCache) IncreaseReadCountIfPresent(ctx context.Context,
 	return r.redis.Eval(ctx, luaInteractiveIncrease, []string{r.key(biz, bizId)}, "read_count", 1).Err()
 }
 
+func (r *RedisInteractiveCache) IncrLikeCountIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error {
+	return r.redis.Eval(ctx, luaInteractiveIncrease, []string{r.key(biz, bizId)}, "like_count", delta).Err()
+}
+
 func (r *RedisInteractiveCache) key(biz string, bizId int64) string {
 	return fmt.Sprintf("%s:%d", biz, bizId)
 }
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"time"
+)
+
+//go:embed lua/login_fail.lua
+var luaLoginFail string
+
+// LoginLockPolicy 渐进式锁定策略，失败次数每达到 Threshold 的整数倍锁定一次，锁定时间翻倍
+type LoginLockPolicy struct {
+	// Window 失败次数的统计窗口
+	Window time.Duration
+	// Threshold 失败多少次开始锁定
+	Threshold int64
+	// Base 第一次锁定的时间
+	Base time.Duration
+	// Max 最长锁定时间
+	Max time.Duration
+}
+
+type LoginAttemptCache interface {
+	// LockTTL 剩余的锁定时间，没有锁定时为 0
+	LockTTL(ctx context.Context, key string) (time.Duration, error)
+	// IncrFail 失败次数加一，达到阈值时锁定，返回这次的锁定时间
+	IncrFail(ctx context.Context, key string, policy LoginLockPolicy) (time.Duration, error)
+	// Reset 清掉失败次数和锁定
+	Reset(ctx context.Context, key string) error
+}
+
+type RedisLoginAttemptCache struct {
+	redis  redis.Cmdable
+	logger *zap.Logger
+}
+
+func NewRedisLoginAttemptCache(r redis.Cmdable, l *zap.Logger) LoginAttemptCache {
+	return &RedisLoginAttemptCache{
+		redis:  r,
+		logger: l,
+	}
+}
+
+func (cache *RedisLoginAttemptCache) LockTTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := cache.redis.TTL(ctx, cache.generateKey(key)+":lock").Result()
+	if err != nil {
+		return 0, err
+	}
+	// key 不存在的时候 ttl 是负数
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (cache *RedisLoginAttemptCache) IncrFail(ctx context.Context, key string, policy LoginLockPolicy) (time.Duration, error) {
+	lock, err := cache.redis.Eval(ctx, luaLoginFail, []string{cache.generateKey(key)},
+		int64(policy.Window.Seconds()),
+		policy.Threshold,
+		int64(policy.Base.Seconds()),
+		int64(policy.Max.Seconds()),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(lock) * time.Second, nil
+}
+
+func (cache *RedisLoginAttemptCache) Reset(ctx context.Context, key string) error {
+	k := cache.generateKey(key)
+	return cache.redis.Del(ctx, k, k+":lock").Err()
+}
+
+func (cache *RedisLoginAttemptCache) generateKey(key string) string {
+	return fmt.Sprintf("user:login:fail:%s", key)
+}
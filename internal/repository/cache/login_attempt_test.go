@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRedisLoginAttemptCache_IncrFail(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := NewRedisLoginAttemptCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), nil)
+	ctx := context.Background()
+	policy := LoginLockPolicy{
+		Window:    time.Hour,
+		Threshold: 3,
+		Base:      time.Minute,
+		Max:       time.Minute * 3,
+	}
+	// 每次失败之后的锁定时间，第 3 次锁 1 分钟，第 6 次翻倍，第 9 次封顶
+	wantLocks := []time.Duration{0, 0, time.Minute, 0, 0, time.Minute * 2, 0, 0, time.Minute * 3}
+	for i, want := range wantLocks {
+		lock, err := c.IncrFail(ctx, "account:a", policy)
+		require.NoError(t, err)
+		assert.Equal(t, want, lock, "第 %d 次失败", i+1)
+	}
+	ttl, err := c.LockTTL(ctx, "account:a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute*3, ttl)
+	// 失败次数的统计窗口从第一次失败开始算
+	assert.Equal(t, time.Hour, mr.TTL("user:login:fail:account:a"))
+
+	// 锁定期间的失败不会延长锁定
+	mr.FastForward(time.Minute)
+	lock, err := c.IncrFail(ctx, "account:a", policy)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), lock)
+	ttl, err = c.LockTTL(ctx, "account:a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute*2, ttl)
+
+	require.NoError(t, c.Reset(ctx, "account:a"))
+	ttl, err = c.LockTTL(ctx, "account:a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
+}
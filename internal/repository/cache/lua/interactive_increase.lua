@@ -3,7 +3,7 @@ local cntKey = ARGV[1]
 local delta = tonumber(ARGV[2])
 local exists = redis.call("EXISTS", key)
 if exists == 1 then
-    redis.call("INCRBY", key, cntKey, delta)
+    redis.call("HINCRBY", key, cntKey, delta)
     return 1
 else
     return 0
@@ -0,0 +1,26 @@
+-- 登录失败次数的 key，锁定标记放在 key..":lock"
+local key = KEYS[1]
+local lockKey = key..":lock"
+-- 失败次数的统计窗口，秒
+local window = tonumber(ARGV[1])
+-- 失败多少次开始锁定
+local threshold = tonumber(ARGV[2])
+-- 第一次锁定的时间和最长锁定时间，秒
+local base = tonumber(ARGV[3])
+local max = tonumber(ARGV[4])
+
+local cnt = redis.call("incr", key)
+if cnt == 1 then
+    redis.call("expire", key, window)
+end
+-- 只有刚好达到 threshold 整数倍的这次失败才锁定，锁定期间的失败不会延长锁定
+if cnt < threshold or cnt % threshold ~= 0 then
+    return 0
+end
+-- 每多失败 threshold 次，锁定时间翻倍
+local lock = math.floor(base * 2 ^ (cnt / threshold - 1))
+if lock > max then
+    lock = max
+end
+redis.call("set", lockKey, cnt, "EX", lock)
+return lock
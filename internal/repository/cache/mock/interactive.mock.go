@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/cache/interactive.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/cache/interactive.go -package=mock -destination=internal/repository/cache/mock/interactive.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInteractiveCache is a mock of InteractiveCache interface.
+type MockInteractiveCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractiveCacheMockRecorder
+}
+
+// MockInteractiveCacheMockRecorder is the mock recorder for MockInteractiveCache.
+type MockInteractiveCacheMockRecorder struct {
+	mock *MockInteractiveCache
+}
+
+// NewMockInteractiveCache creates a new mock instance.
+func NewMockInteractiveCache(ctrl *gomock.Controller) *MockInteractiveCache {
+	mock := &MockInteractiveCache{ctrl: ctrl}
+	mock.recorder = &MockInteractiveCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractiveCache) EXPECT() *MockInteractiveCacheMockRecorder {
+	return m.recorder
+}
+
+// IncrLikeCountIfPresent mocks base method.
+func (m *MockInteractiveCache) IncrLikeCountIfPresent(ctx context.Context, biz string, bizId, delta int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrLikeCountIfPresent", ctx, biz, bizId, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrLikeCountIfPresent indicates an expected call of IncrLikeCountIfPresent.
+func (mr *MockInteractiveCacheMockRecorder) IncrLikeCountIfPresent(ctx, biz, bizId, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrLikeCountIfPresent", reflect.TypeOf((*MockInteractiveCache)(nil).IncrLikeCountIfPresent), ctx, biz, bizId, delta)
+}
+
+// IncreaseReadCountIfPresent mocks base method.
+func (m *MockInteractiveCache) IncreaseReadCountIfPresent(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseReadCountIfPresent", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncreaseReadCountIfPresent indicates an expected call of IncreaseReadCountIfPresent.
+func (mr *MockInteractiveCacheMockRecorder) IncreaseReadCountIfPresent(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseReadCountIfPresent", reflect.TypeOf((*MockInteractiveCache)(nil).IncreaseReadCountIfPresent), ctx, biz, bizId)
+}
@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/cache/session.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/cache/session.go -package=mock -destination=internal/repository/cache/mock/session.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSessionCache is a mock of SessionCache interface.
+type MockSessionCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionCacheMockRecorder
+}
+
+// MockSessionCacheMockRecorder is the mock recorder for MockSessionCache.
+type MockSessionCacheMockRecorder struct {
+	mock *MockSessionCache
+}
+
+// NewMockSessionCache creates a new mock instance.
+func NewMockSessionCache(ctrl *gomock.Controller) *MockSessionCache {
+	mock := &MockSessionCache{ctrl: ctrl}
+	mock.recorder = &MockSessionCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionCache) EXPECT() *MockSessionCacheMockRecorder {
+	return m.recorder
+}
+
+// DelVersion mocks base method.
+func (m *MockSessionCache) DelVersion(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelVersion", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelVersion indicates an expected call of DelVersion.
+func (mr *MockSessionCacheMockRecorder) DelVersion(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelVersion", reflect.TypeOf((*MockSessionCache)(nil).DelVersion), ctx, uid)
+}
+
+// GetVersion mocks base method.
+func (m *MockSessionCache) GetVersion(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockSessionCacheMockRecorder) GetVersion(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockSessionCache)(nil).GetVersion), ctx, uid)
+}
+
+// SetVersion mocks base method.
+func (m *MockSessionCache) SetVersion(ctx context.Context, uid, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVersion", ctx, uid, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVersion indicates an expected call of SetVersion.
+func (mr *MockSessionCacheMockRecorder) SetVersion(ctx, uid, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersion", reflect.TypeOf((*MockSessionCache)(nil).SetVersion), ctx, uid, version)
+}
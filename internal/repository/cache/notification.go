@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const fieldUnread = "unread"
+
+type NotificationCache interface {
+	// GetUnread 不存在时返回 ErrKeyNotExist
+	GetUnread(ctx context.Context, uid int64) (int64, error)
+	SetUnread(ctx context.Context, uid int64, cnt int64) error
+	// IncrUnreadIfPresent 缓存存在的时候才更新未读数
+	IncrUnreadIfPresent(ctx context.Context, uid int64, delta int64) error
+}
+
+type RedisNotificationCache struct {
+	redis      redis.Cmdable
+	expiration time.Duration
+	logger     *zap.Logger
+}
+
+func NewRedisNotificationCache(r redis.Cmdable, l *zap.Logger) NotificationCache {
+	return &RedisNotificationCache{
+		redis: r,
+		// 并发标记已读的时候未读数可能有偏差，靠过期时间兜底
+		expiration: time.Minute * 15,
+		logger:     l,
+	}
+}
+
+func (cache *RedisNotificationCache) GetUnread(ctx context.Context, uid int64) (int64, error) {
+	val, err := cache.redis.HGet(ctx, cache.generateKey(uid), fieldUnread).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrKeyNotExist
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+func (cache *RedisNotificationCache) SetUnread(ctx context.Context, uid int64, cnt int64) error {
+	key := cache.generateKey(uid)
+	_, err := cache.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, fieldUnread, cnt)
+		pipe.Expire(ctx, key, cache.expiration)
+		return nil
+	})
+	return err
+}
+
+func (cache *RedisNotificationCache) IncrUnreadIfPresent(ctx context.Context, uid int64, delta int64) error {
+	return cache.redis.Eval(ctx, luaInteractiveIncrease, []string{cache.generateKey(uid)}, fieldUnread, delta).Err()
+}
+
+func (cache *RedisNotificationCache) generateKey(uid int64) string {
+	return fmt.Sprintf("notification:unread:%d", uid)
+}
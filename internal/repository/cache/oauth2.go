@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"time"
+)
+
+var ErrOAuth2StateNotExist = errors.New("state 不存在或已过期")
+
+type OAuth2StateCache interface {
+	// Set 保存 state，value 为对应的提供方和发起授权的浏览器
+	Set(ctx context.Context, state string, binding string) error
+	// Consume 取出并删除 state，一个 state 只能用一次
+	Consume(ctx context.Context, state string) (string, error)
+}
+
+type RedisOAuth2StateCache struct {
+	redis      redis.Cmdable
+	expiration time.Duration
+	logger     *zap.Logger
+}
+
+func NewRedisOAuth2StateCache(r redis.Cmdable, l *zap.Logger) OAuth2StateCache {
+	return &RedisOAuth2StateCache{
+		redis:      r,
+		expiration: 10 * time.Minute,
+		logger:     l,
+	}
+}
+
+func (cache *RedisOAuth2StateCache) Set(ctx context.Context, state string, binding string) error {
+	return cache.redis.Set(ctx, cache.generateKey(state), binding, cache.expiration).Err()
+}
+
+func (cache *RedisOAuth2StateCache) Consume(ctx context.Context, state string) (string, error) {
+	binding, err := cache.redis.GetDel(ctx, cache.generateKey(state)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrOAuth2StateNotExist
+	}
+	return binding, err
+}
+
+func (cache *RedisOAuth2StateCache) generateKey(state string) string {
+	return fmt.Sprintf("oauth2:state:%s", state)
+}
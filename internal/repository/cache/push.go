@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// pushChannel 所有实例都订阅同一个频道，收到之后只推给连在自己身上的用户
+const pushChannel = "push:events"
+
+var ErrSubscribeNotSupported = errors.New("redis 客户端不支持订阅")
+
+type PushCache interface {
+	Publish(ctx context.Context, evt domain.PushEvent) error
+	// Subscribe 订阅所有实例发布的事件，ctx 取消之后 channel 会被关闭
+	Subscribe(ctx context.Context) (<-chan domain.PushEvent, error)
+}
+
+// redisSubscriber redis.Cmdable 里没有 Subscribe，单机和集群客户端都实现了
+type redisSubscriber interface {
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+type RedisPushCache struct {
+	redis  redis.Cmdable
+	logger *zap.Logger
+}
+
+func NewRedisPushCache(r redis.Cmdable, l *zap.Logger) PushCache {
+	return &RedisPushCache{
+		redis:  r,
+		logger: l,
+	}
+}
+
+func (cache *RedisPushCache) Publish(ctx context.Context, evt domain.PushEvent) error {
+	val, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	return cache.redis.Publish(ctx, pushChannel, val).Err()
+}
+
+func (cache *RedisPushCache) Subscribe(ctx context.Context) (<-chan domain.PushEvent, error) {
+	sub, ok := cache.redis.(redisSubscriber)
+	if !ok {
+		return nil, ErrSubscribeNotSupported
+	}
+	ps := sub.Subscribe(ctx, pushChannel)
+	// 等订阅确认，连不上 redis 的时候直接返回错误
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil, err
+	}
+	events := make(chan domain.PushEvent)
+	go func() {
+		defer close(events)
+		defer ps.Close()
+		msgs := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var evt domain.PushEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &evt); err != nil {
+					cache.logger.Error("解析推送事件失败", zap.Error(err))
+					continue
+				}
+				select {
+				case events <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"time"
+)
+
+type SessionCache interface {
+	// GetVersion 获取用户当前的会话版本，不存在时返回 ErrKeyNotExist
+	GetVersion(ctx context.Context, uid int64) (int64, error)
+	SetVersion(ctx context.Context, uid int64, version int64) error
+	// DelVersion 用户已经不存在的时候删掉缓存，之后的校验会回源到数据库
+	DelVersion(ctx context.Context, uid int64) error
+}
+
+type RedisSessionCache struct {
+	redis      redis.Cmdable
+	expiration time.Duration
+	logger     *zap.Logger
+}
+
+func NewRedisSessionCache(r redis.Cmdable, l *zap.Logger) SessionCache {
+	return &RedisSessionCache{
+		redis:      r,
+		expiration: time.Hour,
+		logger:     l,
+	}
+}
+
+func (cache *RedisSessionCache) GetVersion(ctx context.Context, uid int64) (int64, error) {
+	version, err := cache.redis.Get(ctx, cache.generateKey(uid)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrKeyNotExist
+	}
+	return version, err
+}
+
+func (cache *RedisSessionCache) SetVersion(ctx context.Context, uid int64, version int64) error {
+	return cache.redis.Set(ctx, cache.generateKey(uid), version, cache.expiration).Err()
+}
+
+func (cache *RedisSessionCache) DelVersion(ctx context.Context, uid int64) error {
+	return cache.redis.Del(ctx, cache.generateKey(uid)).Err()
+}
+
+func (cache *RedisSessionCache) generateKey(uid int64) string {
+	return fmt.Sprintf("user:session:version:%d", uid)
+}
@@ -15,6 +15,7 @@ var ErrKeyNotExist = redis.Nil
 type UserCache interface {
 	Get(ctx context.Context, id int64) (domain.User, error)
 	Set(ctx context.Context, u domain.User) error
+	Delete(ctx context.Context, id int64) error
 }
 
 type RedisUserCache struct {
@@ -55,6 +56,10 @@ func (cache *RedisUserCache) Set(ctx context.Context, u domain.User) error {
 	return cache.redis.Set(ctx, key, val, cache.expiration).Err()
 }
 
+func (cache *RedisUserCache) Delete(ctx context.Context, id int64) error {
+	return cache.redis.Del(ctx, cache.generateKey(id)).Err()
+}
+
 func (cache *RedisUserCache) generateKey(id int64) string {
 	return fmt.Sprintf("user:info:%d", id)
 }
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"go.uber.org/zap"
+)
+
+var ErrCaptchaNotExist = cache.ErrCaptchaNotExist
+
+type CaptchaRepository interface {
+	Store(ctx context.Context, id string, answer string) error
+	Consume(ctx context.Context, id string) (string, error)
+}
+
+type CaptchaRepositoryImpl struct {
+	cache  cache.CaptchaCache
+	logger *zap.Logger
+}
+
+func NewCaptchaRepository(c cache.CaptchaCache, l *zap.Logger) CaptchaRepository {
+	return &CaptchaRepositoryImpl{
+		cache:  c,
+		logger: l,
+	}
+}
+
+func (repo *CaptchaRepositoryImpl) Store(ctx context.Context, id string, answer string) error {
+	return repo.cache.Set(ctx, id, answer)
+}
+
+func (repo *CaptchaRepositoryImpl) Consume(ctx context.Context, id string) (string, error) {
+	return repo.cache.Consume(ctx, id)
+}
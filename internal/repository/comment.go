@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrCommentNotFound = errors.New("评论不存在")
+
+type CommentRepository interface {
+	Create(ctx context.Context, c domain.Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (domain.Comment, error)
+	// FindRoots 根评论，带上每个根评论的回复数
+	FindRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error)
+	FindReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error)
+	Delete(ctx context.Context, c domain.Comment) error
+	// ListByUid 用户发过的所有评论
+	ListByUid(ctx context.Context, uid int64) ([]domain.Comment, error)
+}
+
+type CommentRepositoryImpl struct {
+	dao    dao.CommentDao
+	logger *zap.Logger
+}
+
+func NewCommentRepository(d dao.CommentDao, l *zap.Logger) CommentRepository {
+	return &CommentRepositoryImpl{
+		dao:    d,
+		logger: l,
+	}
+}
+
+func (repo *CommentRepositoryImpl) Create(ctx context.Context, c domain.Comment) (int64, error) {
+	return repo.dao.Insert(ctx, repo.domain2entity(c))
+}
+
+func (repo *CommentRepositoryImpl) FindById(ctx context.Context, id int64) (domain.Comment, error) {
+	c, err := repo.dao.FindById(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return repo.entity2domain(0, c), nil
+}
+
+func (repo *CommentRepositoryImpl) FindRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	comments, err := repo.dao.FindRoots(ctx, biz, bizId, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	roots := slice.Map[dao.Comment, domain.Comment](comments, repo.entity2domain)
+	ids := slice.Map[domain.Comment, int64](roots, func(idx int, src domain.Comment) int64 {
+		return src.Id
+	})
+	counts, err := repo.dao.CountReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roots {
+		roots[i].ReplyCount = counts[roots[i].Id]
+	}
+	return roots, nil
+}
+
+func (repo *CommentRepositoryImpl) FindReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error) {
+	comments, err := repo.dao.FindReplies(ctx, rootId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Comment, domain.Comment](comments, repo.entity2domain), nil
+}
+
+func (repo *CommentRepositoryImpl) Delete(ctx context.Context, c domain.Comment) error {
+	_, err := repo.dao.Delete(ctx, repo.domain2entity(c))
+	return err
+}
+
+func (repo *CommentRepositoryImpl) ListByUid(ctx context.Context, uid int64) ([]domain.Comment, error) {
+	comments, err := repo.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Comment, domain.Comment](comments, repo.entity2domain), nil
+}
+
+func (repo *CommentRepositoryImpl) entity2domain(idx int, c dao.Comment) domain.Comment {
+	return domain.Comment{
+		Id:       c.Id,
+		Uid:      c.Uid,
+		Biz:      c.Biz,
+		BizId:    c.BizId,
+		RootId:   c.RootId,
+		ParentId: c.ParentId,
+		Content:  c.Content,
+		Ctime:    time.UnixMilli(c.CreateTime),
+	}
+}
+
+func (repo *CommentRepositoryImpl) domain2entity(c domain.Comment) dao.Comment {
+	return dao.Comment{
+		Id:       c.Id,
+		Uid:      c.Uid,
+		Biz:      c.Biz,
+		BizId:    c.BizId,
+		RootId:   c.RootId,
+		ParentId: c.ParentId,
+		Content:  c.Content,
+	}
+}
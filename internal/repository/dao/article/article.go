@@ -2,18 +2,29 @@ package article
 
 import (
 	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"time"
 )
 
+var ErrArticleNotFound = errors.New("文章不存在")
+
 type ArticleDao interface {
 	Insert(context.Context, *Article) (int64, error)
 	Update(context.Context, *Article) error
 	Sync(context.Context, Article) (int64, error)
 	Upsert(context.Context, *PublishedArticle) error
-	List(ctx context.Context, offset int, limit int) ([]Article, error)
+	// List 文章列表，excludeAuthors 里的作者在查询里直接去掉
+	List(ctx context.Context, offset int, limit int, excludeAuthors []int64) ([]Article, error)
+	// ListByAuthor 作者的全部文章，包括草稿
+	ListByAuthor(ctx context.Context, authorId int64) ([]Article, error)
+	// FindPublishedById 线上库的文章
+	FindPublishedById(ctx context.Context, id int64) (PublishedArticle, error)
+	// UpdateStatus 同时更新制作库和线上库的文章状态，不校验作者
+	UpdateStatus(ctx context.Context, id int64, status uint8) error
 }
 
 type ArticleDaoImpl struct {
@@ -28,17 +39,25 @@ func (dao *ArticleDaoImpl) Insert(ctx context.Context, article *Article) (int64,
 	return article.Id, dao.db.WithContext(ctx).Model(&Article{}).Create(&article).Error
 }
 
+// Update 只能更新自己的文章，被下架的文章不能再修改
 func (dao *ArticleDaoImpl) Update(ctx context.Context, a *Article) error {
 	a.UpdateTime = time.Now().UnixMilli()
-	return dao.db.WithContext(ctx).
+	res := dao.db.WithContext(ctx).
 		Model(&Article{}).
-		Where("id=? and author_id=?", a.Id, a.AuthorId).
+		Where("id=? and author_id=? and status<>?", a.Id, a.AuthorId, domain.ArticleStatusTakenDown.ToUint8()).
 		Updates(map[string]any{
 			"title":       a.Title,
 			"content":     a.Content,
 			"status":      a.Status,
 			"update_time": a.UpdateTime,
-		}).Error
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrArticleNotFound
+	}
+	return nil
 }
 
 func (dao *ArticleDaoImpl) Sync(ctx context.Context, article Article) (int64, error) {
@@ -79,14 +98,58 @@ func (dao *ArticleDaoImpl) Upsert(ctx context.Context, article *PublishedArticle
 		}).Create(&article).Error
 }
 
-func (dao *ArticleDaoImpl) List(ctx context.Context, offset int, limit int) ([]Article, error) {
+func (dao *ArticleDaoImpl) List(ctx context.Context, offset int, limit int, excludeAuthors []int64) ([]Article, error) {
 	articles := []Article{}
-	err := dao.db.WithContext(ctx).Model(&Article{}).
-		Offset(offset).Limit(limit).
+	db := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("status<>?", domain.ArticleStatusTakenDown.ToUint8())
+	if len(excludeAuthors) > 0 {
+		db = db.Where("author_id NOT IN ?", excludeAuthors)
+	}
+	err := db.Offset(offset).Limit(limit).
 		Order("update_time desc").Find(&articles).Error
 	return articles, err
 }
 
+func (dao *ArticleDaoImpl) ListByAuthor(ctx context.Context, authorId int64) ([]Article, error) {
+	articles := []Article{}
+	err := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("author_id = ?", authorId).
+		Order("create_time desc").Find(&articles).Error
+	return articles, err
+}
+
+func (dao *ArticleDaoImpl) FindPublishedById(ctx context.Context, id int64) (PublishedArticle, error) {
+	var a PublishedArticle
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return a, ErrArticleNotFound
+	}
+	return a, err
+}
+
+func (dao *ArticleDaoImpl) UpdateStatus(ctx context.Context, id int64, status uint8) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Article{}).Where("id=?", id).
+			Updates(map[string]any{
+				"status":      status,
+				"update_time": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrArticleNotFound
+		}
+		// 还没发布过的文章线上库里没有记录
+		return tx.Model(&PublishedArticle{}).Where("id=?", id).
+			Updates(map[string]any{
+				"status":      status,
+				"update_time": now,
+			}).Error
+	})
+}
+
 func NewArticleDao(db *gorm.DB, l *zap.Logger) ArticleDao {
 	if err := db.AutoMigrate(&Article{}); err != nil {
 		l.Error("初始化制作库失败", zap.Error(err))
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type BlockDao interface {
+	// Upsert 写入屏蔽或者拉黑关系，两种关系各占一行，互不影响
+	Upsert(ctx context.Context, uid, target int64, typ uint8) error
+	// Delete 只删除指定类型的关系，另一种关系还在
+	Delete(ctx context.Context, uid, target int64, typ uint8) error
+	// FindAll 用户屏蔽和拉黑的所有人，同一个人可能两种关系都有
+	FindAll(ctx context.Context, uid int64) ([]UserBlock, error)
+	// FindByType 按时间倒序分页
+	FindByType(ctx context.Context, uid int64, typ uint8, offset, limit int) ([]UserBlock, error)
+}
+
+type BlockDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewBlockDao(db *gorm.DB, l *zap.Logger) BlockDao {
+	if err := db.AutoMigrate(&UserBlock{}); err != nil {
+		l.Error("初始化拉黑表失败", zap.Error(err))
+	} else if db.Migrator().HasIndex(&UserBlock{}, "uk_uid_target") {
+		// 以前屏蔽和拉黑共用一行，唯一索引换成带 type 的之后去掉老的
+		if err = db.Migrator().DropIndex(&UserBlock{}, "uk_uid_target"); err != nil {
+			l.Error("删除拉黑表旧索引失败", zap.Error(err))
+		}
+	}
+	return &BlockDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *BlockDaoMysql) Upsert(ctx context.Context, uid, target int64, typ uint8) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"update_time": now,
+		}),
+	}).Create(&UserBlock{
+		Uid:        uid,
+		Target:     target,
+		Type:       typ,
+		CreateTime: now,
+		UpdateTime: now,
+	}).Error
+}
+
+func (dao *BlockDaoMysql) Delete(ctx context.Context, uid, target int64, typ uint8) error {
+	return dao.db.WithContext(ctx).
+		Where("uid = ? and target = ? and type = ?", uid, target, typ).
+		Delete(&UserBlock{}).Error
+}
+
+func (dao *BlockDaoMysql) FindAll(ctx context.Context, uid int64) ([]UserBlock, error) {
+	var blocks []UserBlock
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).Find(&blocks).Error
+	return blocks, err
+}
+
+func (dao *BlockDaoMysql) FindByType(ctx context.Context, uid int64, typ uint8, offset, limit int) ([]UserBlock, error) {
+	var blocks []UserBlock
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? and type = ?", uid, typ).
+		Order("update_time desc").
+		Offset(offset).Limit(limit).
+		Find(&blocks).Error
+	return blocks, err
+}
+
+// UserBlock 屏蔽和拉黑关系，uid 屏蔽或者拉黑了 target
+type UserBlock struct {
+	Id     int64 `gorm:"primaryKey,autoIncrement"`
+	Uid    int64 `gorm:"uniqueIndex:uk_uid_target_type,priority:1"`
+	Target int64 `gorm:"uniqueIndex:uk_uid_target_type,priority:2;index"`
+	// Type 1 屏蔽 2 拉黑
+	Type       uint8 `gorm:"uniqueIndex:uk_uid_target_type,priority:3"`
+	CreateTime int64
+	UpdateTime int64
+}
@@ -0,0 +1,163 @@
+package dao
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+type CommentDao interface {
+	// Insert 插入评论，同时更新评论对象的评论数
+	Insert(ctx context.Context, c Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (Comment, error)
+	// FindRoots 根评论，按 id 倒序，maxId 为 0 表示第一页
+	FindRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]Comment, error)
+	// FindReplies 根评论下面的回复，按 id 正序
+	FindReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]Comment, error)
+	// CountReplies 每个根评论下面的回复数
+	CountReplies(ctx context.Context, rootIds []int64) (map[int64]int64, error)
+	// Delete 删除评论和它下面的所有回复，返回删除的条数
+	Delete(ctx context.Context, c Comment) (int64, error)
+	// FindByUid 用户发过的所有评论，按 id 倒序
+	FindByUid(ctx context.Context, uid int64) ([]Comment, error)
+}
+
+type CommentDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewCommentDao(db *gorm.DB, l *zap.Logger) CommentDao {
+	if err := db.AutoMigrate(&Comment{}); err != nil {
+		l.Error("初始化评论表失败", zap.Error(err))
+	}
+	return &CommentDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *CommentDaoMysql) Insert(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.CreateTime = now
+	c.UpdateTime = now
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&c).Error; err != nil {
+			return err
+		}
+		return incrCommentCount(tx, c.Biz, c.BizId, 1, now)
+	})
+	return c.Id, err
+}
+
+func (dao *CommentDaoMysql) FindById(ctx context.Context, id int64) (Comment, error) {
+	var c Comment
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+func (dao *CommentDaoMysql) FindRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]Comment, error) {
+	var comments []Comment
+	db := dao.db.WithContext(ctx).Where("biz = ? and biz_id = ? and root_id = ?", biz, bizId, 0)
+	if maxId > 0 {
+		db = db.Where("id < ?", maxId)
+	}
+	err := db.Order("id desc").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+func (dao *CommentDaoMysql) FindReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]Comment, error) {
+	var comments []Comment
+	err := dao.db.WithContext(ctx).
+		Where("root_id = ? and id > ?", rootId, minId).
+		Order("id asc").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+func (dao *CommentDaoMysql) CountReplies(ctx context.Context, rootIds []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(rootIds))
+	if len(rootIds) == 0 {
+		return res, nil
+	}
+	var counts []struct {
+		RootId int64
+		Cnt    int64
+	}
+	err := dao.db.WithContext(ctx).Model(&Comment{}).
+		Select("root_id, COUNT(*) AS cnt").
+		Where("root_id IN ?", rootIds).
+		Group("root_id").
+		Scan(&counts).Error
+	for _, c := range counts {
+		res[c.RootId] = c.Cnt
+	}
+	return res, err
+}
+
+func (dao *CommentDaoMysql) Delete(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	var deleted int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := []int64{c.Id}
+		if c.RootId == 0 {
+			// 根评论连同下面所有的回复一起删掉
+			var replyIds []int64
+			if err := tx.Model(&Comment{}).Where("root_id = ?", c.Id).Pluck("id", &replyIds).Error; err != nil {
+				return err
+			}
+			ids = append(ids, replyIds...)
+		} else {
+			// 回复只删掉它自己和回复它的那些，一层一层往下找
+			parents := []int64{c.Id}
+			for len(parents) > 0 {
+				var children []int64
+				err := tx.Model(&Comment{}).
+					Where("root_id = ? and parent_id IN ?", c.RootId, parents).
+					Pluck("id", &children).Error
+				if err != nil {
+					return err
+				}
+				ids = append(ids, children...)
+				parents = children
+			}
+		}
+		res := tx.Where("id IN ?", ids).Delete(&Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+		// 评论上的点赞也没有意义了
+		if err := tx.Where("biz = ? and biz_id IN ?", "comment", ids).Delete(&UserLikeBiz{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("biz = ? and biz_id IN ?", "comment", ids).Delete(&Interactive{}).Error; err != nil {
+			return err
+		}
+		return incrCommentCount(tx, c.Biz, c.BizId, -deleted, now)
+	})
+	return deleted, err
+}
+
+func (dao *CommentDaoMysql) FindByUid(ctx context.Context, uid int64) ([]Comment, error) {
+	var comments []Comment
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).Order("id desc").Find(&comments).Error
+	return comments, err
+}
+
+// Comment 评论，回复都挂在根评论下面，通过 ParentId 知道回复的是谁
+type Comment struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	Uid        int64  `gorm:"index"`
+	Biz        string `gorm:"type:varchar(128);index:biz_root"`
+	BizId      int64  `gorm:"index:biz_root"`
+	RootId     int64  `gorm:"index:biz_root;index:root_id"`
+	ParentId   int64
+	Content    string `gorm:"type:text"`
+	CreateTime int64
+	UpdateTime int64
+}
+
+func (c *Comment) TableName() string {
+	return "comment"
+}
@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao/article"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type FeedDao interface {
+	// InsertInbox 推模式下把文章写进粉丝的收件箱，重复发布的文章直接忽略
+	InsertInbox(ctx context.Context, inboxes []FeedInbox) error
+	// FindInbox 收件箱里作者在 authorIds 里面的文章，ctime 和 articleId 是上一页最后一条，都是 0 表示第一页
+	FindInbox(ctx context.Context, uid int64, authorIds []int64, ctime, articleId int64, limit int) ([]FeedInbox, error)
+	// FindPublished 拉模式下直接查作者已发布的文章，分页方式和 FindInbox 一样
+	FindPublished(ctx context.Context, authorIds []int64, status uint8, ctime, articleId int64, limit int) ([]article.PublishedArticle, error)
+	// FindPublishedByIds 按 id 查询指定状态的线上文章
+	FindPublishedByIds(ctx context.Context, ids []int64, status uint8) ([]article.PublishedArticle, error)
+	// InsertPullAuthor 记录走拉模式的作者，已经记录过的直接忽略
+	InsertPullAuthor(ctx context.Context, authorId int64) error
+	// FindPullAuthors 从 authorIds 里面挑出走过拉模式的作者
+	FindPullAuthors(ctx context.Context, authorIds []int64) ([]int64, error)
+}
+
+type FeedDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewFeedDao(db *gorm.DB, l *zap.Logger) FeedDao {
+	if err := db.AutoMigrate(&FeedInbox{}, &FeedPullAuthor{}); err != nil {
+		l.Error("初始化关注流收件箱表失败", zap.Error(err))
+	}
+	return &FeedDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *FeedDaoMysql) InsertInbox(ctx context.Context, inboxes []FeedInbox) error {
+	if len(inboxes) == 0 {
+		return nil
+	}
+	return dao.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&inboxes).Error
+}
+
+func (dao *FeedDaoMysql) FindInbox(ctx context.Context, uid int64, authorIds []int64, ctime, articleId int64, limit int) ([]FeedInbox, error) {
+	var inboxes []FeedInbox
+	if len(authorIds) == 0 {
+		return inboxes, nil
+	}
+	err := dao.cursor(dao.db.WithContext(ctx), "article_id", ctime, articleId).
+		Where("uid = ? AND author_id IN ?", uid, authorIds).
+		Order("create_time desc, article_id desc").
+		Limit(limit).
+		Find(&inboxes).Error
+	return inboxes, err
+}
+
+func (dao *FeedDaoMysql) FindPublished(ctx context.Context, authorIds []int64, status uint8, ctime, articleId int64, limit int) ([]article.PublishedArticle, error) {
+	var articles []article.PublishedArticle
+	if len(authorIds) == 0 {
+		return articles, nil
+	}
+	err := dao.cursor(dao.db.WithContext(ctx), "id", ctime, articleId).
+		Where("author_id IN ? AND status = ?", authorIds, status).
+		Order("create_time desc, id desc").
+		Limit(limit).
+		Find(&articles).Error
+	return articles, err
+}
+
+func (dao *FeedDaoMysql) FindPublishedByIds(ctx context.Context, ids []int64, status uint8) ([]article.PublishedArticle, error) {
+	var articles []article.PublishedArticle
+	if len(ids) == 0 {
+		return articles, nil
+	}
+	err := dao.db.WithContext(ctx).
+		Where("id IN ? AND status = ?", ids, status).
+		Find(&articles).Error
+	return articles, err
+}
+
+func (dao *FeedDaoMysql) InsertPullAuthor(ctx context.Context, authorId int64) error {
+	return dao.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&FeedPullAuthor{AuthorId: authorId, CreateTime: time.Now().UnixMilli()}).Error
+}
+
+func (dao *FeedDaoMysql) FindPullAuthors(ctx context.Context, authorIds []int64) ([]int64, error) {
+	var ids []int64
+	if len(authorIds) == 0 {
+		return ids, nil
+	}
+	err := dao.db.WithContext(ctx).Model(&FeedPullAuthor{}).
+		Where("author_id IN ?", authorIds).
+		Pluck("author_id", &ids).Error
+	return ids, err
+}
+
+// cursor 按 (create_time, 文章 id) 倒序翻页，发布时间相同的时候用文章 id 区分
+func (dao *FeedDaoMysql) cursor(db *gorm.DB, idColumn string, ctime, articleId int64) *gorm.DB {
+	if ctime == 0 {
+		return db
+	}
+	return db.Where("create_time < ? OR (create_time = ? AND "+idColumn+" < ?)", ctime, ctime, articleId)
+}
+
+// FeedInbox 推模式下每个粉丝的收件箱，CreateTime 是文章第一次发布的时间
+type FeedInbox struct {
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	Uid        int64 `gorm:"uniqueIndex:uid_article;index:uid_ctime"`
+	ArticleId  int64 `gorm:"uniqueIndex:uid_article"`
+	AuthorId   int64
+	CreateTime int64 `gorm:"index:uid_ctime"`
+}
+
+func (f *FeedInbox) TableName() string {
+	return "feed_inbox"
+}
+
+// FeedPullAuthor 发布文章时因为粉丝太多没有推到收件箱的作者，
+// 之后粉丝数降回阈值以下也一直拉，否则这期间发布的文章就看不到了
+type FeedPullAuthor struct {
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	AuthorId   int64 `gorm:"uniqueIndex"`
+	CreateTime int64
+}
+
+func (f *FeedPullAuthor) TableName() string {
+	return "feed_pull_author"
+}
@@ -0,0 +1,205 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	followStatusInactive uint8 = iota
+	followStatusActive
+)
+
+type FollowDao interface {
+	// Follow 关注，返回关注关系是否发生了变化，重复关注不算
+	Follow(ctx context.Context, follower, followee int64) (bool, error)
+	// Unfollow 取消关注，返回关注关系是否发生了变化
+	Unfollow(ctx context.Context, follower, followee int64) (bool, error)
+	// FindFollowers 用户的粉丝，按关注时间倒序
+	FindFollowers(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error)
+	// FindFollowees 用户关注的人，按关注时间倒序
+	FindFollowees(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error)
+	// IsFollowing follower 是否关注了 followee
+	IsFollowing(ctx context.Context, follower, followee int64) (bool, error)
+	CountFollowers(ctx context.Context, followee int64) (int64, error)
+	CountFollowees(ctx context.Context, follower int64) (int64, error)
+	// FindFolloweeIds 用户关注的所有人，最多 limit 个
+	FindFolloweeIds(ctx context.Context, follower int64, limit int) ([]int64, error)
+	// FindFollowerIds 按关系 id 翻页查询粉丝，返回粉丝 id 和这一页最后一条关系的 id
+	FindFollowerIds(ctx context.Context, followee int64, afterId int64, limit int) ([]int64, int64, error)
+	// FilterPopular 从 uids 里面挑出粉丝数不少于 threshold 的用户
+	FilterPopular(ctx context.Context, uids []int64, threshold int64) ([]int64, error)
+	// FindRelations 用户关注别人和被别人关注的所有有效关系
+	FindRelations(ctx context.Context, uid int64) ([]FollowRelation, error)
+}
+
+type FollowDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewFollowDao(db *gorm.DB, l *zap.Logger) FollowDao {
+	if err := db.AutoMigrate(&FollowRelation{}); err != nil {
+		l.Error("初始化关注关系表失败", zap.Error(err))
+	}
+	return &FollowDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *FollowDaoMysql) Follow(ctx context.Context, follower, followee int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	// 先尝试把取消过的关注恢复，没有记录再插入
+	res := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? and followee = ? and status = ?", follower, followee, followStatusInactive).
+		Updates(map[string]any{
+			"status":      followStatusActive,
+			"create_time": now,
+			"update_time": now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+	err := dao.db.WithContext(ctx).Create(&FollowRelation{
+		Follower:   follower,
+		Followee:   followee,
+		Status:     followStatusActive,
+		CreateTime: now,
+		UpdateTime: now,
+	}).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		const uniqueConflictErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueConflictErrNo {
+			// 已经关注过了
+			return false, nil
+		}
+	}
+	return err == nil, err
+}
+
+func (dao *FollowDaoMysql) Unfollow(ctx context.Context, follower, followee int64) (bool, error) {
+	res := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? and followee = ? and status = ?", follower, followee, followStatusActive).
+		Updates(map[string]any{
+			"status":      followStatusInactive,
+			"update_time": time.Now().UnixMilli(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (dao *FollowDaoMysql) FindFollowers(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error) {
+	var relations []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("followee = ? and status = ?", followee, followStatusActive).
+		Order("create_time desc").
+		Offset(offset).Limit(limit).
+		Find(&relations).Error
+	return relations, err
+}
+
+func (dao *FollowDaoMysql) FindFollowees(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error) {
+	var relations []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? and status = ?", follower, followStatusActive).
+		Order("create_time desc").
+		Offset(offset).Limit(limit).
+		Find(&relations).Error
+	return relations, err
+}
+
+func (dao *FollowDaoMysql) IsFollowing(ctx context.Context, follower, followee int64) (bool, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? and followee = ? and status = ?", follower, followee, followStatusActive).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (dao *FollowDaoMysql) CountFollowers(ctx context.Context, followee int64) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("followee = ? and status = ?", followee, followStatusActive).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+func (dao *FollowDaoMysql) CountFollowees(ctx context.Context, follower int64) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? and status = ?", follower, followStatusActive).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+func (dao *FollowDaoMysql) FindFolloweeIds(ctx context.Context, follower int64, limit int) ([]int64, error) {
+	var ids []int64
+	err := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ? and status = ?", follower, followStatusActive).
+		Order("create_time desc").
+		Limit(limit).
+		Pluck("followee", &ids).Error
+	return ids, err
+}
+
+func (dao *FollowDaoMysql) FindFollowerIds(ctx context.Context, followee int64, afterId int64, limit int) ([]int64, int64, error) {
+	var relations []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Select("id", "follower").
+		Where("followee = ? and status = ? and id > ?", followee, followStatusActive, afterId).
+		Order("id asc").
+		Limit(limit).
+		Find(&relations).Error
+	if err != nil || len(relations) == 0 {
+		return nil, afterId, err
+	}
+	ids := make([]int64, 0, len(relations))
+	for _, r := range relations {
+		ids = append(ids, r.Follower)
+	}
+	return ids, relations[len(relations)-1].Id, nil
+}
+
+func (dao *FollowDaoMysql) FilterPopular(ctx context.Context, uids []int64, threshold int64) ([]int64, error) {
+	var ids []int64
+	if len(uids) == 0 {
+		return ids, nil
+	}
+	err := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("followee IN ? and status = ?", uids, followStatusActive).
+		Group("followee").
+		Having("COUNT(*) >= ?", threshold).
+		Pluck("followee", &ids).Error
+	return ids, err
+}
+
+func (dao *FollowDaoMysql) FindRelations(ctx context.Context, uid int64) ([]FollowRelation, error) {
+	var relations []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("(follower = ? or followee = ?) and status = ?", uid, uid, followStatusActive).
+		Order("create_time desc").
+		Find(&relations).Error
+	return relations, err
+}
+
+// FollowRelation 关注关系，取消关注只改状态，和 UserLikeBiz 一样
+type FollowRelation struct {
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	Follower   int64 `gorm:"uniqueIndex:follower_followee"`
+	Followee   int64 `gorm:"uniqueIndex:follower_followee;index"`
+	Status     uint8
+	CreateTime int64
+	UpdateTime int64
+}
+
+func (r *FollowRelation) TableName() string {
+	return "follow_relation"
+}
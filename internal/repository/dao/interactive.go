@@ -10,8 +10,14 @@ import (
 
 type InteractiveDao interface {
 	IncreaseReadCount(ctx context.Context, biz string, bizId int64) error
-	IncreaseLikeCount(ctx context.Context, biz string, bizId int64, uid int64) error
-	DeletedLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	// IncreaseLikeCount 点赞，重复点赞不会重复计数，返回是否真的点了赞
+	IncreaseLikeCount(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	// DeletedLike 取消点赞，没有点过赞的时候什么都不做，返回是否真的取消了
+	DeletedLike(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	// ListLikesByUid 用户所有有效的点赞记录
+	ListLikesByUid(ctx context.Context, uid int64) ([]UserLikeBiz, error)
+	// GetByIds 批量查询计数，没有记录的不返回
+	GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error)
 }
 
 type InteractiveDaoMysql struct {
@@ -19,53 +25,73 @@ type InteractiveDaoMysql struct {
 	logger *zap.Logger
 }
 
-func (dao *InteractiveDaoMysql) DeletedLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+func (dao *InteractiveDaoMysql) DeletedLike(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
 	now := time.Now().UnixMilli()
-	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.Model(&UserLikeBiz{}).
-			Where("uid = ? and biz = ? and biz_id = ?", uid, biz, bizId).
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserLikeBiz{}).
+			Where("uid = ? and biz = ? and biz_id = ? and status = ?", uid, biz, bizId, 1).
 			Updates(map[string]any{
 				"status":      0,
 				"update_time": now,
-			}).Error
-		if err != nil {
-			return err
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
 		}
+		changed = true
 		return tx.Model(&Interactive{}).
-			Where("biz = ? and biz_id = ?", biz, bizId).
+			Where("biz = ? and biz_id = ? and like_count > 0", biz, bizId).
 			Updates(map[string]any{
 				"like_count":  gorm.Expr("like_count - 1"),
 				"update_time": now,
 			}).Error
 	})
+	return changed && err == nil, err
 }
 
-func (dao *InteractiveDaoMysql) IncreaseLikeCount(ctx context.Context, biz string, bizId int64, uid int64) error {
+func (dao *InteractiveDaoMysql) IncreaseLikeCount(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
 	now := time.Now().UnixMilli()
-	return dao.db.WithContext(ctx).
+	changed := false
+	err := dao.db.WithContext(ctx).
 		Transaction(func(tx *gorm.DB) error {
-			err := tx.Model(&UserLikeBiz{}).Clauses(clause.OnConflict{
-				DoUpdates: clause.Assignments(map[string]any{
+			// 先恢复取消过的点赞，没有记录再插入，两边都没有变化说明已经点过赞了
+			res := tx.Model(&UserLikeBiz{}).
+				Where("uid = ? and biz = ? and biz_id = ? and status = ?", uid, biz, bizId, 0).
+				Updates(map[string]any{
 					"status":      1,
 					"update_time": now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&UserLikeBiz{
+					Uid:        uid,
+					Biz:        biz,
+					BizId:      bizId,
+					Status:     1,
+					CreateTime: now,
+					UpdateTime: now,
+				})
+				if res.Error != nil || res.RowsAffected == 0 {
+					return res.Error
+				}
+			}
+			changed = true
+			return tx.Clauses(clause.OnConflict{
+				DoUpdates: clause.Assignments(map[string]any{
+					"like_count":  gorm.Expr("like_count + 1"),
+					"update_time": now,
 				}),
-			}).Create(&UserLikeBiz{
-				Uid:        uid,
+			}).Create(&Interactive{
 				Biz:        biz,
 				BizId:      bizId,
-				Status:     1,
+				LikeCount:  1,
 				CreateTime: now,
 				UpdateTime: now,
 			}).Error
-			if err != nil {
-				return err
-			}
-			return tx.Model(&Interactive{}).Clauses(clause.OnConflict{
-				DoUpdates: clause.Assignments(map[string]any{
-					"like_count": gorm.Expr("like_count + 1"),
-				}),
-			}).Error
 		})
+	return changed && err == nil, err
 }
 
 func (dao *InteractiveDaoMysql) IncreaseReadCount(ctx context.Context, biz string, bizId int64) error {
@@ -85,7 +111,56 @@ func (dao *InteractiveDaoMysql) IncreaseReadCount(ctx context.Context, biz strin
 		}).Error
 }
 
+func (dao *InteractiveDaoMysql) ListLikesByUid(ctx context.Context, uid int64) ([]UserLikeBiz, error) {
+	var likes []UserLikeBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? and status = ?", uid, 1).
+		Order("update_time desc").
+		Find(&likes).Error
+	return likes, err
+}
+
+func (dao *InteractiveDaoMysql) GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error) {
+	var interactives []Interactive
+	if len(bizIds) == 0 {
+		return interactives, nil
+	}
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? and biz_id IN ?", biz, bizIds).
+		Find(&interactives).Error
+	return interactives, err
+}
+
+// incrCommentCount 评论数变化，和评论的增删放在同一个事务里
+func incrCommentCount(tx *gorm.DB, biz string, bizId int64, delta int64, now int64) error {
+	if delta > 0 {
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"comment_count": gorm.Expr("comment_count + ?", delta),
+				"update_time":   now,
+			}),
+		}).Create(&Interactive{
+			Biz:          biz,
+			BizId:        bizId,
+			CommentCount: delta,
+			CreateTime:   now,
+			UpdateTime:   now,
+		}).Error
+	}
+	return tx.Model(&Interactive{}).
+		Where("biz = ? and biz_id = ?", biz, bizId).
+		Updates(map[string]any{
+			"comment_count": gorm.Expr("GREATEST(comment_count + ?, 0)", delta),
+			"update_time":   now,
+		}).Error
+}
+
 func NewInteractiveDaoMysql(db *gorm.DB, logger *zap.Logger) *InteractiveDaoMysql {
+	// 加唯一索引之前的老数据可能有重复，不先合并的话建索引会失败
+	if err := dedupeInteractive(db); err != nil {
+		logger.Error("合并重复的点赞计数失败", zap.Error(err))
+		return nil
+	}
 	if err := db.AutoMigrate(&Interactive{}); err != nil {
 		logger.Error("初始化点赞收藏表失败", zap.Error(err))
 		return nil
@@ -100,13 +175,68 @@ func NewInteractiveDaoMysql(db *gorm.DB, logger *zap.Logger) *InteractiveDaoMysq
 	}
 }
 
+// dedupeInteractive 唯一索引还没建的时候合并重复的记录，计数加到 id 最小的那条上，
+// 点赞记录只要有一条有效就算点过赞，点赞数按合并之后的点赞记录重新算
+func dedupeInteractive(db *gorm.DB) error {
+	m := db.Migrator()
+	dedupeLikes := m.HasTable(&UserLikeBiz{}) && !m.HasIndex(&UserLikeBiz{}, "uid_biz_bizId")
+	dedupeCounts := m.HasTable(&Interactive{}) && !m.HasIndex(&Interactive{}, "bizId_type")
+	if !dedupeLikes && !dedupeCounts {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if dedupeLikes {
+			err := tx.Exec(`UPDATE user_like_biz u JOIN (
+				SELECT MIN(id) AS id, MAX(status) AS st, MAX(update_time) AS ut
+				FROM user_like_biz GROUP BY uid, biz, biz_id HAVING COUNT(*) > 1
+			) d ON u.id = d.id
+			SET u.status = d.st, u.update_time = d.ut`).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Exec(`DELETE u FROM user_like_biz u JOIN user_like_biz k
+				ON u.uid = k.uid AND u.biz = k.biz AND u.biz_id = k.biz_id AND u.id > k.id`).Error
+			if err != nil {
+				return err
+			}
+		}
+		if !m.HasTable(&Interactive{}) {
+			return nil
+		}
+		if dedupeCounts {
+			err := tx.Exec(`UPDATE interactive i JOIN (
+				SELECT MIN(id) AS id, SUM(read_count) AS rc, SUM(favorite_count) AS fc,
+					SUM(comment_count) AS cc, MAX(update_time) AS ut
+				FROM interactive GROUP BY biz, biz_id HAVING COUNT(*) > 1
+			) d ON i.id = d.id
+			SET i.read_count = d.rc, i.favorite_count = d.fc, i.comment_count = d.cc, i.update_time = d.ut`).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Exec(`DELETE i FROM interactive i JOIN interactive k
+				ON i.biz = k.biz AND i.biz_id = k.biz_id AND i.id > k.id`).Error
+			if err != nil {
+				return err
+			}
+		}
+		if !m.HasTable(&UserLikeBiz{}) {
+			return nil
+		}
+		return tx.Exec(`UPDATE interactive i SET like_count = (
+			SELECT COUNT(*) FROM user_like_biz u
+			WHERE u.biz = i.biz AND u.biz_id = i.biz_id AND u.status = 1
+		)`).Error
+	})
+}
+
 type Interactive struct {
 	Id            int64  `gorm:"primaryKey,autoIncrement"`
-	BizId         int64  `gorm:"uniqueIndex=bizId_type"`
-	Biz           string `gorm:"uniqueIndex=bizId_type"`
+	BizId         int64  `gorm:"uniqueIndex:bizId_type"`
+	Biz           string `gorm:"type:varchar(128);uniqueIndex:bizId_type"`
 	ReadCount     int64
 	LikeCount     int64
 	FavoriteCount int64
+	CommentCount  int64
 	CreateTime    int64
 	UpdateTime    int64
 }
@@ -117,9 +247,9 @@ func (i *Interactive) TableName() string {
 
 type UserLikeBiz struct {
 	Id         int64  `gorm:"primaryKey,autoIncrement"`
-	Uid        int64  `gorm:"uniqueIndex=uid_biz_bizId"`
-	Biz        string `gorm:"uniqueIndex=uid_biz_bizId"`
-	BizId      int64  `gorm:"uniqueIndex=uid_biz_bizId"`
+	Uid        int64  `gorm:"uniqueIndex:uid_biz_bizId"`
+	Biz        string `gorm:"type:varchar(128);uniqueIndex:uid_biz_bizId"`
+	BizId      int64  `gorm:"uniqueIndex:uid_biz_bizId"`
 	Status     int
 	CreateTime int64
 	UpdateTime int64
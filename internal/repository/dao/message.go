@@ -0,0 +1,146 @@
+package dao
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type MessageDao interface {
+	// Insert 写入私信，同时更新双方的会话，接收方未读数加一
+	Insert(ctx context.Context, m Message) (int64, error)
+	// FindMessages 两个人之间的私信，按 id 倒序，maxId 为 0 表示第一页
+	FindMessages(ctx context.Context, uid, peer int64, maxId int64, limit int) ([]Message, error)
+	FindMessagesByIds(ctx context.Context, ids []int64) ([]Message, error)
+	// FindConversations 用户的会话，按最近一条私信的时间倒序
+	FindConversations(ctx context.Context, uid int64, offset, limit int) ([]Conversation, error)
+	// ClearUnread 会话的未读数清零
+	ClearUnread(ctx context.Context, uid, peer int64) error
+	// FindByUser 用户发出和收到的所有私信，按 id 倒序
+	FindByUser(ctx context.Context, uid int64) ([]Message, error)
+}
+
+type MessageDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewMessageDao(db *gorm.DB, l *zap.Logger) MessageDao {
+	if err := db.AutoMigrate(&Message{}, &Conversation{}); err != nil {
+		l.Error("初始化私信表失败", zap.Error(err))
+	}
+	return &MessageDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *MessageDaoMysql) Insert(ctx context.Context, m Message) (int64, error) {
+	now := time.Now().UnixMilli()
+	m.SmallUid, m.BigUid = pair(m.Sender, m.Receiver)
+	m.CreateTime = now
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		if err := dao.upsertConversation(tx, m.Sender, m.Receiver, m.Id, 0, now); err != nil {
+			return err
+		}
+		return dao.upsertConversation(tx, m.Receiver, m.Sender, m.Id, 1, now)
+	})
+	return m.Id, err
+}
+
+func (dao *MessageDaoMysql) upsertConversation(tx *gorm.DB, uid, peer, msgId, unread, now int64) error {
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"last_msg_id": msgId,
+			"unread":      gorm.Expr("unread + ?", unread),
+			"update_time": now,
+		}),
+	}).Create(&Conversation{
+		Uid:        uid,
+		Peer:       peer,
+		LastMsgId:  msgId,
+		Unread:     unread,
+		CreateTime: now,
+		UpdateTime: now,
+	}).Error
+}
+
+func (dao *MessageDaoMysql) FindMessages(ctx context.Context, uid, peer int64, maxId int64, limit int) ([]Message, error) {
+	var messages []Message
+	small, big := pair(uid, peer)
+	db := dao.db.WithContext(ctx).Where("small_uid = ? and big_uid = ?", small, big)
+	if maxId > 0 {
+		db = db.Where("id < ?", maxId)
+	}
+	err := db.Order("id desc").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+func (dao *MessageDaoMysql) FindMessagesByIds(ctx context.Context, ids []int64) ([]Message, error) {
+	var messages []Message
+	if len(ids) == 0 {
+		return messages, nil
+	}
+	err := dao.db.WithContext(ctx).Where("id IN ?", ids).Find(&messages).Error
+	return messages, err
+}
+
+func (dao *MessageDaoMysql) FindByUser(ctx context.Context, uid int64) ([]Message, error) {
+	var messages []Message
+	err := dao.db.WithContext(ctx).
+		Where("sender = ? or receiver = ?", uid, uid).
+		Order("id desc").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (dao *MessageDaoMysql) FindConversations(ctx context.Context, uid int64, offset, limit int) ([]Conversation, error) {
+	var conversations []Conversation
+	err := dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Order("update_time desc").
+		Offset(offset).Limit(limit).
+		Find(&conversations).Error
+	return conversations, err
+}
+
+func (dao *MessageDaoMysql) ClearUnread(ctx context.Context, uid, peer int64) error {
+	return dao.db.WithContext(ctx).Model(&Conversation{}).
+		Where("uid = ? and peer = ? and unread > 0", uid, peer).
+		Update("unread", 0).Error
+}
+
+// pair 两个人之间的私信按 (小 uid, 大 uid) 归到一起
+func pair(a, b int64) (int64, int64) {
+	if a < b {
+		return a, b
+	}
+	return b, a
+}
+
+// Message 私信，SmallUid BigUid 是双方 uid 排序之后的结果，方便按会话查询
+type Message struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	SmallUid   int64  `gorm:"index:idx_pair,priority:1"`
+	BigUid     int64  `gorm:"index:idx_pair,priority:2"`
+	Sender     int64  `gorm:"index"`
+	Receiver   int64  `gorm:"index"`
+	Content    string `gorm:"type:varchar(1024)"`
+	CreateTime int64
+}
+
+// Conversation 会话，每个人一份
+type Conversation struct {
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	Uid        int64 `gorm:"uniqueIndex:uk_uid_peer,priority:1;index:idx_uid_utime,priority:1"`
+	Peer       int64 `gorm:"uniqueIndex:uk_uid_peer,priority:2"`
+	LastMsgId  int64
+	Unread     int64
+	CreateTime int64
+	UpdateTime int64 `gorm:"index:idx_uid_utime,priority:2"`
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/dao/interactive.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/dao/interactive.go -package=mock -destination=internal/repository/dao/mock/interactive.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInteractiveDao is a mock of InteractiveDao interface.
+type MockInteractiveDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractiveDaoMockRecorder
+}
+
+// MockInteractiveDaoMockRecorder is the mock recorder for MockInteractiveDao.
+type MockInteractiveDaoMockRecorder struct {
+	mock *MockInteractiveDao
+}
+
+// NewMockInteractiveDao creates a new mock instance.
+func NewMockInteractiveDao(ctrl *gomock.Controller) *MockInteractiveDao {
+	mock := &MockInteractiveDao{ctrl: ctrl}
+	mock.recorder = &MockInteractiveDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractiveDao) EXPECT() *MockInteractiveDaoMockRecorder {
+	return m.recorder
+}
+
+// DeletedLike mocks base method.
+func (m *MockInteractiveDao) DeletedLike(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletedLike", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletedLike indicates an expected call of DeletedLike.
+func (mr *MockInteractiveDaoMockRecorder) DeletedLike(ctx, biz, bizId, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletedLike", reflect.TypeOf((*MockInteractiveDao)(nil).DeletedLike), ctx, biz, bizId, uid)
+}
+
+// GetByIds mocks base method.
+func (m *MockInteractiveDao) GetByIds(ctx context.Context, biz string, bizIds []int64) ([]dao.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIds", ctx, biz, bizIds)
+	ret0, _ := ret[0].([]dao.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIds indicates an expected call of GetByIds.
+func (mr *MockInteractiveDaoMockRecorder) GetByIds(ctx, biz, bizIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIds", reflect.TypeOf((*MockInteractiveDao)(nil).GetByIds), ctx, biz, bizIds)
+}
+
+// IncreaseLikeCount mocks base method.
+func (m *MockInteractiveDao) IncreaseLikeCount(ctx context.Context, biz string, bizId, uid int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseLikeCount", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncreaseLikeCount indicates an expected call of IncreaseLikeCount.
+func (mr *MockInteractiveDaoMockRecorder) IncreaseLikeCount(ctx, biz, bizId, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseLikeCount", reflect.TypeOf((*MockInteractiveDao)(nil).IncreaseLikeCount), ctx, biz, bizId, uid)
+}
+
+// IncreaseReadCount mocks base method.
+func (m *MockInteractiveDao) IncreaseReadCount(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseReadCount", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncreaseReadCount indicates an expected call of IncreaseReadCount.
+func (mr *MockInteractiveDaoMockRecorder) IncreaseReadCount(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseReadCount", reflect.TypeOf((*MockInteractiveDao)(nil).IncreaseReadCount), ctx, biz, bizId)
+}
+
+// ListLikesByUid mocks base method.
+func (m *MockInteractiveDao) ListLikesByUid(ctx context.Context, uid int64) ([]dao.UserLikeBiz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLikesByUid", ctx, uid)
+	ret0, _ := ret[0].([]dao.UserLikeBiz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLikesByUid indicates an expected call of ListLikesByUid.
+func (mr *MockInteractiveDaoMockRecorder) ListLikesByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLikesByUid", reflect.TypeOf((*MockInteractiveDao)(nil).ListLikesByUid), ctx, uid)
+}
@@ -0,0 +1,160 @@
+package dao
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	notificationUnread uint8 = 0
+	notificationRead   uint8 = 1
+)
+
+type NotificationDao interface {
+	// Upsert 有未读的同类通知就合并进去，否则新建一条，返回是否新建
+	Upsert(ctx context.Context, n Notification) (bool, error)
+	// List 按最近更新倒序
+	List(ctx context.Context, uid int64, offset, limit int) ([]Notification, error)
+	CountUnread(ctx context.Context, uid int64) (int64, error)
+	// MarkRead 返回真正从未读变成已读的条数
+	MarkRead(ctx context.Context, uid int64, ids []int64) (int64, error)
+	MarkAllRead(ctx context.Context, uid int64) error
+}
+
+type NotificationDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewNotificationDao(db *gorm.DB, l *zap.Logger) NotificationDao {
+	// 加 unread 列之前的未读通知，每组只保留最新的一条参与合并
+	backfill := db.Migrator().HasTable(&Notification{}) && !db.Migrator().HasColumn(&Notification{}, "Unread")
+	if err := db.AutoMigrate(&Notification{}, &NotificationActor{}); err != nil {
+		l.Error("初始化通知表失败", zap.Error(err))
+	} else if backfill {
+		err = db.Exec(`UPDATE notifications n JOIN (
+			SELECT MAX(id) AS id FROM notifications WHERE status = ? GROUP BY uid, type, biz, biz_id
+		) d ON n.id = d.id SET n.unread = TRUE`, notificationUnread).Error
+		if err != nil {
+			l.Error("回填未读通知失败", zap.Error(err))
+		}
+	}
+	return &NotificationDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+// Upsert 靠未读分组的唯一索引合并，并发的时候也只会有一条未读通知，
+// 触发过的人单独记一张表，同一个人反复触发只算一次
+func (dao *NotificationDaoMysql) Upsert(ctx context.Context, n Notification) (bool, error) {
+	now := time.Now().UnixMilli()
+	unread := true
+	created := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		n.Status = notificationUnread
+		n.Unread = &unread
+		n.ActorCount = 0
+		n.CreateTime = now
+		n.UpdateTime = now
+		res := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"last_actor":  n.LastActor,
+				"update_time": now,
+			}),
+		}).Create(&n)
+		if res.Error != nil {
+			return res.Error
+		}
+		// MySQL 插入新行影响 1 行，更新已有的行影响 2 行
+		created = res.RowsAffected == 1
+		var id int64
+		err := tx.Model(&Notification{}).
+			Where("uid = ? and type = ? and biz = ? and biz_id = ? and unread = ?", n.Uid, n.Type, n.Biz, n.BizId, true).
+			Pluck("id", &id).Error
+		if err != nil {
+			return err
+		}
+		res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&NotificationActor{
+			NotificationId: id,
+			Actor:          n.LastActor,
+			CreateTime:     now,
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&Notification{}).Where("id = ?", id).
+			Update("actor_count", gorm.Expr("actor_count + 1")).Error
+	})
+	return created && err == nil, err
+}
+
+func (dao *NotificationDaoMysql) List(ctx context.Context, uid int64, offset, limit int) ([]Notification, error) {
+	var notifications []Notification
+	err := dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Order("update_time desc, id desc").
+		Offset(offset).Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (dao *NotificationDaoMysql) CountUnread(ctx context.Context, uid int64) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid = ? and status = ?", uid, notificationUnread).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+func (dao *NotificationDaoMysql) MarkRead(ctx context.Context, uid int64, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid = ? and id IN ? and status = ?", uid, ids, notificationUnread).
+		Updates(map[string]any{
+			"status":      notificationRead,
+			"unread":      nil,
+			"update_time": time.Now().UnixMilli(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+func (dao *NotificationDaoMysql) MarkAllRead(ctx context.Context, uid int64) error {
+	return dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid = ? and status = ?", uid, notificationUnread).
+		Updates(map[string]any{
+			"status":      notificationRead,
+			"unread":      nil,
+			"update_time": time.Now().UnixMilli(),
+		}).Error
+}
+
+// Notification 站内通知，uid 是接收人
+type Notification struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	Uid        int64  `gorm:"index:idx_uid_group,priority:1;index:idx_uid_utime,priority:1;uniqueIndex:uk_unread_group,priority:1"`
+	Type       uint8  `gorm:"index:idx_uid_group,priority:2;uniqueIndex:uk_unread_group,priority:2"`
+	Biz        string `gorm:"type:varchar(128);index:idx_uid_group,priority:3;uniqueIndex:uk_unread_group,priority:3"`
+	BizId      int64  `gorm:"index:idx_uid_group,priority:4;uniqueIndex:uk_unread_group,priority:4"`
+	LastActor  int64
+	ActorCount int64
+	// Status 0 未读 1 已读
+	Status uint8 `gorm:"index:idx_uid_group,priority:5"`
+	// Unread 未读的时候是 true，已读之后置空，唯一索引不管空值，保证每组只有一条未读
+	Unread     *bool `gorm:"uniqueIndex:uk_unread_group,priority:5"`
+	CreateTime int64
+	UpdateTime int64 `gorm:"index:idx_uid_utime,priority:2"`
+}
+
+// NotificationActor 触发过这条通知的人，用来算不重复的人数
+type NotificationActor struct {
+	Id             int64 `gorm:"primaryKey,autoIncrement"`
+	NotificationId int64 `gorm:"uniqueIndex:uk_notification_actor,priority:1"`
+	Actor          int64 `gorm:"uniqueIndex:uk_notification_actor,priority:2"`
+	CreateTime     int64
+}
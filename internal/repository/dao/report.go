@@ -0,0 +1,197 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrReportDuplicate = errors.New("重复举报")
+
+type ReportDao interface {
+	// Insert 同一个人对同一个对象只能有一条未处理的举报
+	Insert(ctx context.Context, r Report) (int64, error)
+	FindById(ctx context.Context, id int64) (Report, error)
+	// FindByStatus 按创建时间正序，先举报的先处理
+	FindByStatus(ctx context.Context, status uint8, offset, limit int) ([]Report, error)
+	// Claim 认领待处理的举报，认领超过 expireBefore 还没处理的可以被别人重新认领
+	Claim(ctx context.Context, id, moderator int64, expireBefore int64) (bool, error)
+	// Resolve 只有认领的人可以处理，认领早于 claimedAfter 的已经过期，同一个对象上其他待处理的举报一起结案
+	Resolve(ctx context.Context, id, moderator int64, action uint8, remark string, claimedAfter int64) (bool, error)
+	FindLogs(ctx context.Context, reportId int64, offset, limit int) ([]ModerationLog, error)
+}
+
+type ReportDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewReportDao(db *gorm.DB, l *zap.Logger) ReportDao {
+	if err := db.AutoMigrate(&Report{}, &ModerationLog{}); err != nil {
+		l.Error("初始化举报表失败", zap.Error(err))
+	}
+	return &ReportDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *ReportDaoMysql) Insert(ctx context.Context, r Report) (int64, error) {
+	now := time.Now().UnixMilli()
+	open := true
+	r.Status = domain.ReportStatusPending.ToUint8()
+	r.Open = &open
+	r.CreateTime = now
+	r.UpdateTime = now
+	err := dao.db.WithContext(ctx).Create(&r).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		const uniqueConflictErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueConflictErrNo {
+			return 0, ErrReportDuplicate
+		}
+	}
+	return r.Id, err
+}
+
+func (dao *ReportDaoMysql) FindById(ctx context.Context, id int64) (Report, error) {
+	var r Report
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&r).Error
+	return r, err
+}
+
+func (dao *ReportDaoMysql) FindByStatus(ctx context.Context, status uint8, offset, limit int) ([]Report, error) {
+	var reports []Report
+	err := dao.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("id asc").
+		Offset(offset).Limit(limit).
+		Find(&reports).Error
+	return reports, err
+}
+
+func (dao *ReportDaoMysql) Claim(ctx context.Context, id, moderator int64, expireBefore int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	var claimed bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Report{}).
+			Where("id = ? AND (status = ? OR (status = ? AND update_time < ?))",
+				id, domain.ReportStatusPending.ToUint8(), domain.ReportStatusClaimed.ToUint8(), expireBefore).
+			Updates(map[string]any{
+				"status":      domain.ReportStatusClaimed.ToUint8(),
+				"moderator":   moderator,
+				"update_time": now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		claimed = true
+		var r Report
+		if err := tx.Where("id = ?", id).First(&r).Error; err != nil {
+			return err
+		}
+		return dao.insertLog(tx, r, moderator, domain.ModerationOperationClaim.ToUint8(), 0, "", now)
+	})
+	return claimed, err
+}
+
+func (dao *ReportDaoMysql) Resolve(ctx context.Context, id, moderator int64, action uint8, remark string,
+	claimedAfter int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	var resolved bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var r Report
+		err := tx.Where("id = ? AND status = ? AND moderator = ? AND update_time >= ?",
+			id, domain.ReportStatusClaimed.ToUint8(), moderator, claimedAfter).
+			First(&r).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		values := map[string]any{
+			"status":      domain.ReportStatusResolved.ToUint8(),
+			"open":        nil,
+			"moderator":   moderator,
+			"action":      action,
+			"update_time": now,
+		}
+		res := tx.Model(&Report{}).
+			Where("id = ? AND status = ? AND moderator = ? AND update_time >= ?",
+				id, domain.ReportStatusClaimed.ToUint8(), moderator, claimedAfter).
+			Updates(values)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		// 别人认领的不动，由认领的人自己结案
+		err = tx.Model(&Report{}).
+			Where("biz = ? AND biz_id = ? AND status = ?", r.Biz, r.BizId, domain.ReportStatusPending.ToUint8()).
+			Updates(values).Error
+		if err != nil {
+			return err
+		}
+		resolved = true
+		return dao.insertLog(tx, r, moderator, domain.ModerationOperationResolve.ToUint8(), action, remark, now)
+	})
+	return resolved, err
+}
+
+func (dao *ReportDaoMysql) FindLogs(ctx context.Context, reportId int64, offset, limit int) ([]ModerationLog, error) {
+	var logs []ModerationLog
+	db := dao.db.WithContext(ctx)
+	if reportId > 0 {
+		db = db.Where("report_id = ?", reportId)
+	}
+	err := db.Order("id desc").Offset(offset).Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+func (dao *ReportDaoMysql) insertLog(tx *gorm.DB, r Report, moderator int64, operation, action uint8, remark string, now int64) error {
+	return tx.Create(&ModerationLog{
+		Moderator:  moderator,
+		ReportId:   r.Id,
+		Biz:        r.Biz,
+		BizId:      r.BizId,
+		Operation:  operation,
+		Action:     action,
+		Remark:     remark,
+		CreateTime: now,
+	}).Error
+}
+
+// Report 用户举报，Open 只在未结案的时候为 true，结案后置空，
+// 这样唯一索引只限制未处理的重复举报，结案后同一个人还可以再举报
+type Report struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Reporter int64  `gorm:"uniqueIndex:uk_reporter_biz_open,priority:1"`
+	Biz      string `gorm:"type:varchar(32);uniqueIndex:uk_reporter_biz_open,priority:2;index:idx_biz,priority:1"`
+	BizId    int64  `gorm:"uniqueIndex:uk_reporter_biz_open,priority:3;index:idx_biz,priority:2"`
+	Open     *bool  `gorm:"uniqueIndex:uk_reporter_biz_open,priority:4"`
+	Reason   string `gorm:"type:varchar(1024)"`
+	// Status 1 待处理 2 已认领 3 已结案
+	Status    uint8 `gorm:"index"`
+	Moderator int64
+	// Action 1 驳回 2 下架文章 3 删除评论
+	Action     uint8
+	CreateTime int64
+	UpdateTime int64
+}
+
+// ModerationLog 审核操作记录
+type ModerationLog struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	Moderator int64 `gorm:"index"`
+	ReportId  int64 `gorm:"index"`
+	Biz       string
+	BizId     int64
+	// Operation 1 认领 2 结案
+	Operation  uint8
+	Action     uint8
+	Remark     string `gorm:"type:varchar(1024)"`
+	CreateTime int64
+}
@@ -0,0 +1,69 @@
+package dao
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type RoleDao interface {
+	// FindByUid 用户拥有的全部角色
+	FindByUid(ctx context.Context, uid int64) ([]UserRole, error)
+	// Insert 授予角色，已经拥有的角色直接忽略
+	Insert(ctx context.Context, uid int64, role string) error
+	// Delete 收回角色
+	Delete(ctx context.Context, uid int64, role string) error
+}
+
+type RoleDaoImpl struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewRoleDao(db *gorm.DB, l *zap.Logger) RoleDao {
+	if err := db.AutoMigrate(&UserRole{}); err != nil {
+		l.Error("初始化用户角色表失败", zap.Error(err))
+	}
+	return &RoleDaoImpl{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *RoleDaoImpl) FindByUid(ctx context.Context, uid int64) ([]UserRole, error) {
+	var roles []UserRole
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).Find(&roles).Error
+	return roles, err
+}
+
+func (dao *RoleDaoImpl) Insert(ctx context.Context, uid int64, role string) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&UserRole{
+			Uid:        uid,
+			Role:       role,
+			CreateTime: now,
+			UpdateTime: now,
+		}).Error
+}
+
+func (dao *RoleDaoImpl) Delete(ctx context.Context, uid int64, role string) error {
+	return dao.db.WithContext(ctx).
+		Where("uid = ? and role = ?", uid, role).
+		Delete(&UserRole{}).Error
+}
+
+type UserRole struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	Uid        int64  `gorm:"uniqueIndex:uid_role"`
+	Role       string `gorm:"type:varchar(32);uniqueIndex:uid_role"`
+	CreateTime int64
+	UpdateTime int64
+}
+
+func (r *UserRole) TableName() string {
+	return "user_role"
+}
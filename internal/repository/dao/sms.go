@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+type SmsTaskDao interface {
+	Insert(ctx context.Context, t SmsTask) (int64, error)
+	// Preempt 抢占到期的任务，抢占之后超过 staleBefore 还没有结果的认为实例已经挂了，可以重新抢占
+	Preempt(ctx context.Context, now int64, staleBefore int64, limit int) ([]SmsTask, error)
+	MarkSuccess(ctx context.Context, id int64) error
+	// MarkFailed 记录失败原因，status 为等待重试或者最终失败
+	MarkFailed(ctx context.Context, id int64, status uint8, attempts int, nextTime int64, lastError string) error
+}
+
+type SmsTaskDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewSmsTaskDao(db *gorm.DB, l *zap.Logger) SmsTaskDao {
+	// 模板字段以前叫 tpl_id，改名之前的任务不能丢
+	m := db.Migrator()
+	if m.HasTable(&SmsTask{}) && m.HasColumn(&SmsTask{}, "tpl_id") && !m.HasColumn(&SmsTask{}, "tpl") {
+		if err := m.RenameColumn(&SmsTask{}, "tpl_id", "tpl"); err != nil {
+			l.Error("短信任务表模板字段改名失败", zap.Error(err))
+		}
+	}
+	if err := db.AutoMigrate(&SmsTask{}); err != nil {
+		l.Error("初始化短信任务表失败", zap.Error(err))
+	}
+	return &SmsTaskDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *SmsTaskDaoMysql) Insert(ctx context.Context, t SmsTask) (int64, error) {
+	now := time.Now().UnixMilli()
+	t.Status = domain.SmsTaskStatusWaiting.ToUint8()
+	t.CreateTime = now
+	t.UpdateTime = now
+	err := dao.db.WithContext(ctx).Create(&t).Error
+	return t.Id, err
+}
+
+func (dao *SmsTaskDaoMysql) Preempt(ctx context.Context, now int64, staleBefore int64, limit int) ([]SmsTask, error) {
+	var candidates []SmsTask
+	err := dao.db.WithContext(ctx).
+		Where("(status = ? AND next_time <= ?) OR (status = ? AND update_time < ?)",
+			domain.SmsTaskStatusWaiting.ToUint8(), now, domain.SmsTaskStatusSending.ToUint8(), staleBefore).
+		Order("next_time asc").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	// 多个实例同时抢，按版本号更新，更新成功的才算抢到
+	tasks := make([]SmsTask, 0, len(candidates))
+	for _, t := range candidates {
+		res := dao.db.WithContext(ctx).Model(&SmsTask{}).
+			Where("id = ? AND version = ?", t.Id, t.Version).
+			Updates(map[string]any{
+				"status":      domain.SmsTaskStatusSending.ToUint8(),
+				"version":     gorm.Expr("version + 1"),
+				"update_time": now,
+			})
+		if res.Error != nil {
+			return tasks, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		t.Status = domain.SmsTaskStatusSending.ToUint8()
+		t.Version++
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func (dao *SmsTaskDaoMysql) MarkSuccess(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Model(&SmsTask{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":      domain.SmsTaskStatusSuccess.ToUint8(),
+			"attempts":    gorm.Expr("attempts + 1"),
+			"update_time": time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *SmsTaskDaoMysql) MarkFailed(ctx context.Context, id int64, status uint8, attempts int, nextTime int64, lastError string) error {
+	return dao.db.WithContext(ctx).Model(&SmsTask{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":      status,
+			"attempts":    attempts,
+			"next_time":   nextTime,
+			"last_error":  lastError,
+			"update_time": time.Now().UnixMilli(),
+		}).Error
+}
+
+// SmsTask 短信重试任务，Args 和 Numbers 存 JSON
+type SmsTask struct {
+	Id      int64  `gorm:"primaryKey,autoIncrement"`
+	Tpl     string `gorm:"column:tpl;type:varchar(64)"`
+	Args    string `gorm:"type:varchar(1024)"`
+	Numbers string `gorm:"type:varchar(4096)"`
+	// Status 1 等待重试 2 发送中 3 成功 4 失败
+	Status     uint8 `gorm:"index:idx_status_next_time,priority:1"`
+	Attempts   int
+	NextTime   int64  `gorm:"index:idx_status_next_time,priority:2"`
+	LastError  string `gorm:"type:varchar(1024)"`
+	Version    int64
+	CreateTime int64
+	UpdateTime int64
+}
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+type SmsRecordDao interface {
+	InsertBatch(ctx context.Context, records []SmsRecord) error
+	// UpdateDelivery 只更新还在等待回执的记录，重复的回执不会覆盖第一次的结果
+	UpdateDelivery(ctx context.Context, r SmsRecord) (int64, error)
+	FindByPhoneHash(ctx context.Context, phoneHash string, offset int, limit int) ([]SmsRecord, error)
+}
+
+type SmsRecordDaoMysql struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewSmsRecordDao(db *gorm.DB, l *zap.Logger) SmsRecordDao {
+	if err := db.AutoMigrate(&SmsRecord{}); err != nil {
+		l.Error("初始化短信发送记录表失败", zap.Error(err))
+	}
+	return &SmsRecordDaoMysql{
+		db:     db,
+		logger: l,
+	}
+}
+
+func (dao *SmsRecordDaoMysql) InsertBatch(ctx context.Context, records []SmsRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	for i := range records {
+		records[i].CreateTime = now
+		records[i].UpdateTime = now
+	}
+	return dao.db.WithContext(ctx).Create(&records).Error
+}
+
+func (dao *SmsRecordDaoMysql) UpdateDelivery(ctx context.Context, r SmsRecord) (int64, error) {
+	res := dao.db.WithContext(ctx).Model(&SmsRecord{}).
+		Where("provider = ? AND request_id = ? AND phone_hash = ? AND status = ?",
+			r.Provider, r.RequestId, r.PhoneHash, domain.SmsRecordStatusSent.ToUint8()).
+		Updates(map[string]any{
+			"status":        r.Status,
+			"error":         r.Error,
+			"delivery_time": r.DeliveryTime,
+			"update_time":   time.Now().UnixMilli(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+func (dao *SmsRecordDaoMysql) FindByPhoneHash(ctx context.Context, phoneHash string, offset int, limit int) ([]SmsRecord, error) {
+	var records []SmsRecord
+	err := dao.db.WithContext(ctx).
+		Where("phone_hash = ?", phoneHash).
+		Order("id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+// SmsRecord 短信发送记录，一个号码一条，Phone 是打码之后的手机号
+type SmsRecord struct {
+	Id        int64  `gorm:"primaryKey,autoIncrement"`
+	Provider  string `gorm:"type:varchar(32);index:idx_provider_request_id,priority:1"`
+	RequestId string `gorm:"type:varchar(128);index:idx_provider_request_id,priority:2"`
+	Tpl       string `gorm:"type:varchar(64)"`
+	Phone     string `gorm:"type:varchar(32)"`
+	PhoneHash string `gorm:"type:char(64);index"`
+	// Latency 调用服务商的耗时，毫秒
+	Latency int64
+	// Status 1 失败 2 已发送 3 已送达 4 未送达
+	Status       uint8
+	Error        string `gorm:"type:varchar(1024)"`
+	DeliveryTime int64
+	CreateTime   int64
+	UpdateTime   int64
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao/article"
 	"github.com/go-sql-driver/mysql"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -11,18 +13,33 @@ import (
 )
 
 var (
-	ErrUserDuplicateEmail = errors.New("邮箱冲突")
-	ErrUserNotFound       = gorm.ErrRecordNotFound
+	ErrUserDuplicateEmail      = errors.New("邮箱冲突")
+	ErrUserDuplicatePhone      = errors.New("手机号冲突")
+	ErrUserDuplicateOAuth2     = errors.New("第三方账号已被绑定")
+	ErrUserNotFound            = gorm.ErrRecordNotFound
+	ErrOAuth2ProviderNotExists = errors.New("第三方登录提供方不存在")
 )
 
+// oauth2Columns 第三方登录提供方对应的 open id 列
+var oauth2Columns = map[string]string{
+	"github": "github_id",
+	"wechat": "wechat_open_id",
+}
+
 // User 用户数据库对象
 type User struct {
-	Id         int64          `gorm:"primaryKey,autoIncrement"` // 用户ID
-	Email      sql.NullString `gorm:"unique"`                   // 用户邮箱
-	Password   string         // 用户密码
-	Phone      sql.NullString `gorm:"unique"`
-	CreateTime int64          // 创建时间 毫秒数
-	UpdateTime int64          // 更新时间 毫秒数
+	Id           int64          `gorm:"primaryKey,autoIncrement"` // 用户ID
+	Email        sql.NullString `gorm:"unique"`                   // 用户邮箱
+	Password     string         // 用户密码
+	Phone        sql.NullString `gorm:"unique"`
+	Verified     bool           // 是否已经验证邮箱或手机号
+	GithubId     sql.NullString `gorm:"unique"` // GitHub 用户 id
+	WechatOpenId sql.NullString `gorm:"unique"` // 微信 openid
+	Status       uint8          // 用户状态 0 正常 1 封禁
+	// SessionVersion 会话版本，Redis 里面只是缓存，数据丢失之后从这里恢复
+	SessionVersion int64
+	CreateTime     int64 // 创建时间 毫秒数
+	UpdateTime     int64 // 更新时间 毫秒数
 }
 
 type UserDao interface {
@@ -31,6 +48,24 @@ type UserDao interface {
 	Insert(ctx context.Context, u User) error
 	FindById(ctx context.Context, id int64) (User, error)
 	FindByPhone(ctx context.Context, phone string) (User, error)
+	// UpdatePassword 更新密码，password 为加密之后的结果
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	// UpdateVerifiedByEmail 邮箱验证通过，返回被验证的用户
+	UpdateVerifiedByEmail(ctx context.Context, email string) (User, error)
+	// FindByOAuth2 根据第三方登录的 open id 查找用户
+	FindByOAuth2(ctx context.Context, provider string, openId string) (User, error)
+	// UpdateOAuth2 给已有用户绑定第三方账号
+	UpdateOAuth2(ctx context.Context, id int64, provider string, openId string) error
+	// UpdatePhone 绑定或更换手机号，返回更新之后的用户
+	UpdatePhone(ctx context.Context, id int64, phone string) (User, error)
+	// UpdateEmail 绑定或更换邮箱，新邮箱已经通过验证码校验，返回更新之后的用户
+	UpdateEmail(ctx context.Context, id int64, email string) (User, error)
+	// UpdateStatus 更新用户状态，封禁或者解封
+	UpdateStatus(ctx context.Context, id int64, status uint8) error
+	// IncrSessionVersion 会话版本加一，返回新的版本，新版本一定大于 floor
+	IncrSessionVersion(ctx context.Context, id int64, floor int64) (int64, error)
+	// Delete 注销用户，级联删除用户的文章和点赞记录
+	Delete(ctx context.Context, id int64) error
 }
 
 type UserDaoImpl struct {
@@ -39,8 +74,15 @@ type UserDaoImpl struct {
 }
 
 func NewUserDao(db *gorm.DB, l *zap.Logger) UserDao {
+	// 加 verified 列之前就存在的用户都按已验证处理，否则老用户全部发不了文章
+	grandfather := db.Migrator().HasTable(&User{}) && !db.Migrator().HasColumn(&User{}, "Verified")
 	if err := db.AutoMigrate(&User{}); err != nil {
 		l.Error("自动建表失败", zap.Error(err))
+	} else if grandfather {
+		if err = db.Model(&User{}).Where("verified = ?", false).
+			Update("verified", true).Error; err != nil {
+			l.Error("回填用户验证状态失败", zap.Error(err))
+		}
 	}
 	return &UserDaoImpl{
 		db:     db,
@@ -84,3 +126,245 @@ func (d *UserDaoImpl) FindByPhone(ctx context.Context, phone string) (User, erro
 	err := d.db.WithContext(ctx).Where("phone = ?", phone).First(&u).Error
 	return u, err
 }
+
+func (d *UserDaoImpl) UpdatePassword(ctx context.Context, id int64, password string) error {
+	return d.db.WithContext(ctx).Model(&User{}).
+		Where("`id` = ?", id).
+		Updates(map[string]any{
+			"password":    password,
+			"update_time": time.Now().UnixMilli(),
+		}).Error
+}
+
+func (d *UserDaoImpl) UpdateVerifiedByEmail(ctx context.Context, email string) (User, error) {
+	var u User
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("email = ?", email).First(&u).Error; err != nil {
+			return err
+		}
+		u.Verified = true
+		u.UpdateTime = time.Now().UnixMilli()
+		return tx.Model(&User{}).
+			Where("`id` = ?", u.Id).
+			Updates(map[string]any{
+				"verified":    true,
+				"update_time": u.UpdateTime,
+			}).Error
+	})
+	return u, err
+}
+
+func (d *UserDaoImpl) FindByOAuth2(ctx context.Context, provider string, openId string) (User, error) {
+	column, ok := oauth2Columns[provider]
+	if !ok {
+		return User{}, ErrOAuth2ProviderNotExists
+	}
+	var u User
+	err := d.db.WithContext(ctx).Where(column+" = ?", openId).First(&u).Error
+	return u, err
+}
+
+func (d *UserDaoImpl) UpdateOAuth2(ctx context.Context, id int64, provider string, openId string) error {
+	column, ok := oauth2Columns[provider]
+	if !ok {
+		return ErrOAuth2ProviderNotExists
+	}
+	err := d.db.WithContext(ctx).Model(&User{}).
+		Where("`id` = ?", id).
+		Updates(map[string]any{
+			column:        openId,
+			"update_time": time.Now().UnixMilli(),
+		}).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		const uniqueConflictErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueConflictErrNo {
+			return ErrUserDuplicateOAuth2
+		}
+	}
+	return err
+}
+
+func (d *UserDaoImpl) UpdatePhone(ctx context.Context, id int64, phone string) (User, error) {
+	return d.updateContact(ctx, id, map[string]any{
+		"phone":    phone,
+		"verified": true,
+	}, ErrUserDuplicatePhone)
+}
+
+func (d *UserDaoImpl) UpdateEmail(ctx context.Context, id int64, email string) (User, error) {
+	return d.updateContact(ctx, id, map[string]any{
+		"email":    email,
+		"verified": true,
+	}, ErrUserDuplicateEmail)
+}
+
+// updateContact 更新手机号或邮箱，唯一索引冲突说明已经被其他用户占用
+func (d *UserDaoImpl) updateContact(ctx context.Context, id int64, fields map[string]any, duplicateErr error) (User, error) {
+	var u User
+	fields["update_time"] = time.Now().UnixMilli()
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("`id` = ?", id).Updates(fields).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("`id` = ?", id).First(&u).Error
+	})
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		const uniqueConflictErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueConflictErrNo {
+			return User{}, duplicateErr
+		}
+	}
+	return u, err
+}
+
+func (d *UserDaoImpl) UpdateStatus(ctx context.Context, id int64, status uint8) error {
+	res := d.db.WithContext(ctx).Model(&User{}).
+		Where("`id` = ?", id).
+		Updates(map[string]any{
+			"status":      status,
+			"update_time": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (d *UserDaoImpl) IncrSessionVersion(ctx context.Context, id int64, floor int64) (int64, error) {
+	var u User
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&User{}).Where("`id` = ?", id).
+			Updates(map[string]any{
+				"session_version": gorm.Expr("GREATEST(session_version, ?) + 1", floor),
+				"update_time":     time.Now().UnixMilli(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return tx.Select("session_version").Where("`id` = ?", id).First(&u).Error
+	})
+	return u.SessionVersion, err
+}
+
+func (d *UserDaoImpl) Delete(ctx context.Context, id int64) error {
+	now := time.Now().UnixMilli()
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 用户写的文章，连同别人在这些文章上的点赞和计数一起删掉
+		var articleIds []int64
+		err := tx.Model(&article.Article{}).Where("author_id = ?", id).Pluck("id", &articleIds).Error
+		if err != nil {
+			return err
+		}
+		if len(articleIds) > 0 {
+			if err = tx.Where("id IN ?", articleIds).Delete(&article.Article{}).Error; err != nil {
+				return err
+			}
+			if err = tx.Where("id IN ?", articleIds).Delete(&article.PublishedArticle{}).Error; err != nil {
+				return err
+			}
+			if err = tx.Where("biz = ? AND biz_id IN ?", "article", articleIds).Delete(&UserLikeBiz{}).Error; err != nil {
+				return err
+			}
+			if err = tx.Where("biz = ? AND biz_id IN ?", "article", articleIds).Delete(&Interactive{}).Error; err != nil {
+				return err
+			}
+			// 这些文章下面的评论，评论上的点赞和计数也要一起删，不然就成了孤儿数据
+			var commentIds []int64
+			err = tx.Model(&Comment{}).Where("biz = ? AND biz_id IN ?", "article", articleIds).
+				Pluck("id", &commentIds).Error
+			if err != nil {
+				return err
+			}
+			if len(commentIds) > 0 {
+				if err = tx.Where("biz = ? AND biz_id IN ?", "comment", commentIds).Delete(&UserLikeBiz{}).Error; err != nil {
+					return err
+				}
+				if err = tx.Where("biz = ? AND biz_id IN ?", "comment", commentIds).Delete(&Interactive{}).Error; err != nil {
+					return err
+				}
+				if err = tx.Where("id IN ?", commentIds).Delete(&Comment{}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// 用户在别处发的评论，按删除评论的逻辑处理，回复和评论数一起修正
+		var comments []Comment
+		if err = tx.Where("uid = ?", id).Order("root_id asc").Find(&comments).Error; err != nil {
+			return err
+		}
+		commentDao := &CommentDaoMysql{db: tx, logger: d.logger}
+		for _, c := range comments {
+			if _, err = commentDao.Delete(ctx, c); err != nil {
+				return err
+			}
+		}
+
+		// 用户点过的赞，对应内容的点赞数要扣回来
+		var likes []UserLikeBiz
+		if err = tx.Where("uid = ? AND status = ?", id, 1).Find(&likes).Error; err != nil {
+			return err
+		}
+		for _, like := range likes {
+			err = tx.Model(&Interactive{}).
+				Where("biz = ? AND biz_id = ? AND like_count > 0", like.Biz, like.BizId).
+				Updates(map[string]any{
+					"like_count":  gorm.Expr("like_count - 1"),
+					"update_time": now,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err = tx.Where("uid = ?", id).Delete(&UserLikeBiz{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("uid = ? OR author_id = ?", id, id).Delete(&FeedInbox{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("author_id = ?", id).Delete(&FeedPullAuthor{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("follower = ? OR followee = ?", id, id).Delete(&FollowRelation{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("sender = ? OR receiver = ?", id, id).Delete(&Message{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("uid = ? OR peer = ?", id, id).Delete(&Conversation{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("uid = ? OR target = ?", id, id).Delete(&UserBlock{}).Error; err != nil {
+			return err
+		}
+		var notificationIds []int64
+		if err = tx.Model(&Notification{}).Where("uid = ?", id).Pluck("id", &notificationIds).Error; err != nil {
+			return err
+		}
+		if len(notificationIds) > 0 {
+			if err = tx.Where("notification_id IN ?", notificationIds).Delete(&NotificationActor{}).Error; err != nil {
+				return err
+			}
+			if err = tx.Where("id IN ?", notificationIds).Delete(&Notification{}).Error; err != nil {
+				return err
+			}
+		}
+		// 已经结案的举报和审核记录留作审计，只删未处理的
+		if err = tx.Where("reporter = ? AND status <> ?", id, domain.ReportStatusResolved.ToUint8()).Delete(&Report{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("uid = ?", id).Delete(&UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("`id` = ?", id).Delete(&User{}).Error
+	})
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao/article"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"time"
+)
+
+type FeedRepository interface {
+	// Push 把文章写进粉丝的收件箱
+	Push(ctx context.Context, item domain.FeedItem, uids []int64) error
+	// FindInbox 推模式的文章，只返回 authorIds 里面的作者，
+	// 收件箱还没有读完的时候同时返回这一页最后扫过的位置，否则返回零值
+	FindInbox(ctx context.Context, uid int64, authorIds []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, domain.FeedCursor, error)
+	// FindPublished 拉模式的文章
+	FindPublished(ctx context.Context, authorIds []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error)
+	// FindPublishedById 已发布的文章，下架或者不存在的时候返回 ErrArticleNotFound
+	FindPublishedById(ctx context.Context, id int64) (domain.FeedItem, error)
+	// MarkPull 作者的文章改成读的时候拉
+	MarkPull(ctx context.Context, authorId int64) error
+	// FilterPullAuthors 从 authorIds 里面挑出需要拉的作者
+	FilterPullAuthors(ctx context.Context, authorIds []int64) ([]int64, error)
+}
+
+type FeedRepositoryImpl struct {
+	dao    dao.FeedDao
+	logger *zap.Logger
+}
+
+func NewFeedRepository(d dao.FeedDao, l *zap.Logger) FeedRepository {
+	return &FeedRepositoryImpl{
+		dao:    d,
+		logger: l,
+	}
+}
+
+func (repo *FeedRepositoryImpl) Push(ctx context.Context, item domain.FeedItem, uids []int64) error {
+	return repo.dao.InsertInbox(ctx, slice.Map[int64, dao.FeedInbox](uids, func(idx int, uid int64) dao.FeedInbox {
+		return dao.FeedInbox{
+			Uid:        uid,
+			ArticleId:  item.Article.Id,
+			AuthorId:   item.Article.Author.Id,
+			CreateTime: item.Ctime.UnixMilli(),
+		}
+	}))
+}
+
+// FindInbox 收件箱里只有文章 id，内容从线上库查，被下架的文章直接过滤掉
+func (repo *FeedRepositoryImpl) FindInbox(ctx context.Context, uid int64, authorIds []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, domain.FeedCursor, error) {
+	inboxes, err := repo.dao.FindInbox(ctx, uid, authorIds, cursor.Ctime, cursor.ArticleId, limit)
+	if err != nil {
+		return nil, domain.FeedCursor{}, err
+	}
+	var scanned domain.FeedCursor
+	if len(inboxes) == limit {
+		last := inboxes[len(inboxes)-1]
+		scanned = domain.FeedCursor{Ctime: last.CreateTime, ArticleId: last.ArticleId}
+	}
+	ids := slice.Map[dao.FeedInbox, int64](inboxes, func(idx int, src dao.FeedInbox) int64 {
+		return src.ArticleId
+	})
+	articles, err := repo.dao.FindPublishedByIds(ctx, ids, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return nil, domain.FeedCursor{}, err
+	}
+	articleMap := make(map[int64]article.PublishedArticle, len(articles))
+	for _, a := range articles {
+		articleMap[a.Id] = a
+	}
+	items := make([]domain.FeedItem, 0, len(inboxes))
+	for _, inbox := range inboxes {
+		a, ok := articleMap[inbox.ArticleId]
+		if !ok {
+			continue
+		}
+		items = append(items, repo.toFeedItem(a))
+	}
+	return items, scanned, nil
+}
+
+func (repo *FeedRepositoryImpl) FindPublished(ctx context.Context, authorIds []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error) {
+	articles, err := repo.dao.FindPublished(ctx, authorIds, domain.ArticleStatusPublished.ToUint8(),
+		cursor.Ctime, cursor.ArticleId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[article.PublishedArticle, domain.FeedItem](articles, func(idx int, src article.PublishedArticle) domain.FeedItem {
+		return repo.toFeedItem(src)
+	}), nil
+}
+
+func (repo *FeedRepositoryImpl) FindPublishedById(ctx context.Context, id int64) (domain.FeedItem, error) {
+	articles, err := repo.dao.FindPublishedByIds(ctx, []int64{id}, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return domain.FeedItem{}, err
+	}
+	if len(articles) == 0 {
+		return domain.FeedItem{}, ErrArticleNotFound
+	}
+	return repo.toFeedItem(articles[0]), nil
+}
+
+func (repo *FeedRepositoryImpl) MarkPull(ctx context.Context, authorId int64) error {
+	return repo.dao.InsertPullAuthor(ctx, authorId)
+}
+
+func (repo *FeedRepositoryImpl) FilterPullAuthors(ctx context.Context, authorIds []int64) ([]int64, error) {
+	return repo.dao.FindPullAuthors(ctx, authorIds)
+}
+
+func (repo *FeedRepositoryImpl) toFeedItem(a article.PublishedArticle) domain.FeedItem {
+	src := article.Article(a)
+	return domain.FeedItem{
+		Article: *entity2domain(&src),
+		Ctime:   time.UnixMilli(a.CreateTime),
+	}
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"time"
+)
+
+type FollowRepository interface {
+	// Follow 返回是否新建了关注关系，重复关注返回 false
+	Follow(ctx context.Context, follower, followee int64) (bool, error)
+	Unfollow(ctx context.Context, follower, followee int64) error
+	ListFollowers(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	ListFollowees(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	IsFollowing(ctx context.Context, follower, followee int64) (bool, error)
+	FolloweeIds(ctx context.Context, uid int64, limit int) ([]int64, error)
+	FollowerIds(ctx context.Context, uid int64, afterId int64, limit int) ([]int64, int64, error)
+	FilterPopular(ctx context.Context, uids []int64, threshold int64) ([]int64, error)
+	// ListRelations 用户关注别人和被别人关注的所有关系
+	ListRelations(ctx context.Context, uid int64) ([]domain.FollowRelation, error)
+	// ClearStatics 关注关系批量变化之后删掉相关用户的统计缓存
+	ClearStatics(ctx context.Context, uids ...int64) error
+}
+
+type FollowRepositoryImpl struct {
+	dao    dao.FollowDao
+	cache  cache.FollowCache
+	logger *zap.Logger
+}
+
+func NewFollowRepository(d dao.FollowDao, c cache.FollowCache, l *zap.Logger) FollowRepository {
+	return &FollowRepositoryImpl{
+		dao:    d,
+		cache:  c,
+		logger: l,
+	}
+}
+
+func (repo *FollowRepositoryImpl) Follow(ctx context.Context, follower, followee int64) (bool, error) {
+	changed, err := repo.dao.Follow(ctx, follower, followee)
+	if err != nil || !changed {
+		return false, err
+	}
+	repo.incrStatics(ctx, follower, followee, 1)
+	return true, nil
+}
+
+func (repo *FollowRepositoryImpl) Unfollow(ctx context.Context, follower, followee int64) error {
+	changed, err := repo.dao.Unfollow(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	repo.incrStatics(ctx, follower, followee, -1)
+	return nil
+}
+
+func (repo *FollowRepositoryImpl) ListFollowers(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	relations, err := repo.dao.FindFollowers(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](relations, repo.entity2domain), nil
+}
+
+func (repo *FollowRepositoryImpl) ListFollowees(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	relations, err := repo.dao.FindFollowees(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](relations, repo.entity2domain), nil
+}
+
+// GetStatics 缓存没有的时候从数据库统计，回写缓存失败不影响结果
+func (repo *FollowRepositoryImpl) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	statics, err := repo.cache.GetStatics(ctx, uid)
+	if err == nil {
+		return statics, nil
+	}
+	if !errors.Is(err, cache.ErrKeyNotExist) {
+		repo.logger.Error("查询关注统计缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	statics.Followers, err = repo.dao.CountFollowers(ctx, uid)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	statics.Followees, err = repo.dao.CountFollowees(ctx, uid)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if err = repo.cache.SetStatics(ctx, uid, statics); err != nil {
+		repo.logger.Error("回写关注统计缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	return statics, nil
+}
+
+func (repo *FollowRepositoryImpl) IsFollowing(ctx context.Context, follower, followee int64) (bool, error) {
+	return repo.dao.IsFollowing(ctx, follower, followee)
+}
+
+func (repo *FollowRepositoryImpl) FolloweeIds(ctx context.Context, uid int64, limit int) ([]int64, error) {
+	return repo.dao.FindFolloweeIds(ctx, uid, limit)
+}
+
+func (repo *FollowRepositoryImpl) FollowerIds(ctx context.Context, uid int64, afterId int64, limit int) ([]int64, int64, error) {
+	return repo.dao.FindFollowerIds(ctx, uid, afterId, limit)
+}
+
+func (repo *FollowRepositoryImpl) FilterPopular(ctx context.Context, uids []int64, threshold int64) ([]int64, error) {
+	return repo.dao.FilterPopular(ctx, uids, threshold)
+}
+
+func (repo *FollowRepositoryImpl) ListRelations(ctx context.Context, uid int64) ([]domain.FollowRelation, error) {
+	relations, err := repo.dao.FindRelations(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](relations, repo.entity2domain), nil
+}
+
+func (repo *FollowRepositoryImpl) ClearStatics(ctx context.Context, uids ...int64) error {
+	return repo.cache.DelStatics(ctx, uids...)
+}
+
+// incrStatics 关注关系变化之后更新双方的统计缓存
+func (repo *FollowRepositoryImpl) incrStatics(ctx context.Context, follower, followee int64, delta int64) {
+	if err := repo.cache.IncrFolloweesIfPresent(ctx, follower, delta); err != nil {
+		repo.logger.Error("更新关注数缓存失败", zap.Int64("uid", follower), zap.Error(err))
+	}
+	if err := repo.cache.IncrFollowersIfPresent(ctx, followee, delta); err != nil {
+		repo.logger.Error("更新粉丝数缓存失败", zap.Int64("uid", followee), zap.Error(err))
+	}
+}
+
+func (repo *FollowRepositoryImpl) entity2domain(idx int, src dao.FollowRelation) domain.FollowRelation {
+	return domain.FollowRelation{
+		Follower: src.Follower,
+		Followee: src.Followee,
+		Ctime:    time.UnixMilli(src.CreateTime),
+	}
+}
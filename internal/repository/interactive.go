@@ -2,33 +2,103 @@ package repository
 
 import (
 	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
 	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
 	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"time"
 )
 
 type InteractiveRepository interface {
 	IncreaseReadCount(ctx context.Context, biz string, bizId int64) error
-	IncreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) error
+	// IncreaseLikeCount 点赞，返回是否真的点了赞，重复点赞返回 false
+	IncreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) (bool, error)
+	// DecreaseLikeCount 取消点赞，返回是否真的取消了
+	DecreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) (bool, error)
+	ListLikes(ctx context.Context, uid int64) ([]domain.Like, error)
+	// GetByIds 批量查询计数，没有记录的计数都是 0
+	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error)
 }
 
 type InteractiveRepositoryImpl struct {
-	dao   dao.InteractiveDao
-	cache cache.InteractiveCache
+	dao    dao.InteractiveDao
+	cache  cache.InteractiveCache
+	logger *zap.Logger
 }
 
-func (repo *InteractiveRepositoryImpl) IncreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) error {
-	repo.dao.IncreaseLikeCount(ctx, biz, id, uid)
-	//TODO implement me
-	panic("implement me")
+func (repo *InteractiveRepositoryImpl) IncreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) (bool, error) {
+	changed, err := repo.dao.IncreaseLikeCount(ctx, biz, id, uid)
+	if err != nil || !changed {
+		return false, err
+	}
+	// 数据库已经提交了，缓存更新失败只记日志，不然调用方会以为点赞失败
+	if er := repo.cache.IncrLikeCountIfPresent(ctx, biz, id, 1); er != nil {
+		repo.logger.Error("更新点赞数缓存失败", zap.String("biz", biz), zap.Int64("bizId", id), zap.Error(er))
+	}
+	return true, nil
+}
+
+func (repo *InteractiveRepositoryImpl) DecreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) (bool, error) {
+	changed, err := repo.dao.DeletedLike(ctx, biz, id, uid)
+	if err != nil || !changed {
+		return false, err
+	}
+	if er := repo.cache.IncrLikeCountIfPresent(ctx, biz, id, -1); er != nil {
+		repo.logger.Error("更新点赞数缓存失败", zap.String("biz", biz), zap.Int64("bizId", id), zap.Error(er))
+	}
+	return true, nil
 }
 
 func (repo *InteractiveRepositoryImpl) IncreaseReadCount(ctx context.Context, biz string, bizId int64) error {
 	if err := repo.dao.IncreaseReadCount(ctx, biz, bizId); err != nil {
 		return err
 	}
-	return repo.cache.IncreaseReadCountIfPresent(ctx, biz, bizId)
+	if err := repo.cache.IncreaseReadCountIfPresent(ctx, biz, bizId); err != nil {
+		repo.logger.Error("更新阅读数缓存失败", zap.String("biz", biz), zap.Int64("bizId", bizId), zap.Error(err))
+	}
+	return nil
+}
+
+func (repo *InteractiveRepositoryImpl) ListLikes(ctx context.Context, uid int64) ([]domain.Like, error) {
+	likes, err := repo.dao.ListLikesByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.UserLikeBiz, domain.Like](likes, func(idx int, src dao.UserLikeBiz) domain.Like {
+		return domain.Like{
+			Biz:   src.Biz,
+			BizId: src.BizId,
+			Ctime: time.UnixMilli(src.CreateTime),
+		}
+	}), nil
+}
+
+func (repo *InteractiveRepositoryImpl) GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error) {
+	interactives, err := repo.dao.GetByIds(ctx, biz, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(ids))
+	for _, id := range ids {
+		res[id] = domain.Interactive{Biz: biz, BizId: id}
+	}
+	for _, i := range interactives {
+		res[i.BizId] = domain.Interactive{
+			Biz:          i.Biz,
+			BizId:        i.BizId,
+			ReadCount:    i.ReadCount,
+			LikeCount:    i.LikeCount,
+			CommentCount: i.CommentCount,
+		}
+	}
+	return res, nil
 }
 
-func NewInteractiveRepositoryImpl() *InteractiveRepositoryImpl {
-	return &InteractiveRepositoryImpl{}
+func NewInteractiveRepositoryImpl(dao dao.InteractiveDao, cache cache.InteractiveCache, l *zap.Logger) *InteractiveRepositoryImpl {
+	return &InteractiveRepositoryImpl{
+		dao:    dao,
+		cache:  cache,
+		logger: l,
+	}
 }
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	cachemock "github.com/ChongYanOvO/little-blue-book/internal/repository/cache/mock"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	daomock "github.com/ChongYanOvO/little-blue-book/internal/repository/dao/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestInteractiveRepositoryImpl_IncreaseLikeCount(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(ctl *gomock.Controller) (dao.InteractiveDao, cache.InteractiveCache)
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name: "点赞成功更新缓存",
+			mock: func(ctl *gomock.Controller) (dao.InteractiveDao, cache.InteractiveCache) {
+				d := daomock.NewMockInteractiveDao(ctl)
+				d.EXPECT().IncreaseLikeCount(gomock.Any(), "article", int64(1), int64(2)).Return(true, nil)
+				c := cachemock.NewMockInteractiveCache(ctl)
+				c.EXPECT().IncrLikeCountIfPresent(gomock.Any(), "article", int64(1), int64(1)).Return(nil)
+				return d, c
+			},
+			wantChanged: true,
+		},
+		{
+			name: "数据库已经提交，缓存失败不算点赞失败",
+			mock: func(ctl *gomock.Controller) (dao.InteractiveDao, cache.InteractiveCache) {
+				d := daomock.NewMockInteractiveDao(ctl)
+				d.EXPECT().IncreaseLikeCount(gomock.Any(), "article", int64(1), int64(2)).Return(true, nil)
+				c := cachemock.NewMockInteractiveCache(ctl)
+				c.EXPECT().IncrLikeCountIfPresent(gomock.Any(), "article", int64(1), int64(1)).Return(errors.New("redis 错误"))
+				return d, c
+			},
+			wantChanged: true,
+		},
+		{
+			name: "重复点赞不动缓存",
+			mock: func(ctl *gomock.Controller) (dao.InteractiveDao, cache.InteractiveCache) {
+				d := daomock.NewMockInteractiveDao(ctl)
+				d.EXPECT().IncreaseLikeCount(gomock.Any(), "article", int64(1), int64(2)).Return(false, nil)
+				return d, cachemock.NewMockInteractiveCache(ctl)
+			},
+		},
+		{
+			name: "数据库失败",
+			mock: func(ctl *gomock.Controller) (dao.InteractiveDao, cache.InteractiveCache) {
+				d := daomock.NewMockInteractiveDao(ctl)
+				d.EXPECT().IncreaseLikeCount(gomock.Any(), "article", int64(1), int64(2)).Return(false, errors.New("数据库错误"))
+				return d, cachemock.NewMockInteractiveCache(ctl)
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			d, c := tc.mock(ctl)
+			repo := NewInteractiveRepositoryImpl(d, c, zap.NewNop())
+			changed, err := repo.IncreaseLikeCount(context.Background(), "article", 1, 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantChanged, changed)
+		})
+	}
+}
+
+func TestInteractiveRepositoryImpl_DecreaseLikeCount(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	d := daomock.NewMockInteractiveDao(ctl)
+	d.EXPECT().DeletedLike(gomock.Any(), "comment", int64(1), int64(2)).Return(true, nil)
+	c := cachemock.NewMockInteractiveCache(ctl)
+	c.EXPECT().IncrLikeCountIfPresent(gomock.Any(), "comment", int64(1), int64(-1)).Return(errors.New("redis 错误"))
+	repo := NewInteractiveRepositoryImpl(d, c, zap.NewNop())
+	changed, err := repo.DecreaseLikeCount(context.Background(), "comment", 1, 2)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"go.uber.org/zap"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	LockTTL(ctx context.Context, key string) (time.Duration, error)
+	IncrFail(ctx context.Context, key string, policy cache.LoginLockPolicy) (time.Duration, error)
+	Reset(ctx context.Context, key string) error
+}
+
+type LoginAttemptRepositoryImpl struct {
+	cache  cache.LoginAttemptCache
+	logger *zap.Logger
+}
+
+func NewLoginAttemptRepository(c cache.LoginAttemptCache, l *zap.Logger) LoginAttemptRepository {
+	return &LoginAttemptRepositoryImpl{
+		cache:  c,
+		logger: l,
+	}
+}
+
+func (repo *LoginAttemptRepositoryImpl) LockTTL(ctx context.Context, key string) (time.Duration, error) {
+	return repo.cache.LockTTL(ctx, key)
+}
+
+func (repo *LoginAttemptRepositoryImpl) IncrFail(ctx context.Context, key string, policy cache.LoginLockPolicy) (time.Duration, error) {
+	return repo.cache.IncrFail(ctx, key, policy)
+}
+
+func (repo *LoginAttemptRepositoryImpl) Reset(ctx context.Context, key string) error {
+	return repo.cache.Reset(ctx, key)
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"time"
+)
+
+type MessageRepository interface {
+	Create(ctx context.Context, m domain.Message) (int64, error)
+	ListMessages(ctx context.Context, uid, peer int64, maxId int64, limit int) ([]domain.Message, error)
+	// ListConversations 带上每个会话的最后一条私信
+	ListConversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error)
+	MarkRead(ctx context.Context, uid, peer int64) error
+	// ListByUser 用户发出和收到的所有私信
+	ListByUser(ctx context.Context, uid int64) ([]domain.Message, error)
+}
+
+type MessageRepositoryImpl struct {
+	dao    dao.MessageDao
+	logger *zap.Logger
+}
+
+func NewMessageRepository(d dao.MessageDao, l *zap.Logger) MessageRepository {
+	return &MessageRepositoryImpl{
+		dao:    d,
+		logger: l,
+	}
+}
+
+func (repo *MessageRepositoryImpl) Create(ctx context.Context, m domain.Message) (int64, error) {
+	return repo.dao.Insert(ctx, dao.Message{
+		Sender:   m.Sender,
+		Receiver: m.Receiver,
+		Content:  m.Content,
+	})
+}
+
+func (repo *MessageRepositoryImpl) ListMessages(ctx context.Context, uid, peer int64, maxId int64, limit int) ([]domain.Message, error) {
+	messages, err := repo.dao.FindMessages(ctx, uid, peer, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Message, domain.Message](messages, repo.entity2domain), nil
+}
+
+func (repo *MessageRepositoryImpl) ListConversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error) {
+	conversations, err := repo.dao.FindConversations(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := slice.Map[dao.Conversation, int64](conversations, func(idx int, src dao.Conversation) int64 {
+		return src.LastMsgId
+	})
+	messages, err := repo.dao.FindMessagesByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	lastMessages := make(map[int64]domain.Message, len(messages))
+	for i, m := range messages {
+		lastMessages[m.Id] = repo.entity2domain(i, m)
+	}
+	return slice.Map[dao.Conversation, domain.Conversation](conversations, func(idx int, src dao.Conversation) domain.Conversation {
+		return domain.Conversation{
+			Uid:         src.Uid,
+			Peer:        src.Peer,
+			LastMessage: lastMessages[src.LastMsgId],
+			Unread:      src.Unread,
+			Utime:       time.UnixMilli(src.UpdateTime),
+		}
+	}), nil
+}
+
+func (repo *MessageRepositoryImpl) MarkRead(ctx context.Context, uid, peer int64) error {
+	return repo.dao.ClearUnread(ctx, uid, peer)
+}
+
+func (repo *MessageRepositoryImpl) ListByUser(ctx context.Context, uid int64) ([]domain.Message, error) {
+	messages, err := repo.dao.FindByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Message, domain.Message](messages, repo.entity2domain), nil
+}
+
+func (repo *MessageRepositoryImpl) entity2domain(idx int, src dao.Message) domain.Message {
+	return domain.Message{
+		Id:       src.Id,
+		Sender:   src.Sender,
+		Receiver: src.Receiver,
+		Content:  src.Content,
+		Ctime:    time.UnixMilli(src.CreateTime),
+	}
+}
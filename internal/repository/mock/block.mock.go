@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/block.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/block.go -package=mock -destination=internal/repository/mock/block.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/ChongYanOvO/little-blue-book/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBlockRepository is a mock of BlockRepository interface.
+type MockBlockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockRepositoryMockRecorder
+}
+
+// MockBlockRepositoryMockRecorder is the mock recorder for MockBlockRepository.
+type MockBlockRepositoryMockRecorder struct {
+	mock *MockBlockRepository
+}
+
+// NewMockBlockRepository creates a new mock instance.
+func NewMockBlockRepository(ctrl *gomock.Controller) *MockBlockRepository {
+	mock := &MockBlockRepository{ctrl: ctrl}
+	mock.recorder = &MockBlockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlockRepository) EXPECT() *MockBlockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockBlockRepository) Add(ctx context.Context, uid, target int64, typ domain.BlockType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, uid, target, typ)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockBlockRepositoryMockRecorder) Add(ctx, uid, target, typ any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockBlockRepository)(nil).Add), ctx, uid, target, typ)
+}
+
+// IsBlocked mocks base method.
+func (m *MockBlockRepository) IsBlocked(ctx context.Context, uid, target int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlocked", ctx, uid, target)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlocked indicates an expected call of IsBlocked.
+func (mr *MockBlockRepositoryMockRecorder) IsBlocked(ctx, uid, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockBlockRepository)(nil).IsBlocked), ctx, uid, target)
+}
+
+// List mocks base method.
+func (m *MockBlockRepository) List(ctx context.Context, uid int64, typ domain.BlockType, offset, limit int) ([]domain.BlockRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, typ, offset, limit)
+	ret0, _ := ret[0].([]domain.BlockRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockBlockRepositoryMockRecorder) List(ctx, uid, typ, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockBlockRepository)(nil).List), ctx, uid, typ, offset, limit)
+}
+
+// Relations mocks base method.
+func (m *MockBlockRepository) Relations(ctx context.Context, uid int64) (map[int64]domain.BlockType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Relations", ctx, uid)
+	ret0, _ := ret[0].(map[int64]domain.BlockType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Relations indicates an expected call of Relations.
+func (mr *MockBlockRepositoryMockRecorder) Relations(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Relations", reflect.TypeOf((*MockBlockRepository)(nil).Relations), ctx, uid)
+}
+
+// Remove mocks base method.
+func (m *MockBlockRepository) Remove(ctx context.Context, uid, target int64, typ domain.BlockType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, uid, target, typ)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockBlockRepositoryMockRecorder) Remove(ctx, uid, target, typ any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockBlockRepository)(nil).Remove), ctx, uid, target, typ)
+}
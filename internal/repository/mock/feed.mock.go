@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/feed.go -package=mock -destination=internal/repository/mock/feed.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/ChongYanOvO/little-blue-book/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedRepository is a mock of FeedRepository interface.
+type MockFeedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedRepositoryMockRecorder
+}
+
+// MockFeedRepositoryMockRecorder is the mock recorder for MockFeedRepository.
+type MockFeedRepositoryMockRecorder struct {
+	mock *MockFeedRepository
+}
+
+// NewMockFeedRepository creates a new mock instance.
+func NewMockFeedRepository(ctrl *gomock.Controller) *MockFeedRepository {
+	mock := &MockFeedRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedRepository) EXPECT() *MockFeedRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FilterPullAuthors mocks base method.
+func (m *MockFeedRepository) FilterPullAuthors(ctx context.Context, authorIds []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterPullAuthors", ctx, authorIds)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterPullAuthors indicates an expected call of FilterPullAuthors.
+func (mr *MockFeedRepositoryMockRecorder) FilterPullAuthors(ctx, authorIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterPullAuthors", reflect.TypeOf((*MockFeedRepository)(nil).FilterPullAuthors), ctx, authorIds)
+}
+
+// FindInbox mocks base method.
+func (m *MockFeedRepository) FindInbox(ctx context.Context, uid int64, authorIds []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, domain.FeedCursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInbox", ctx, uid, authorIds, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(domain.FeedCursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindInbox indicates an expected call of FindInbox.
+func (mr *MockFeedRepositoryMockRecorder) FindInbox(ctx, uid, authorIds, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInbox", reflect.TypeOf((*MockFeedRepository)(nil).FindInbox), ctx, uid, authorIds, cursor, limit)
+}
+
+// FindPublished mocks base method.
+func (m *MockFeedRepository) FindPublished(ctx context.Context, authorIds []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPublished", ctx, authorIds, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPublished indicates an expected call of FindPublished.
+func (mr *MockFeedRepositoryMockRecorder) FindPublished(ctx, authorIds, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPublished", reflect.TypeOf((*MockFeedRepository)(nil).FindPublished), ctx, authorIds, cursor, limit)
+}
+
+// FindPublishedById mocks base method.
+func (m *MockFeedRepository) FindPublishedById(ctx context.Context, id int64) (domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPublishedById", ctx, id)
+	ret0, _ := ret[0].(domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPublishedById indicates an expected call of FindPublishedById.
+func (mr *MockFeedRepositoryMockRecorder) FindPublishedById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPublishedById", reflect.TypeOf((*MockFeedRepository)(nil).FindPublishedById), ctx, id)
+}
+
+// MarkPull mocks base method.
+func (m *MockFeedRepository) MarkPull(ctx context.Context, authorId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPull", ctx, authorId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPull indicates an expected call of MarkPull.
+func (mr *MockFeedRepositoryMockRecorder) MarkPull(ctx, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPull", reflect.TypeOf((*MockFeedRepository)(nil).MarkPull), ctx, authorId)
+}
+
+// Push mocks base method.
+func (m *MockFeedRepository) Push(ctx context.Context, item domain.FeedItem, uids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", ctx, item, uids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockFeedRepositoryMockRecorder) Push(ctx, item, uids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockFeedRepository)(nil).Push), ctx, item, uids)
+}
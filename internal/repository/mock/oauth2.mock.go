@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/oauth2.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/oauth2.go -package=mock -destination=internal/repository/mock/oauth2.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuth2StateRepository is a mock of OAuth2StateRepository interface.
+type MockOAuth2StateRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuth2StateRepositoryMockRecorder
+}
+
+// MockOAuth2StateRepositoryMockRecorder is the mock recorder for MockOAuth2StateRepository.
+type MockOAuth2StateRepositoryMockRecorder struct {
+	mock *MockOAuth2StateRepository
+}
+
+// NewMockOAuth2StateRepository creates a new mock instance.
+func NewMockOAuth2StateRepository(ctrl *gomock.Controller) *MockOAuth2StateRepository {
+	mock := &MockOAuth2StateRepository{ctrl: ctrl}
+	mock.recorder = &MockOAuth2StateRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuth2StateRepository) EXPECT() *MockOAuth2StateRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockOAuth2StateRepository) Consume(ctx context.Context, state string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, state)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockOAuth2StateRepositoryMockRecorder) Consume(ctx, state any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockOAuth2StateRepository)(nil).Consume), ctx, state)
+}
+
+// Store mocks base method.
+func (m *MockOAuth2StateRepository) Store(ctx context.Context, state, binding string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, state, binding)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockOAuth2StateRepositoryMockRecorder) Store(ctx, state, binding any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockOAuth2StateRepository)(nil).Store), ctx, state, binding)
+}
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/sms.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/sms.go -package=mock -destination=internal/repository/mock/sms.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/ChongYanOvO/little-blue-book/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSmsTaskRepository is a mock of SmsTaskRepository interface.
+type MockSmsTaskRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmsTaskRepositoryMockRecorder
+}
+
+// MockSmsTaskRepositoryMockRecorder is the mock recorder for MockSmsTaskRepository.
+type MockSmsTaskRepositoryMockRecorder struct {
+	mock *MockSmsTaskRepository
+}
+
+// NewMockSmsTaskRepository creates a new mock instance.
+func NewMockSmsTaskRepository(ctrl *gomock.Controller) *MockSmsTaskRepository {
+	mock := &MockSmsTaskRepository{ctrl: ctrl}
+	mock.recorder = &MockSmsTaskRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSmsTaskRepository) EXPECT() *MockSmsTaskRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSmsTaskRepository) Create(ctx context.Context, t domain.SmsTask) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, t)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSmsTaskRepositoryMockRecorder) Create(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSmsTaskRepository)(nil).Create), ctx, t)
+}
+
+// MarkFailed mocks base method.
+func (m *MockSmsTaskRepository) MarkFailed(ctx context.Context, t domain.SmsTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockSmsTaskRepositoryMockRecorder) MarkFailed(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockSmsTaskRepository)(nil).MarkFailed), ctx, t)
+}
+
+// MarkSuccess mocks base method.
+func (m *MockSmsTaskRepository) MarkSuccess(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSuccess", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSuccess indicates an expected call of MarkSuccess.
+func (mr *MockSmsTaskRepositoryMockRecorder) MarkSuccess(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSuccess", reflect.TypeOf((*MockSmsTaskRepository)(nil).MarkSuccess), ctx, id)
+}
+
+// Preempt mocks base method.
+func (m *MockSmsTaskRepository) Preempt(ctx context.Context, staleBefore time.Time, limit int) ([]domain.SmsTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preempt", ctx, staleBefore, limit)
+	ret0, _ := ret[0].([]domain.SmsTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preempt indicates an expected call of Preempt.
+func (mr *MockSmsTaskRepositoryMockRecorder) Preempt(ctx, staleBefore, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preempt", reflect.TypeOf((*MockSmsTaskRepository)(nil).Preempt), ctx, staleBefore, limit)
+}
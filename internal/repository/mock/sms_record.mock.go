@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/sms_record.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/sms_record.go -package=mock -destination=internal/repository/mock/sms_record.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/ChongYanOvO/little-blue-book/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSmsRecordRepository is a mock of SmsRecordRepository interface.
+type MockSmsRecordRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmsRecordRepositoryMockRecorder
+}
+
+// MockSmsRecordRepositoryMockRecorder is the mock recorder for MockSmsRecordRepository.
+type MockSmsRecordRepositoryMockRecorder struct {
+	mock *MockSmsRecordRepository
+}
+
+// NewMockSmsRecordRepository creates a new mock instance.
+func NewMockSmsRecordRepository(ctrl *gomock.Controller) *MockSmsRecordRepository {
+	mock := &MockSmsRecordRepository{ctrl: ctrl}
+	mock.recorder = &MockSmsRecordRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSmsRecordRepository) EXPECT() *MockSmsRecordRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSmsRecordRepository) Create(ctx context.Context, records []domain.SmsRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSmsRecordRepositoryMockRecorder) Create(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSmsRecordRepository)(nil).Create), ctx, records)
+}
+
+// FindByPhoneHash mocks base method.
+func (m *MockSmsRecordRepository) FindByPhoneHash(ctx context.Context, phoneHash string, offset, limit int) ([]domain.SmsRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPhoneHash", ctx, phoneHash, offset, limit)
+	ret0, _ := ret[0].([]domain.SmsRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPhoneHash indicates an expected call of FindByPhoneHash.
+func (mr *MockSmsRecordRepositoryMockRecorder) FindByPhoneHash(ctx, phoneHash, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhoneHash", reflect.TypeOf((*MockSmsRecordRepository)(nil).FindByPhoneHash), ctx, phoneHash, offset, limit)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockSmsRecordRepository) UpdateDelivery(ctx context.Context, r domain.SmsRecord) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, r)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockSmsRecordRepositoryMockRecorder) UpdateDelivery(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockSmsRecordRepository)(nil).UpdateDelivery), ctx, r)
+}
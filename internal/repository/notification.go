@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"time"
+)
+
+type NotificationRepository interface {
+	// Create 新建或者合并到未读的同类通知里
+	Create(ctx context.Context, n domain.Notification) error
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error)
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	MarkAllRead(ctx context.Context, uid int64) error
+}
+
+type NotificationRepositoryImpl struct {
+	dao    dao.NotificationDao
+	cache  cache.NotificationCache
+	logger *zap.Logger
+}
+
+func NewNotificationRepository(d dao.NotificationDao, c cache.NotificationCache, l *zap.Logger) NotificationRepository {
+	return &NotificationRepositoryImpl{
+		dao:    d,
+		cache:  c,
+		logger: l,
+	}
+}
+
+func (repo *NotificationRepositoryImpl) Create(ctx context.Context, n domain.Notification) error {
+	created, err := repo.dao.Upsert(ctx, dao.Notification{
+		Uid:       n.Uid,
+		Type:      n.Type.ToUint8(),
+		Biz:       n.Biz,
+		BizId:     n.BizId,
+		LastActor: n.LastActor,
+	})
+	if err != nil || !created {
+		return err
+	}
+	repo.incrUnread(ctx, n.Uid, 1)
+	return nil
+}
+
+func (repo *NotificationRepositoryImpl) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error) {
+	notifications, err := repo.dao.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Notification, domain.Notification](notifications, repo.entity2domain), nil
+}
+
+// UnreadCount 缓存没有的时候从数据库统计，回写缓存失败不影响结果
+func (repo *NotificationRepositoryImpl) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	cnt, err := repo.cache.GetUnread(ctx, uid)
+	if err == nil {
+		return cnt, nil
+	}
+	if !errors.Is(err, cache.ErrKeyNotExist) {
+		repo.logger.Error("查询未读通知数缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	cnt, err = repo.dao.CountUnread(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	if err = repo.cache.SetUnread(ctx, uid, cnt); err != nil {
+		repo.logger.Error("回写未读通知数缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	return cnt, nil
+}
+
+func (repo *NotificationRepositoryImpl) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	affected, err := repo.dao.MarkRead(ctx, uid, ids)
+	if err != nil || affected == 0 {
+		return err
+	}
+	repo.incrUnread(ctx, uid, -affected)
+	return nil
+}
+
+func (repo *NotificationRepositoryImpl) MarkAllRead(ctx context.Context, uid int64) error {
+	if err := repo.dao.MarkAllRead(ctx, uid); err != nil {
+		return err
+	}
+	if err := repo.cache.SetUnread(ctx, uid, 0); err != nil {
+		repo.logger.Error("更新未读通知数缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	return nil
+}
+
+func (repo *NotificationRepositoryImpl) incrUnread(ctx context.Context, uid int64, delta int64) {
+	if err := repo.cache.IncrUnreadIfPresent(ctx, uid, delta); err != nil {
+		repo.logger.Error("更新未读通知数缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+}
+
+func (repo *NotificationRepositoryImpl) entity2domain(idx int, src dao.Notification) domain.Notification {
+	return domain.Notification{
+		Id:         src.Id,
+		Uid:        src.Uid,
+		Type:       domain.NotificationType(src.Type),
+		Biz:        src.Biz,
+		BizId:      src.BizId,
+		LastActor:  src.LastActor,
+		ActorCount: src.ActorCount,
+		Read:       src.Status == 1,
+		Ctime:      time.UnixMilli(src.CreateTime),
+		Utime:      time.UnixMilli(src.UpdateTime),
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"go.uber.org/zap"
+)
+
+var ErrOAuth2StateNotExist = cache.ErrOAuth2StateNotExist
+
+type OAuth2StateRepository interface {
+	// Store 保存 state 和它绑定的提供方、浏览器，Consume 的时候原样返回
+	Store(ctx context.Context, state string, binding string) error
+	Consume(ctx context.Context, state string) (string, error)
+}
+
+type OAuth2StateRepositoryImpl struct {
+	cache  cache.OAuth2StateCache
+	logger *zap.Logger
+}
+
+func NewOAuth2StateRepository(c cache.OAuth2StateCache, l *zap.Logger) OAuth2StateRepository {
+	return &OAuth2StateRepositoryImpl{
+		cache:  c,
+		logger: l,
+	}
+}
+
+func (repo *OAuth2StateRepositoryImpl) Store(ctx context.Context, state string, binding string) error {
+	return repo.cache.Set(ctx, state, binding)
+}
+
+func (repo *OAuth2StateRepositoryImpl) Consume(ctx context.Context, state string) (string, error) {
+	return repo.cache.Consume(ctx, state)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+)
+
+type PushRepository interface {
+	// Publish 广播给所有实例
+	Publish(ctx context.Context, evt domain.PushEvent) error
+	Subscribe(ctx context.Context) (<-chan domain.PushEvent, error)
+}
+
+type PushRepositoryImpl struct {
+	cache cache.PushCache
+}
+
+func NewPushRepository(c cache.PushCache) PushRepository {
+	return &PushRepositoryImpl{
+		cache: c,
+	}
+}
+
+func (repo *PushRepositoryImpl) Publish(ctx context.Context, evt domain.PushEvent) error {
+	return repo.cache.Publish(ctx, evt)
+}
+
+func (repo *PushRepositoryImpl) Subscribe(ctx context.Context) (<-chan domain.PushEvent, error) {
+	return repo.cache.Subscribe(ctx)
+}
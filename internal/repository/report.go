@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrReportNotFound  = errors.New("举报不存在")
+	ErrReportDuplicate = dao.ErrReportDuplicate
+)
+
+type ReportRepository interface {
+	Create(ctx context.Context, r domain.Report) (int64, error)
+	FindById(ctx context.Context, id int64) (domain.Report, error)
+	List(ctx context.Context, status domain.ReportStatus, offset, limit int) ([]domain.Report, error)
+	// Claim 返回是否认领成功，已经被别人认领或者已经结案的返回 false
+	Claim(ctx context.Context, id, moderator int64, expireBefore time.Time) (bool, error)
+	// Resolve 返回是否结案成功，不是自己认领的或者认领时间早于 claimedAfter 的返回 false
+	Resolve(ctx context.Context, id, moderator int64, action domain.ReportAction, remark string,
+		claimedAfter time.Time) (bool, error)
+	Logs(ctx context.Context, reportId int64, offset, limit int) ([]domain.ModerationLog, error)
+}
+
+type ReportRepositoryImpl struct {
+	dao    dao.ReportDao
+	logger *zap.Logger
+}
+
+func NewReportRepository(d dao.ReportDao, l *zap.Logger) ReportRepository {
+	return &ReportRepositoryImpl{
+		dao:    d,
+		logger: l,
+	}
+}
+
+func (repo *ReportRepositoryImpl) Create(ctx context.Context, r domain.Report) (int64, error) {
+	return repo.dao.Insert(ctx, dao.Report{
+		Reporter: r.Reporter,
+		Biz:      r.Biz,
+		BizId:    r.BizId,
+		Reason:   r.Reason,
+	})
+}
+
+func (repo *ReportRepositoryImpl) FindById(ctx context.Context, id int64) (domain.Report, error) {
+	r, err := repo.dao.FindById(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.Report{}, ErrReportNotFound
+	}
+	if err != nil {
+		return domain.Report{}, err
+	}
+	return repo.entity2domain(r), nil
+}
+
+func (repo *ReportRepositoryImpl) List(ctx context.Context, status domain.ReportStatus, offset, limit int) ([]domain.Report, error) {
+	reports, err := repo.dao.FindByStatus(ctx, status.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Report, domain.Report](reports, func(idx int, src dao.Report) domain.Report {
+		return repo.entity2domain(src)
+	}), nil
+}
+
+func (repo *ReportRepositoryImpl) Claim(ctx context.Context, id, moderator int64, expireBefore time.Time) (bool, error) {
+	return repo.dao.Claim(ctx, id, moderator, expireBefore.UnixMilli())
+}
+
+func (repo *ReportRepositoryImpl) Resolve(ctx context.Context, id, moderator int64, action domain.ReportAction, remark string,
+	claimedAfter time.Time) (bool, error) {
+	return repo.dao.Resolve(ctx, id, moderator, action.ToUint8(), remark, claimedAfter.UnixMilli())
+}
+
+func (repo *ReportRepositoryImpl) Logs(ctx context.Context, reportId int64, offset, limit int) ([]domain.ModerationLog, error) {
+	logs, err := repo.dao.FindLogs(ctx, reportId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.ModerationLog, domain.ModerationLog](logs, func(idx int, src dao.ModerationLog) domain.ModerationLog {
+		return domain.ModerationLog{
+			Id:        src.Id,
+			Moderator: src.Moderator,
+			ReportId:  src.ReportId,
+			Biz:       src.Biz,
+			BizId:     src.BizId,
+			Operation: domain.ModerationOperation(src.Operation),
+			Action:    domain.ReportAction(src.Action),
+			Remark:    src.Remark,
+			Ctime:     time.UnixMilli(src.CreateTime),
+		}
+	}), nil
+}
+
+func (repo *ReportRepositoryImpl) entity2domain(r dao.Report) domain.Report {
+	return domain.Report{
+		Id:        r.Id,
+		Reporter:  r.Reporter,
+		Biz:       r.Biz,
+		BizId:     r.BizId,
+		Reason:    r.Reason,
+		Status:    domain.ReportStatus(r.Status),
+		Moderator: r.Moderator,
+		Action:    domain.ReportAction(r.Action),
+		Ctime:     time.UnixMilli(r.CreateTime),
+		Utime:     time.UnixMilli(r.UpdateTime),
+	}
+}
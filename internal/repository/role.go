@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+)
+
+type RoleRepository interface {
+	FindByUid(ctx context.Context, uid int64) ([]string, error)
+	Grant(ctx context.Context, uid int64, role string) error
+	Revoke(ctx context.Context, uid int64, role string) error
+}
+
+type RoleRepositoryImpl struct {
+	dao    dao.RoleDao
+	logger *zap.Logger
+}
+
+func NewRoleRepository(d dao.RoleDao, l *zap.Logger) RoleRepository {
+	return &RoleRepositoryImpl{
+		dao:    d,
+		logger: l,
+	}
+}
+
+func (repo *RoleRepositoryImpl) FindByUid(ctx context.Context, uid int64) ([]string, error) {
+	roles, err := repo.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.UserRole, string](roles, func(idx int, src dao.UserRole) string {
+		return src.Role
+	}), nil
+}
+
+func (repo *RoleRepositoryImpl) Grant(ctx context.Context, uid int64, role string) error {
+	return repo.dao.Insert(ctx, uid, role)
+}
+
+func (repo *RoleRepositoryImpl) Revoke(ctx context.Context, uid int64, role string) error {
+	return repo.dao.Delete(ctx, uid, role)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"go.uber.org/zap"
+)
+
+type SessionRepository interface {
+	Version(ctx context.Context, uid int64) (int64, error)
+	Revoke(ctx context.Context, uid int64) (int64, error)
+}
+
+type SessionRepositoryImpl struct {
+	dao    dao.UserDao
+	cache  cache.SessionCache
+	logger *zap.Logger
+}
+
+func NewSessionRepository(d dao.UserDao, c cache.SessionCache, l *zap.Logger) SessionRepository {
+	return &SessionRepositoryImpl{
+		dao:    d,
+		cache:  c,
+		logger: l,
+	}
+}
+
+// Version 每个登录请求都会调用，优先读缓存，缓存没有的时候从数据库恢复
+func (repo *SessionRepositoryImpl) Version(ctx context.Context, uid int64) (int64, error) {
+	version, err := repo.cache.GetVersion(ctx, uid)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, cache.ErrKeyNotExist) {
+		return 0, err
+	}
+	u, err := repo.dao.FindById(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	if err = repo.cache.SetVersion(ctx, uid, u.SessionVersion); err != nil {
+		repo.logger.Error("回写会话版本缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	return u.SessionVersion, nil
+}
+
+// Revoke 先改数据库再更新缓存，缓存更新失败的时候旧 token 最多在缓存过期之前还能用
+func (repo *SessionRepositoryImpl) Revoke(ctx context.Context, uid int64) (int64, error) {
+	// 缓存里的版本可能比数据库的大，比如数据库没有这个版本只有 Redis 里有，token 里带的也可能是缓存的值，
+	// 所以用 GREATEST 取两边较大的再加一，新版本要比任何 token 里可能带的版本都大
+	cached, err := repo.cache.GetVersion(ctx, uid)
+	if err != nil && !errors.Is(err, cache.ErrKeyNotExist) {
+		return 0, err
+	}
+	version, err := repo.dao.IncrSessionVersion(ctx, uid, cached)
+	if errors.Is(err, dao.ErrUserNotFound) {
+		// 用户已经没了，缓存里的版本不能留着，否则旧 token 在缓存过期之前还能通过校验
+		if er := repo.cache.DelVersion(ctx, uid); er != nil {
+			return 0, er
+		}
+		return 0, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err = repo.cache.SetVersion(ctx, uid, version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
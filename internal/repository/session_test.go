@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	cachemock "github.com/ChongYanOvO/little-blue-book/internal/repository/cache/mock"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	daomock "github.com/ChongYanOvO/little-blue-book/internal/repository/dao/mock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestSessionRepositoryImpl_Version(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache)
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name: "缓存命中不查数据库",
+			mock: func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache) {
+				sc := cachemock.NewMockSessionCache(ctl)
+				sc.EXPECT().GetVersion(gomock.Any(), int64(1)).Return(int64(3), nil)
+				return daomock.NewMockUserDao(ctl), sc
+			},
+			wantVersion: 3,
+		},
+		{
+			name: "缓存丢失从数据库恢复",
+			mock: func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache) {
+				sc := cachemock.NewMockSessionCache(ctl)
+				sc.EXPECT().GetVersion(gomock.Any(), int64(1)).Return(int64(0), cache.ErrKeyNotExist)
+				sc.EXPECT().SetVersion(gomock.Any(), int64(1), int64(3)).Return(nil)
+				ud := daomock.NewMockUserDao(ctl)
+				ud.EXPECT().FindById(gomock.Any(), int64(1)).Return(dao.User{Id: 1, SessionVersion: 3}, nil)
+				return ud, sc
+			},
+			wantVersion: 3,
+		},
+		{
+			name: "缓存出错",
+			mock: func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache) {
+				sc := cachemock.NewMockSessionCache(ctl)
+				sc.EXPECT().GetVersion(gomock.Any(), int64(1)).Return(int64(0), errors.New("redis 错误"))
+				return daomock.NewMockUserDao(ctl), sc
+			},
+			wantErr: errors.New("redis 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			ud, sc := tc.mock(ctl)
+			repo := NewSessionRepository(ud, sc, zap.NewNop())
+			version, err := repo.Version(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVersion, version)
+		})
+	}
+}
+
+func TestSessionRepositoryImpl_Revoke(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache)
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name: "新版本不小于缓存里的版本",
+			mock: func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache) {
+				sc := cachemock.NewMockSessionCache(ctl)
+				sc.EXPECT().GetVersion(gomock.Any(), int64(1)).Return(int64(5), nil)
+				sc.EXPECT().SetVersion(gomock.Any(), int64(1), int64(6)).Return(nil)
+				ud := daomock.NewMockUserDao(ctl)
+				ud.EXPECT().IncrSessionVersion(gomock.Any(), int64(1), int64(5)).Return(int64(6), nil)
+				return ud, sc
+			},
+			wantVersion: 6,
+		},
+		{
+			name: "缓存没有的时候以数据库为准",
+			mock: func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache) {
+				sc := cachemock.NewMockSessionCache(ctl)
+				sc.EXPECT().GetVersion(gomock.Any(), int64(1)).Return(int64(0), cache.ErrKeyNotExist)
+				sc.EXPECT().SetVersion(gomock.Any(), int64(1), int64(2)).Return(nil)
+				ud := daomock.NewMockUserDao(ctl)
+				ud.EXPECT().IncrSessionVersion(gomock.Any(), int64(1), int64(0)).Return(int64(2), nil)
+				return ud, sc
+			},
+			wantVersion: 2,
+		},
+		{
+			name: "更新缓存失败",
+			mock: func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache) {
+				sc := cachemock.NewMockSessionCache(ctl)
+				sc.EXPECT().GetVersion(gomock.Any(), int64(1)).Return(int64(1), nil)
+				sc.EXPECT().SetVersion(gomock.Any(), int64(1), int64(2)).Return(errors.New("redis 错误"))
+				ud := daomock.NewMockUserDao(ctl)
+				ud.EXPECT().IncrSessionVersion(gomock.Any(), int64(1), int64(1)).Return(int64(2), nil)
+				return ud, sc
+			},
+			wantErr: errors.New("redis 错误"),
+		},
+		{
+			name: "用户已经删掉，删除缓存里的版本",
+			mock: func(ctl *gomock.Controller) (dao.UserDao, cache.SessionCache) {
+				sc := cachemock.NewMockSessionCache(ctl)
+				sc.EXPECT().GetVersion(gomock.Any(), int64(1)).Return(int64(1), nil)
+				sc.EXPECT().DelVersion(gomock.Any(), int64(1)).Return(nil)
+				ud := daomock.NewMockUserDao(ctl)
+				ud.EXPECT().IncrSessionVersion(gomock.Any(), int64(1), int64(1)).Return(int64(0), dao.ErrUserNotFound)
+				return ud, sc
+			},
+			wantErr: dao.ErrUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			ud, sc := tc.mock(ctl)
+			repo := NewSessionRepository(ud, sc, zap.NewNop())
+			version, err := repo.Revoke(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVersion, version)
+		})
+	}
+}
+
+func TestSessionRepositoryImpl_RevokeDeletedUser(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mr := miniredis.RunT(t)
+	sc := cache.NewRedisSessionCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), zap.NewNop())
+	ud := daomock.NewMockUserDao(ctl)
+	repo := NewSessionRepository(ud, sc, zap.NewNop())
+
+	// 登录之后缓存里有会话版本
+	require.NoError(t, sc.SetVersion(context.Background(), 1, 1))
+	version, err := repo.Version(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	// 用户删掉之后再作废会话，数据库里加不了版本，缓存也不能留着
+	ud.EXPECT().IncrSessionVersion(gomock.Any(), int64(1), int64(1)).Return(int64(0), dao.ErrUserNotFound)
+	_, err = repo.Revoke(context.Background(), 1)
+	assert.ErrorIs(t, err, dao.ErrUserNotFound)
+	assert.False(t, mr.Exists("user:session:version:1"))
+
+	// 之后的校验回源到数据库，用户不存在，旧 token 通不过
+	ud.EXPECT().FindById(gomock.Any(), int64(1)).Return(dao.User{}, dao.ErrUserNotFound)
+	_, err = repo.Version(context.Background(), 1)
+	assert.ErrorIs(t, err, dao.ErrUserNotFound)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"go.uber.org/zap"
+	"time"
+)
+
+type SmsTaskRepository interface {
+	Create(ctx context.Context, t domain.SmsTask) (int64, error)
+	// Preempt 抢占到期的任务，staleBefore 之前抢占还没有结果的任务可以被重新抢占
+	Preempt(ctx context.Context, staleBefore time.Time, limit int) ([]domain.SmsTask, error)
+	MarkSuccess(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, t domain.SmsTask) error
+}
+
+type SmsTaskRepositoryImpl struct {
+	dao    dao.SmsTaskDao
+	logger *zap.Logger
+}
+
+func NewSmsTaskRepository(d dao.SmsTaskDao, l *zap.Logger) SmsTaskRepository {
+	return &SmsTaskRepositoryImpl{
+		dao:    d,
+		logger: l,
+	}
+}
+
+func (repo *SmsTaskRepositoryImpl) Create(ctx context.Context, t domain.SmsTask) (int64, error) {
+	args, err := json.Marshal(t.Args)
+	if err != nil {
+		return 0, err
+	}
+	numbers, err := json.Marshal(t.Numbers)
+	if err != nil {
+		return 0, err
+	}
+	return repo.dao.Insert(ctx, dao.SmsTask{
+		Tpl:       t.Tpl,
+		Args:      string(args),
+		Numbers:   string(numbers),
+		Attempts:  t.Attempts,
+		NextTime:  t.NextTime.UnixMilli(),
+		LastError: t.LastError,
+	})
+}
+
+func (repo *SmsTaskRepositoryImpl) Preempt(ctx context.Context, staleBefore time.Time, limit int) ([]domain.SmsTask, error) {
+	tasks, err := repo.dao.Preempt(ctx, time.Now().UnixMilli(), staleBefore.UnixMilli(), limit)
+	res := make([]domain.SmsTask, 0, len(tasks))
+	for _, t := range tasks {
+		task, er := repo.entity2domain(t)
+		if er != nil {
+			// 数据坏了重试也没用，直接标记失败
+			repo.logger.Error("短信任务数据损坏", zap.Int64("id", t.Id), zap.Error(er))
+			_ = repo.dao.MarkFailed(ctx, t.Id, domain.SmsTaskStatusFailed.ToUint8(), t.Attempts, t.NextTime, er.Error())
+			continue
+		}
+		res = append(res, task)
+	}
+	return res, err
+}
+
+func (repo *SmsTaskRepositoryImpl) MarkSuccess(ctx context.Context, id int64) error {
+	return repo.dao.MarkSuccess(ctx, id)
+}
+
+func (repo *SmsTaskRepositoryImpl) MarkFailed(ctx context.Context, t domain.SmsTask) error {
+	return repo.dao.MarkFailed(ctx, t.Id, t.Status.ToUint8(), t.Attempts, t.NextTime.UnixMilli(), t.LastError)
+}
+
+func (repo *SmsTaskRepositoryImpl) entity2domain(t dao.SmsTask) (domain.SmsTask, error) {
+	var args, numbers []string
+	if err := json.Unmarshal([]byte(t.Args), &args); err != nil {
+		return domain.SmsTask{}, err
+	}
+	if err := json.Unmarshal([]byte(t.Numbers), &numbers); err != nil {
+		return domain.SmsTask{}, err
+	}
+	return domain.SmsTask{
+		Id:        t.Id,
+		Tpl:       t.Tpl,
+		Args:      args,
+		Numbers:   numbers,
+		Status:    domain.SmsTaskStatus(t.Status),
+		Attempts:  t.Attempts,
+		NextTime:  time.UnixMilli(t.NextTime),
+		LastError: t.LastError,
+		Ctime:     time.UnixMilli(t.CreateTime),
+		Utime:     time.UnixMilli(t.UpdateTime),
+	}, nil
+}
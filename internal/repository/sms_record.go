@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"time"
+)
+
+type SmsRecordRepository interface {
+	Create(ctx context.Context, records []domain.SmsRecord) error
+	// UpdateDelivery 按服务商、请求 id 和手机号找到记录，返回是否有记录被更新
+	UpdateDelivery(ctx context.Context, r domain.SmsRecord) (bool, error)
+	FindByPhoneHash(ctx context.Context, phoneHash string, offset int, limit int) ([]domain.SmsRecord, error)
+}
+
+type SmsRecordRepositoryImpl struct {
+	dao    dao.SmsRecordDao
+	logger *zap.Logger
+}
+
+func NewSmsRecordRepository(d dao.SmsRecordDao, l *zap.Logger) SmsRecordRepository {
+	return &SmsRecordRepositoryImpl{
+		dao:    d,
+		logger: l,
+	}
+}
+
+func (repo *SmsRecordRepositoryImpl) Create(ctx context.Context, records []domain.SmsRecord) error {
+	return repo.dao.InsertBatch(ctx, slice.Map[domain.SmsRecord, dao.SmsRecord](records,
+		func(idx int, src domain.SmsRecord) dao.SmsRecord {
+			return repo.domain2entity(src)
+		}))
+}
+
+func (repo *SmsRecordRepositoryImpl) UpdateDelivery(ctx context.Context, r domain.SmsRecord) (bool, error) {
+	affected, err := repo.dao.UpdateDelivery(ctx, repo.domain2entity(r))
+	return affected > 0, err
+}
+
+func (repo *SmsRecordRepositoryImpl) FindByPhoneHash(ctx context.Context, phoneHash string, offset int, limit int) ([]domain.SmsRecord, error) {
+	records, err := repo.dao.FindByPhoneHash(ctx, phoneHash, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.SmsRecord, domain.SmsRecord](records, func(idx int, src dao.SmsRecord) domain.SmsRecord {
+		return repo.entity2domain(src)
+	}), nil
+}
+
+func (repo *SmsRecordRepositoryImpl) domain2entity(r domain.SmsRecord) dao.SmsRecord {
+	var deliveryTime int64
+	if !r.DeliveryTime.IsZero() {
+		deliveryTime = r.DeliveryTime.UnixMilli()
+	}
+	return dao.SmsRecord{
+		Id:           r.Id,
+		Provider:     r.Provider,
+		RequestId:    r.RequestId,
+		Tpl:          r.Tpl,
+		Phone:        r.Phone,
+		PhoneHash:    r.PhoneHash,
+		Latency:      r.Latency.Milliseconds(),
+		Status:       r.Status.ToUint8(),
+		Error:        r.Error,
+		DeliveryTime: deliveryTime,
+	}
+}
+
+func (repo *SmsRecordRepositoryImpl) entity2domain(r dao.SmsRecord) domain.SmsRecord {
+	var deliveryTime time.Time
+	if r.DeliveryTime > 0 {
+		deliveryTime = time.UnixMilli(r.DeliveryTime)
+	}
+	return domain.SmsRecord{
+		Id:           r.Id,
+		Provider:     r.Provider,
+		Tpl:          r.Tpl,
+		Phone:        r.Phone,
+		PhoneHash:    r.PhoneHash,
+		RequestId:    r.RequestId,
+		Latency:      time.Duration(r.Latency) * time.Millisecond,
+		Status:       domain.SmsRecordStatus(r.Status),
+		Error:        r.Error,
+		DeliveryTime: deliveryTime,
+		Ctime:        time.UnixMilli(r.CreateTime),
+		Utime:        time.UnixMilli(r.UpdateTime),
+	}
+}
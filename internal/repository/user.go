@@ -10,8 +10,10 @@ import (
 )
 
 var (
-	ErrUserDuplicateEmail = dao.ErrUserDuplicateEmail
-	ErrUserNotFound       = dao.ErrUserNotFound
+	ErrUserDuplicateEmail  = dao.ErrUserDuplicateEmail
+	ErrUserDuplicateOAuth2 = dao.ErrUserDuplicateOAuth2
+	ErrUserDuplicatePhone  = dao.ErrUserDuplicatePhone
+	ErrUserNotFound        = dao.ErrUserNotFound
 )
 
 type UserRepository interface {
@@ -19,6 +21,14 @@ type UserRepository interface {
 	Create(ctx context.Context, user domain.User) error
 	FindById(ctx context.Context, id int64) (domain.User, error)
 	FindByPhone(ctx context.Context, phone string) (domain.User, error)
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	VerifyEmail(ctx context.Context, email string) error
+	FindByOAuth2(ctx context.Context, provider string, openId string) (domain.User, error)
+	BindOAuth2(ctx context.Context, id int64, provider string, openId string) error
+	UpdatePhone(ctx context.Context, id int64, phone string) error
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	UpdateStatus(ctx context.Context, id int64, status domain.UserStatus) error
+	Delete(ctx context.Context, id int64) error
 }
 
 type UserRepositoryImpl struct {
@@ -78,19 +88,115 @@ func (r *UserRepositoryImpl) FindByPhone(ctx context.Context, phone string) (dom
 	return r.Entity2Domain(u), err
 }
 
+// UpdatePassword 缓存里面也有密码，更新之后直接删掉缓存
+func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, id int64, password string) error {
+	if err := r.dao.UpdatePassword(ctx, id, password); err != nil {
+		return err
+	}
+	if err := r.cache.Delete(ctx, id); err != nil {
+		r.logger.Error("删除用户缓存失败", zap.Int64("uid", id), zap.Error(err))
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) VerifyEmail(ctx context.Context, email string) error {
+	u, err := r.dao.UpdateVerifiedByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if err = r.cache.Delete(ctx, u.Id); err != nil {
+		r.logger.Error("删除用户缓存失败", zap.Int64("uid", u.Id), zap.Error(err))
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) FindByOAuth2(ctx context.Context, provider string, openId string) (domain.User, error) {
+	u, err := r.dao.FindByOAuth2(ctx, provider, openId)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return r.Entity2Domain(u), err
+}
+
+func (r *UserRepositoryImpl) BindOAuth2(ctx context.Context, id int64, provider string, openId string) error {
+	if err := r.dao.UpdateOAuth2(ctx, id, provider, openId); err != nil {
+		return err
+	}
+	if err := r.cache.Delete(ctx, id); err != nil {
+		r.logger.Error("删除用户缓存失败", zap.Int64("uid", id), zap.Error(err))
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	u, err := r.dao.UpdatePhone(ctx, id, phone)
+	if err != nil {
+		return err
+	}
+	r.refreshCache(ctx, u)
+	return nil
+}
+
+func (r *UserRepositoryImpl) UpdateEmail(ctx context.Context, id int64, email string) error {
+	u, err := r.dao.UpdateEmail(ctx, id, email)
+	if err != nil {
+		return err
+	}
+	r.refreshCache(ctx, u)
+	return nil
+}
+
+func (r *UserRepositoryImpl) UpdateStatus(ctx context.Context, id int64, status domain.UserStatus) error {
+	if err := r.dao.UpdateStatus(ctx, id, uint8(status)); err != nil {
+		return err
+	}
+	if err := r.cache.Delete(ctx, id); err != nil {
+		r.logger.Error("删除用户缓存失败", zap.Int64("uid", id), zap.Error(err))
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	if err := r.dao.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := r.cache.Delete(ctx, id); err != nil {
+		r.logger.Error("删除用户缓存失败", zap.Int64("uid", id), zap.Error(err))
+	}
+	return nil
+}
+
+// refreshCache 用数据库里最新的数据覆盖缓存，覆盖失败就删掉，避免读到旧的手机号或邮箱
+func (r *UserRepositoryImpl) refreshCache(ctx context.Context, u dao.User) {
+	if err := r.cache.Set(ctx, r.Entity2Domain(u)); err == nil {
+		return
+	}
+	if err := r.cache.Delete(ctx, u.Id); err != nil {
+		r.logger.Error("删除用户缓存失败", zap.Int64("uid", u.Id), zap.Error(err))
+	}
+}
+
 func (r *UserRepositoryImpl) Entity2Domain(u dao.User) domain.User {
 	return domain.User{
-		Id:       u.Id,
-		Email:    u.Email.String,
-		Password: u.Password,
-		Phone:    u.Phone.String,
+		Id:           u.Id,
+		Email:        u.Email.String,
+		Password:     u.Password,
+		Phone:        u.Phone.String,
+		Verified:     u.Verified,
+		GithubId:     u.GithubId.String,
+		WechatOpenId: u.WechatOpenId.String,
+		Status:       domain.UserStatus(u.Status),
 	}
 }
 
 func (r *UserRepositoryImpl) Domain2Entity(u domain.User) dao.User {
 	return dao.User{
-		Email:    sql.NullString{String: u.Email, Valid: u.Email != ""},
-		Password: u.Password,
-		Phone:    sql.NullString{String: u.Phone, Valid: u.Phone != ""},
+		Email:        sql.NullString{String: u.Email, Valid: u.Email != ""},
+		Password:     u.Password,
+		Phone:        sql.NullString{String: u.Phone, Valid: u.Phone != ""},
+		Verified:     u.Verified,
+		GithubId:     sql.NullString{String: u.GithubId, Valid: u.GithubId != ""},
+		WechatOpenId: sql.NullString{String: u.WechatOpenId, Valid: u.WechatOpenId != ""},
+		Status:       uint8(u.Status),
 	}
 }
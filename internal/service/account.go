@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+)
+
+type AccountService interface {
+	// Delete 注销账号，调用方需要先完成身份校验
+	Delete(ctx context.Context, uid int64) error
+	// Export 导出用户的个人数据
+	Export(ctx context.Context, uid int64) (domain.UserArchive, error)
+}
+
+type AccountServiceImpl struct {
+	userRepo        repository.UserRepository
+	articleRepo     repository.ArticleRepository
+	interactiveRepo repository.InteractiveRepository
+	sessionRepo     repository.SessionRepository
+	followRepo      repository.FollowRepository
+	commentRepo     repository.CommentRepository
+	messageRepo     repository.MessageRepository
+	logger          *zap.Logger
+}
+
+func NewAccountService(userRepo repository.UserRepository,
+	articleRepo repository.ArticleRepository,
+	interactiveRepo repository.InteractiveRepository,
+	sessionRepo repository.SessionRepository,
+	followRepo repository.FollowRepository,
+	commentRepo repository.CommentRepository,
+	messageRepo repository.MessageRepository,
+	l *zap.Logger) AccountService {
+	return &AccountServiceImpl{
+		userRepo:        userRepo,
+		articleRepo:     articleRepo,
+		interactiveRepo: interactiveRepo,
+		sessionRepo:     sessionRepo,
+		followRepo:      followRepo,
+		commentRepo:     commentRepo,
+		messageRepo:     messageRepo,
+		logger:          l,
+	}
+}
+
+func (svc *AccountServiceImpl) Delete(ctx context.Context, uid int64) error {
+	// 关注关系会和用户一起删掉，先记下受影响的用户，删完之后修正他们的关注统计
+	relations, err := svc.followRepo.ListRelations(ctx, uid)
+	if err != nil {
+		return err
+	}
+	// 先作废会话再删数据，用户删掉之后就没有会话版本可以加了
+	if _, err = svc.sessionRepo.Revoke(ctx, uid); err != nil {
+		return err
+	}
+	if err = svc.userRepo.Delete(ctx, uid); err != nil {
+		return err
+	}
+	uids := []int64{uid}
+	for _, r := range relations {
+		if r.Follower == uid {
+			uids = append(uids, r.Followee)
+		} else {
+			uids = append(uids, r.Follower)
+		}
+	}
+	if err = svc.followRepo.ClearStatics(ctx, uids...); err != nil {
+		svc.logger.Error("注销账号清除关注统计缓存失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	return nil
+}
+
+func (svc *AccountServiceImpl) Export(ctx context.Context, uid int64) (domain.UserArchive, error) {
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return domain.UserArchive{}, err
+	}
+	articles, err := svc.articleRepo.ListByAuthor(ctx, uid)
+	if err != nil {
+		return domain.UserArchive{}, err
+	}
+	likes, err := svc.interactiveRepo.ListLikes(ctx, uid)
+	if err != nil {
+		return domain.UserArchive{}, err
+	}
+	comments, err := svc.commentRepo.ListByUid(ctx, uid)
+	if err != nil {
+		return domain.UserArchive{}, err
+	}
+	follows, err := svc.followRepo.ListRelations(ctx, uid)
+	if err != nil {
+		return domain.UserArchive{}, err
+	}
+	messages, err := svc.messageRepo.ListByUser(ctx, uid)
+	if err != nil {
+		return domain.UserArchive{}, err
+	}
+	return domain.UserArchive{
+		User:     u,
+		Articles: articles,
+		Likes:    likes,
+		Comments: comments,
+		Follows:  follows,
+		Messages: messages,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type accountMocks struct {
+	user        *repomock.MockUserRepository
+	article     *repomock.MockArticleRepository
+	interactive *repomock.MockInteractiveRepository
+	session     *repomock.MockSessionRepository
+	follow      *repomock.MockFollowRepository
+	comment     *repomock.MockCommentRepository
+	message     *repomock.MockMessageRepository
+}
+
+func newAccountMocks(ctl *gomock.Controller) accountMocks {
+	return accountMocks{
+		user:        repomock.NewMockUserRepository(ctl),
+		article:     repomock.NewMockArticleRepository(ctl),
+		interactive: repomock.NewMockInteractiveRepository(ctl),
+		session:     repomock.NewMockSessionRepository(ctl),
+		follow:      repomock.NewMockFollowRepository(ctl),
+		comment:     repomock.NewMockCommentRepository(ctl),
+		message:     repomock.NewMockMessageRepository(ctl),
+	}
+}
+
+func (m accountMocks) service() AccountService {
+	return NewAccountService(m.user, m.article, m.interactive, m.session, m.follow, m.comment, m.message, zap.NewNop())
+}
+
+func TestAccountServiceImpl_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(m accountMocks)
+		wantErr error
+	}{
+		{
+			name: "注销成功，清除双方的关注统计缓存",
+			mock: func(m accountMocks) {
+				m.follow.EXPECT().ListRelations(gomock.Any(), int64(1)).Return([]domain.FollowRelation{
+					{Follower: 1, Followee: 2},
+					{Follower: 3, Followee: 1},
+				}, nil)
+				gomock.InOrder(
+					m.session.EXPECT().Revoke(gomock.Any(), int64(1)).Return(int64(2), nil),
+					m.user.EXPECT().Delete(gomock.Any(), int64(1)).Return(nil),
+				)
+				m.follow.EXPECT().ClearStatics(gomock.Any(), int64(1), int64(2), int64(3)).Return(nil)
+			},
+		},
+		{
+			name: "删除用户失败不清缓存",
+			mock: func(m accountMocks) {
+				m.follow.EXPECT().ListRelations(gomock.Any(), int64(1)).Return(nil, nil)
+				m.session.EXPECT().Revoke(gomock.Any(), int64(1)).Return(int64(2), nil)
+				m.user.EXPECT().Delete(gomock.Any(), int64(1)).Return(errors.New("数据库错误"))
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+		{
+			name: "作废会话失败不删数据",
+			mock: func(m accountMocks) {
+				m.follow.EXPECT().ListRelations(gomock.Any(), int64(1)).Return(nil, nil)
+				m.session.EXPECT().Revoke(gomock.Any(), int64(1)).Return(int64(0), errors.New("redis 错误"))
+			},
+			wantErr: errors.New("redis 错误"),
+		},
+		{
+			name: "清缓存失败不影响注销",
+			mock: func(m accountMocks) {
+				m.follow.EXPECT().ListRelations(gomock.Any(), int64(1)).Return(nil, nil)
+				m.session.EXPECT().Revoke(gomock.Any(), int64(1)).Return(int64(2), nil)
+				m.user.EXPECT().Delete(gomock.Any(), int64(1)).Return(nil)
+				m.follow.EXPECT().ClearStatics(gomock.Any(), int64(1)).Return(errors.New("redis 错误"))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			m := newAccountMocks(ctl)
+			tc.mock(m)
+			err := m.service().Delete(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestAccountServiceImpl_Export(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(m accountMocks)
+		wantArchive domain.UserArchive
+		wantErr     error
+	}{
+		{
+			name: "导出所有个人数据",
+			mock: func(m accountMocks) {
+				m.user.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				m.article.EXPECT().ListByAuthor(gomock.Any(), int64(1)).Return([]domain.Article{{Id: 10}}, nil)
+				m.interactive.EXPECT().ListLikes(gomock.Any(), int64(1)).Return([]domain.Like{{Biz: "article", BizId: 11}}, nil)
+				m.comment.EXPECT().ListByUid(gomock.Any(), int64(1)).Return([]domain.Comment{{Id: 20, Uid: 1}}, nil)
+				m.follow.EXPECT().ListRelations(gomock.Any(), int64(1)).Return([]domain.FollowRelation{{Follower: 1, Followee: 2}}, nil)
+				m.message.EXPECT().ListByUser(gomock.Any(), int64(1)).Return([]domain.Message{{Id: 30, Sender: 2, Receiver: 1}}, nil)
+			},
+			wantArchive: domain.UserArchive{
+				User:     domain.User{Id: 1},
+				Articles: []domain.Article{{Id: 10}},
+				Likes:    []domain.Like{{Biz: "article", BizId: 11}},
+				Comments: []domain.Comment{{Id: 20, Uid: 1}},
+				Follows:  []domain.FollowRelation{{Follower: 1, Followee: 2}},
+				Messages: []domain.Message{{Id: 30, Sender: 2, Receiver: 1}},
+			},
+		},
+		{
+			name: "查询私信失败",
+			mock: func(m accountMocks) {
+				m.user.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				m.article.EXPECT().ListByAuthor(gomock.Any(), int64(1)).Return(nil, nil)
+				m.interactive.EXPECT().ListLikes(gomock.Any(), int64(1)).Return(nil, nil)
+				m.comment.EXPECT().ListByUid(gomock.Any(), int64(1)).Return(nil, nil)
+				m.follow.EXPECT().ListRelations(gomock.Any(), int64(1)).Return(nil, nil)
+				m.message.EXPECT().ListByUser(gomock.Any(), int64(1)).Return(nil, errors.New("数据库错误"))
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			m := newAccountMocks(ctl)
+			tc.mock(m)
+			archive, err := m.service().Export(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArchive, archive)
+		})
+	}
+}
@@ -2,40 +2,66 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/ChongYanOvO/little-blue-book/internal/domain"
 	"github.com/ChongYanOvO/little-blue-book/internal/repository"
 	"go.uber.org/zap"
 )
 
+var (
+	ErrUserUnverified  = errors.New("用户未完成验证")
+	ErrArticleNotFound = repository.ErrArticleNotFound
+)
+
 type ArticleService interface {
 	Save(ctx context.Context, article *domain.Article) (int64, error)
 	Create(ctx context.Context, article *domain.Article) (int64, error)
 	Update(ctx context.Context, article *domain.Article) error
 	Publish(ctx context.Context, article *domain.Article) (int64, error)
-	List(ctx context.Context, offset int, limit int) ([]domain.Article, error)
+	// List uid 屏蔽或者拉黑的作者的文章不返回
+	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// Takedown 管理员下架文章，作者不能再修改或重新发布
+	Takedown(ctx context.Context, id int64) error
 }
 
 type ArticleServiceImpl struct {
-	repo   repository.ArticleRepository
-	logger *zap.Logger
+	repo         repository.ArticleRepository
+	userRepo     repository.UserRepository
+	blockRepo    repository.BlockRepository
+	sensitiveSvc SensitiveService
+	logger       *zap.Logger
 }
 
-func NewArticleService(repo repository.ArticleRepository, l *zap.Logger) ArticleService {
+func NewArticleService(repo repository.ArticleRepository, userRepo repository.UserRepository,
+	blockRepo repository.BlockRepository, sensitiveSvc SensitiveService, l *zap.Logger) ArticleService {
 	return &ArticleServiceImpl{
-		repo:   repo,
-		logger: l,
+		repo:         repo,
+		userRepo:     userRepo,
+		blockRepo:    blockRepo,
+		sensitiveSvc: sensitiveSvc,
+		logger:       l,
 	}
 }
 
 func (svc *ArticleServiceImpl) Create(ctx context.Context, article *domain.Article) (int64, error) {
+	if _, err := svc.checkSensitive(article); err != nil {
+		return 0, err
+	}
 	return svc.repo.Create(ctx, article)
 }
 
 func (svc *ArticleServiceImpl) Update(ctx context.Context, article *domain.Article) error {
+	if _, err := svc.checkSensitive(article); err != nil {
+		return err
+	}
 	return svc.repo.Update(ctx, article)
 }
 
+// Save 草稿不公开，审核策略下命中的敏感词等发布的时候再提交审核
 func (svc *ArticleServiceImpl) Save(ctx context.Context, article *domain.Article) (int64, error) {
+	if _, err := svc.checkSensitive(article); err != nil {
+		return 0, err
+	}
 	article.Status = domain.ArticleStatusUnpublished
 	if article.Id > 0 {
 		if err := svc.repo.Update(ctx, article); err != nil {
@@ -46,11 +72,50 @@ func (svc *ArticleServiceImpl) Save(ctx context.Context, article *domain.Article
 	return svc.repo.Create(ctx, article)
 }
 
+// Publish 没有验证过邮箱或手机号的用户只能保存草稿，不能发布
 func (svc *ArticleServiceImpl) Publish(ctx context.Context, article *domain.Article) (int64, error) {
+	author, err := svc.userRepo.FindById(ctx, article.Author.Id)
+	if err != nil {
+		return 0, err
+	}
+	if !author.Verified {
+		return 0, ErrUserUnverified
+	}
+	hits, err := svc.checkSensitive(article)
+	if err != nil {
+		return 0, err
+	}
 	article.Status = domain.ArticleStatusPublished
-	return svc.repo.Sync(ctx, article)
+	id, err := svc.repo.Sync(ctx, article)
+	if err != nil || len(hits) == 0 {
+		return id, err
+	}
+	// 已经发布成功了，提交审核失败不影响发布
+	if er := svc.sensitiveSvc.Review(ctx, domain.BizArticle, id, hits); er != nil {
+		svc.logger.Error("文章提交审核失败", zap.Int64("articleId", id), zap.Error(er))
+	}
+	return id, nil
+}
+
+func (svc *ArticleServiceImpl) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	// 屏蔽和拉黑的作者在查询里去掉，查出来再过滤的话每页条数不够，分页也会乱
+	relations, err := svc.blockRepo.Relations(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make([]int64, 0, len(relations))
+	for target := range relations {
+		hidden = append(hidden, target)
+	}
+	return svc.repo.List(ctx, offset, limit, hidden)
+}
+
+func (svc *ArticleServiceImpl) checkSensitive(article *domain.Article) ([]domain.SensitiveHit, error) {
+	return svc.sensitiveSvc.Check(domain.BizArticle,
+		SensitiveField{Name: "title", Text: &article.Title},
+		SensitiveField{Name: "content", Text: &article.Content})
 }
 
-func (svc *ArticleServiceImpl) List(ctx context.Context, offset int, limit int) ([]domain.Article, error) {
-	return svc.repo.List(ctx, offset, limit)
+func (svc *ArticleServiceImpl) Takedown(ctx context.Context, id int64) error {
+	return svc.repo.UpdateStatus(ctx, id, domain.ArticleStatusTakenDown)
 }
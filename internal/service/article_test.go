@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/ChongYanOvO/little-blue-book/pkg/sensitive"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestArticleServiceImpl_List(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mock         func(ctl *gomock.Controller) (repository.ArticleRepository, repository.BlockRepository)
+		wantArticles []domain.Article
+		wantErr      error
+	}{
+		{
+			name: "屏蔽的作者在查询里去掉",
+			mock: func(ctl *gomock.Controller) (repository.ArticleRepository, repository.BlockRepository) {
+				ar := repomock.NewMockArticleRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().Relations(gomock.Any(), int64(1)).Return(map[int64]domain.BlockType{3: domain.BlockTypeMute}, nil)
+				ar.EXPECT().List(gomock.Any(), 0, 10, []int64{3}).Return([]domain.Article{{Id: 10}}, nil)
+				return ar, br
+			},
+			wantArticles: []domain.Article{{Id: 10}},
+		},
+		{
+			name: "没有屏蔽任何人",
+			mock: func(ctl *gomock.Controller) (repository.ArticleRepository, repository.BlockRepository) {
+				ar := repomock.NewMockArticleRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().Relations(gomock.Any(), int64(1)).Return(map[int64]domain.BlockType{}, nil)
+				ar.EXPECT().List(gomock.Any(), 0, 10, []int64{}).Return([]domain.Article{{Id: 10}, {Id: 11}}, nil)
+				return ar, br
+			},
+			wantArticles: []domain.Article{{Id: 10}, {Id: 11}},
+		},
+		{
+			name: "查询屏蔽关系失败",
+			mock: func(ctl *gomock.Controller) (repository.ArticleRepository, repository.BlockRepository) {
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().Relations(gomock.Any(), int64(1)).Return(nil, errors.New("数据库错误"))
+				return repomock.NewMockArticleRepository(ctl), br
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			ar, br := tc.mock(ctl)
+			svc := NewArticleService(ar, nil, br, nil, nil)
+			articles, err := svc.List(context.Background(), 1, 0, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArticles, articles)
+		})
+	}
+}
+
+func TestArticleServiceImpl_Update(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ar := repomock.NewMockArticleRepository(ctl)
+	ss := NewSensitiveService(sensitive.NewACFilter([]string{"赌博"}), SensitivePolicies{}, nil, nil)
+	svc := NewArticleService(ar, nil, nil, ss, nil)
+
+	// 没有配置策略的时候命中就拒绝，不能绕过发布时的检查直接改内容
+	err := svc.Update(context.Background(), &domain.Article{Id: 1, Title: "标题", Content: "来赌博吧"})
+	var sensitiveErr *SensitiveError
+	assert.ErrorAs(t, err, &sensitiveErr)
+
+	article := &domain.Article{Id: 1, Title: "标题", Content: "你好"}
+	ar.EXPECT().Update(gomock.Any(), article).Return(nil)
+	assert.NoError(t, svc.Update(context.Background(), article))
+}
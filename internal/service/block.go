@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrBlockSelf = errors.New("不能拉黑或屏蔽自己")
+	// ErrBlocked 被对方拉黑之后不能再关注、评论、私信对方
+	ErrBlocked = errors.New("对方已将你拉黑")
+)
+
+// maxBlockPageSize 拉黑和屏蔽列表每页最多返回的条数
+const maxBlockPageSize = 100
+
+type BlockService interface {
+	// Block 拉黑，同时解除双方的关注关系
+	Block(ctx context.Context, uid, target int64) error
+	Unblock(ctx context.Context, uid, target int64) error
+	// Mute 屏蔽，只是自己看不到对方的内容
+	Mute(ctx context.Context, uid, target int64) error
+	Unmute(ctx context.Context, uid, target int64) error
+	List(ctx context.Context, uid int64, typ domain.BlockType, offset, limit int) ([]domain.BlockRelation, error)
+}
+
+type BlockServiceImpl struct {
+	repo       repository.BlockRepository
+	userRepo   repository.UserRepository
+	followRepo repository.FollowRepository
+	logger     *zap.Logger
+}
+
+func NewBlockService(repo repository.BlockRepository, userRepo repository.UserRepository,
+	followRepo repository.FollowRepository, l *zap.Logger) BlockService {
+	return &BlockServiceImpl{
+		repo:       repo,
+		userRepo:   userRepo,
+		followRepo: followRepo,
+		logger:     l,
+	}
+}
+
+func (svc *BlockServiceImpl) Block(ctx context.Context, uid, target int64) error {
+	if err := svc.add(ctx, uid, target, domain.BlockTypeBlock); err != nil {
+		return err
+	}
+	if err := svc.followRepo.Unfollow(ctx, uid, target); err != nil {
+		return err
+	}
+	return svc.followRepo.Unfollow(ctx, target, uid)
+}
+
+func (svc *BlockServiceImpl) Unblock(ctx context.Context, uid, target int64) error {
+	return svc.repo.Remove(ctx, uid, target, domain.BlockTypeBlock)
+}
+
+func (svc *BlockServiceImpl) Mute(ctx context.Context, uid, target int64) error {
+	return svc.add(ctx, uid, target, domain.BlockTypeMute)
+}
+
+func (svc *BlockServiceImpl) Unmute(ctx context.Context, uid, target int64) error {
+	return svc.repo.Remove(ctx, uid, target, domain.BlockTypeMute)
+}
+
+func (svc *BlockServiceImpl) List(ctx context.Context, uid int64, typ domain.BlockType, offset, limit int) ([]domain.BlockRelation, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxBlockPageSize {
+		limit = maxBlockPageSize
+	}
+	return svc.repo.List(ctx, uid, typ, offset, limit)
+}
+
+func (svc *BlockServiceImpl) add(ctx context.Context, uid, target int64, typ domain.BlockType) error {
+	if uid == target {
+		return ErrBlockSelf
+	}
+	if _, err := svc.userRepo.FindById(ctx, target); err != nil {
+		return err
+	}
+	return svc.repo.Add(ctx, uid, target, typ)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/pkg/captcha"
+	"go.uber.org/zap"
+	"strings"
+)
+
+type CaptchaService interface {
+	// Generate 生成一个图形验证码，答案存到 Redis 里
+	Generate(ctx context.Context) (domain.Captcha, error)
+	// Verify 校验之后验证码就失效了，过期、不存在和答案错误都返回 false
+	Verify(ctx context.Context, answer domain.CaptchaAnswer) (bool, error)
+}
+
+type CaptchaServiceImpl struct {
+	repo      repository.CaptchaRepository
+	generator captcha.Generator
+	logger    *zap.Logger
+}
+
+func NewCaptchaService(repo repository.CaptchaRepository, generator captcha.Generator, l *zap.Logger) CaptchaService {
+	return &CaptchaServiceImpl{
+		repo:      repo,
+		generator: generator,
+		logger:    l,
+	}
+}
+
+func (svc *CaptchaServiceImpl) Generate(ctx context.Context) (domain.Captcha, error) {
+	answer, img, err := svc.generator.Generate()
+	if err != nil {
+		return domain.Captcha{}, err
+	}
+	id, err := svc.generateId()
+	if err != nil {
+		return domain.Captcha{}, err
+	}
+	if err = svc.repo.Store(ctx, id, answer); err != nil {
+		return domain.Captcha{}, err
+	}
+	return domain.Captcha{
+		Id:     id,
+		Answer: answer,
+		Image:  img,
+	}, nil
+}
+
+func (svc *CaptchaServiceImpl) Verify(ctx context.Context, answer domain.CaptchaAnswer) (bool, error) {
+	if answer.Id == "" || answer.Value == "" {
+		return false, nil
+	}
+	stored, err := svc.repo.Consume(ctx, answer.Id)
+	if errors.Is(err, repository.ErrCaptchaNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(strings.TrimSpace(answer.Value), stored), nil
+}
+
+func (svc *CaptchaServiceImpl) generateId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -2,34 +2,91 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
 	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/email"
 	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ratelimit"
 	"go.uber.org/zap"
 	"math/rand"
 )
 
-var codeTpId = "1877556"
+var (
+	ErrCodeSendTooMany        = repository.ErrCodeSendToMany
+	ErrCodeVerifyTooManyTimes = repository.ErrCodeVerifyTooManyTimes
+	// ErrCodePhoneLimited 同一个手机号一段时间内收到的验证码太多
+	ErrCodePhoneLimited = errors.New("该手机号获取验证码次数过多")
+	// ErrCodeEmailLimited 同一个邮箱一段时间内收到的验证码太多
+	ErrCodeEmailLimited = errors.New("该邮箱获取验证码次数过多")
+	// ErrCodeIpLimited 同一个 IP 一段时间内请求的验证码太多
+	ErrCodeIpLimited = errors.New("当前网络获取验证码次数过多")
+	// ErrCodeBizLimited 某种业务整体发送量太大，一般是被刷了
+	ErrCodeBizLimited = errors.New("验证码发送繁忙")
+	// ErrCaptchaRequired 发送频率触发了风控，需要先完成图形验证码
+	ErrCaptchaRequired = errors.New("需要图形验证码")
+	ErrInvalidCaptcha  = errors.New("图形验证码错误")
+)
+
+// CodeLimits 验证码的分层限流，先按 IP 再按手机号或邮箱最后按业务，
+// 被 IP 拦下来的请求不会占用手机号或邮箱的次数，短信和邮件共用 IP 和业务的额度。
+// Captcha 是风控阈值，同一个 IP、手机号或者邮箱超过它之后每次发送都要带上图形验证码
+type CodeLimits struct {
+	Phone   ratelimit.Limiter
+	Ip      ratelimit.Limiter
+	Biz     ratelimit.Limiter
+	Captcha ratelimit.Limiter
+}
+
+// codeTemplates 不同业务的验证码用的短信模板，没有配置的用登录验证码的模板
+var codeTemplates = map[string]string{
+	"reset_password": sms.TemplatePasswordReset,
+}
+
+const codeEmailSubject = "小蓝书验证码"
+
+// 验证码发送对象的种类，限流和风控的 key 里用来区分手机号和邮箱
+const (
+	codeTargetPhone = "phone"
+	codeTargetEmail = "email"
+)
 
 type CodeService interface {
+	// Send 发短信验证码，ip 为空的时候不按 IP 限流，没有触发风控的时候不校验 captcha
 	Send(ctx context.Context,
 		biz string,
-		phone string) error
+		phone string,
+		ip string,
+		captcha domain.CaptchaAnswer) error
+	// SendByEmail 通过邮件发送验证码，和短信验证码共用同一套存储、限流和风控
+	SendByEmail(ctx context.Context,
+		biz string,
+		email string,
+		ip string,
+		captcha domain.CaptchaAnswer) error
 	Verify(ctx context.Context, biz string,
 		phone string, inputCode string) (bool, error)
 }
 
 type CodeServiceImpl struct {
-	repo   repository.CodeRepository
-	smsSvc sms.SmsService
-	logger *zap.Logger
+	repo       repository.CodeRepository
+	smsSvc     sms.SmsService
+	emailSvc   email.EmailService
+	captchaSvc CaptchaService
+	limits     CodeLimits
+	logger     *zap.Logger
 }
 
-func NewCodeService(repo repository.CodeRepository, smsSvc sms.SmsService, l *zap.Logger) CodeService {
+func NewCodeService(repo repository.CodeRepository, smsSvc sms.SmsService, emailSvc email.EmailService,
+	captchaSvc CaptchaService, limits CodeLimits, l *zap.Logger) CodeService {
 	return &CodeServiceImpl{
-		repo:   repo,
-		smsSvc: smsSvc,
-		logger: l,
+		repo:       repo,
+		smsSvc:     smsSvc,
+		emailSvc:   emailSvc,
+		captchaSvc: captchaSvc,
+		limits:     limits,
+		logger:     l,
 	}
 }
 
@@ -37,7 +94,15 @@ func NewCodeService(repo repository.CodeRepository, smsSvc sms.SmsService, l *za
 func (svc *CodeServiceImpl) Send(ctx context.Context,
 	// 区别使用业务
 	biz string,
-	phone string) error {
+	phone string,
+	ip string,
+	captcha domain.CaptchaAnswer) error {
+	if err := svc.checkCaptcha(ctx, codeTargetPhone, phone, ip, captcha); err != nil {
+		return err
+	}
+	if err := svc.limit(ctx, biz, codeTargetPhone, phone, ip); err != nil {
+		return err
+	}
 	// 生成一个验证码
 	code := svc.generateCode()
 	// 塞进去 Redis
@@ -45,16 +110,34 @@ func (svc *CodeServiceImpl) Send(ctx context.Context,
 	if err != nil {
 		return err
 	}
-	// 发送出去
-	err = svc.smsSvc.Send(ctx, codeTpId, []string{code}, phone)
-	if err != nil {
-		// 这意味着，Redis 有这个验证码，但是不好意思
-		// 我能不能删掉这个验证码
-		// 你这个 err 可能是超时的 err，你都不知道收到了吗
-		// 在这里重试，要重试的时候，传入一个自己就会重试的 smsSvcRetry
+	// 发送出去，服务商失败或者被限流的时候由 sms.AsyncService 落库重试，
+	// 这里还是返回错误说明连落库都失败了
+	tpl, ok := codeTemplates[biz]
+	if !ok {
+		tpl = sms.TemplateLoginCode
+	}
+	return svc.smsSvc.Send(ctx, tpl, []string{code}, phone)
+}
 
+// SendByEmail 发邮件验证码，key 直接使用邮箱，不会和手机号冲突
+func (svc *CodeServiceImpl) SendByEmail(ctx context.Context,
+	biz string,
+	email string,
+	ip string,
+	captcha domain.CaptchaAnswer) error {
+	if err := svc.checkCaptcha(ctx, codeTargetEmail, email, ip, captcha); err != nil {
+		return err
+	}
+	if err := svc.limit(ctx, biz, codeTargetEmail, email, ip); err != nil {
+		return err
 	}
-	return err
+	code := svc.generateCode()
+	err := svc.repo.Store(ctx, biz, email, code)
+	if err != nil {
+		return err
+	}
+	return svc.emailSvc.Send(ctx, codeEmailSubject,
+		fmt.Sprintf("您的验证码是 %s，10 分钟内有效，请勿泄露给他人。", code), email)
 }
 
 func (svc *CodeServiceImpl) Verify(ctx context.Context, biz string,
@@ -62,8 +145,84 @@ func (svc *CodeServiceImpl) Verify(ctx context.Context, biz string,
 	return svc.repo.Verify(ctx, biz, phone, inputCode)
 }
 
+// checkCaptcha 同一个 IP、手机号或者邮箱发送太频繁的时候要求图形验证码，
+// 先于分层限流检查，没有通过图形验证码的请求不占用限流的次数
+func (svc *CodeServiceImpl) checkCaptcha(ctx context.Context, kind string, target string, ip string,
+	captcha domain.CaptchaAnswer) error {
+	risky, err := svc.risky(ctx, kind, target, ip)
+	if err != nil || !risky {
+		return err
+	}
+	if captcha.Id == "" {
+		return ErrCaptchaRequired
+	}
+	ok, err := svc.captchaSvc.Verify(ctx, captcha)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCaptcha
+	}
+	return nil
+}
+
+// risky IP 和手机号或邮箱任意一个超过风控阈值就算有风险，限流器出错的时候不放行
+func (svc *CodeServiceImpl) risky(ctx context.Context, kind string, target string, ip string) (bool, error) {
+	if svc.limits.Captcha == nil {
+		return false, nil
+	}
+	keys := []string{fmt.Sprintf("code:captcha:%s:%s", kind, target)}
+	if ip != "" {
+		keys = append([]string{fmt.Sprintf("code:captcha:ip:%s", ip)}, keys...)
+	}
+	for _, key := range keys {
+		limited, err := svc.limits.Captcha.Limit(ctx, key)
+		if err != nil {
+			svc.logger.Error("验证码风控限流器异常", zap.String("key", key), zap.Error(err))
+			return false, err
+		}
+		if limited {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// limit 按顺序检查每一层，限流器出错的时候不放行，短信是要花钱的
+func (svc *CodeServiceImpl) limit(ctx context.Context, biz string, kind string, target string, ip string) error {
+	if ip != "" {
+		if err := svc.limitLayer(ctx, svc.limits.Ip, fmt.Sprintf("code:send:ip:%s", ip), ErrCodeIpLimited); err != nil {
+			return err
+		}
+	}
+	limitErr := ErrCodePhoneLimited
+	if kind == codeTargetEmail {
+		limitErr = ErrCodeEmailLimited
+	}
+	if err := svc.limitLayer(ctx, svc.limits.Phone, fmt.Sprintf("code:send:%s:%s", kind, target), limitErr); err != nil {
+		return err
+	}
+	return svc.limitLayer(ctx, svc.limits.Biz, fmt.Sprintf("code:send:biz:%s", biz), ErrCodeBizLimited)
+}
+
+// limitLayer 没有配置的层不限流
+func (svc *CodeServiceImpl) limitLayer(ctx context.Context, limiter ratelimit.Limiter, key string, limitErr error) error {
+	if limiter == nil {
+		return nil
+	}
+	limited, err := limiter.Limit(ctx, key)
+	if err != nil {
+		svc.logger.Error("验证码限流器异常", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	if limited {
+		return limitErr
+	}
+	return nil
+}
+
 func (svc *CodeServiceImpl) generateCode() string {
 	num := rand.Intn(999999)
 	// 不够 6 位的，加上前导 0
-	return fmt.Sprintf("%6d", num)
+	return fmt.Sprintf("%06d", num)
 }
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	svcmock "github.com/ChongYanOvO/little-blue-book/internal/service/mock"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
+	smsmock "github.com/ChongYanOvO/little-blue-book/internal/service/sms/mock"
+	limitmock "github.com/ChongYanOvO/little-blue-book/pkg/ratelimit/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestCodeServiceImpl_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CodeLimits)
+		biz     string
+		ip      string
+		wantErr error
+	}{
+		{
+			name: "发送成功",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CodeLimits) {
+				ip, phone, biz := limitmock.NewMockLimiter(ctl), limitmock.NewMockLimiter(ctl), limitmock.NewMockLimiter(ctl)
+				ip.EXPECT().Limit(gomock.Any(), "code:send:ip:1.2.3.4").Return(false, nil)
+				phone.EXPECT().Limit(gomock.Any(), "code:send:phone:13800000000").Return(false, nil)
+				biz.EXPECT().Limit(gomock.Any(), "code:send:biz:reset_password").Return(false, nil)
+				repo := repomock.NewMockCodeRepository(ctl)
+				repo.EXPECT().Store(gomock.Any(), "reset_password", "13800000000", gomock.Any()).Return(nil)
+				smsSvc := smsmock.NewMockSmsService(ctl)
+				smsSvc.EXPECT().Send(gomock.Any(), sms.TemplatePasswordReset, gomock.Len(1), "13800000000").Return(nil)
+				return repo, smsSvc, CodeLimits{Phone: phone, Ip: ip, Biz: biz}
+			},
+			biz: "reset_password",
+			ip:  "1.2.3.4",
+		},
+		{
+			name: "IP 超限不占用手机号的次数",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CodeLimits) {
+				ip := limitmock.NewMockLimiter(ctl)
+				ip.EXPECT().Limit(gomock.Any(), "code:send:ip:1.2.3.4").Return(true, nil)
+				return repomock.NewMockCodeRepository(ctl), smsmock.NewMockSmsService(ctl),
+					CodeLimits{Phone: limitmock.NewMockLimiter(ctl), Ip: ip, Biz: limitmock.NewMockLimiter(ctl)}
+			},
+			biz:     "login",
+			ip:      "1.2.3.4",
+			wantErr: ErrCodeIpLimited,
+		},
+		{
+			name: "没有 IP 的时候直接检查手机号",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CodeLimits) {
+				phone := limitmock.NewMockLimiter(ctl)
+				phone.EXPECT().Limit(gomock.Any(), "code:send:phone:13800000000").Return(true, nil)
+				return repomock.NewMockCodeRepository(ctl), smsmock.NewMockSmsService(ctl),
+					CodeLimits{Phone: phone, Ip: limitmock.NewMockLimiter(ctl), Biz: limitmock.NewMockLimiter(ctl)}
+			},
+			biz:     "login",
+			wantErr: ErrCodePhoneLimited,
+		},
+		{
+			name: "业务整体超限",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CodeLimits) {
+				phone, biz := limitmock.NewMockLimiter(ctl), limitmock.NewMockLimiter(ctl)
+				phone.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				biz.EXPECT().Limit(gomock.Any(), "code:send:biz:login").Return(true, nil)
+				return repomock.NewMockCodeRepository(ctl), smsmock.NewMockSmsService(ctl),
+					CodeLimits{Phone: phone, Biz: biz}
+			},
+			biz:     "login",
+			wantErr: ErrCodeBizLimited,
+		},
+		{
+			name: "限流器出错不放行",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CodeLimits) {
+				phone := limitmock.NewMockLimiter(ctl)
+				phone.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, errors.New("redis error"))
+				return repomock.NewMockCodeRepository(ctl), smsmock.NewMockSmsService(ctl), CodeLimits{Phone: phone}
+			},
+			biz:     "login",
+			wantErr: errors.New("redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			repo, smsSvc, limits := tc.mock(ctl)
+			svc := NewCodeService(repo, smsSvc, nil, nil, limits, zap.NewNop())
+			err := svc.Send(context.Background(), tc.biz, "13800000000", tc.ip, domain.CaptchaAnswer{})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCodeServiceImpl_SendCaptcha(t *testing.T) {
+	answer := domain.CaptchaAnswer{Id: "id", Value: "1234"}
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CaptchaService, CodeLimits)
+		captcha domain.CaptchaAnswer
+		wantErr error
+	}{
+		{
+			name: "没有触发风控不需要图形验证码",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CaptchaService, CodeLimits) {
+				risk := limitmock.NewMockLimiter(ctl)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:ip:1.2.3.4").Return(false, nil)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:phone:13800000000").Return(false, nil)
+				repo := repomock.NewMockCodeRepository(ctl)
+				repo.EXPECT().Store(gomock.Any(), "login", "13800000000", gomock.Any()).Return(nil)
+				smsSvc := smsmock.NewMockSmsService(ctl)
+				smsSvc.EXPECT().Send(gomock.Any(), sms.TemplateLoginCode, gomock.Len(1), "13800000000").Return(nil)
+				return repo, smsSvc, svcmock.NewMockCaptchaService(ctl), CodeLimits{Captcha: risk}
+			},
+		},
+		{
+			name: "IP 触发风控没有带图形验证码",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CaptchaService, CodeLimits) {
+				risk, ip := limitmock.NewMockLimiter(ctl), limitmock.NewMockLimiter(ctl)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:ip:1.2.3.4").Return(true, nil)
+				return repomock.NewMockCodeRepository(ctl), smsmock.NewMockSmsService(ctl),
+					svcmock.NewMockCaptchaService(ctl), CodeLimits{Captcha: risk, Ip: ip}
+			},
+			wantErr: ErrCaptchaRequired,
+		},
+		{
+			name: "手机号触发风控，图形验证码错误",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CaptchaService, CodeLimits) {
+				risk := limitmock.NewMockLimiter(ctl)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:ip:1.2.3.4").Return(false, nil)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:phone:13800000000").Return(true, nil)
+				captchaSvc := svcmock.NewMockCaptchaService(ctl)
+				captchaSvc.EXPECT().Verify(gomock.Any(), answer).Return(false, nil)
+				return repomock.NewMockCodeRepository(ctl), smsmock.NewMockSmsService(ctl), captchaSvc, CodeLimits{Captcha: risk}
+			},
+			captcha: answer,
+			wantErr: ErrInvalidCaptcha,
+		},
+		{
+			name: "触发风控，图形验证码正确",
+			mock: func(ctl *gomock.Controller) (repository.CodeRepository, sms.SmsService, CaptchaService, CodeLimits) {
+				risk, phone := limitmock.NewMockLimiter(ctl), limitmock.NewMockLimiter(ctl)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:ip:1.2.3.4").Return(true, nil)
+				captchaSvc := svcmock.NewMockCaptchaService(ctl)
+				captchaSvc.EXPECT().Verify(gomock.Any(), answer).Return(true, nil)
+				phone.EXPECT().Limit(gomock.Any(), "code:send:phone:13800000000").Return(false, nil)
+				repo := repomock.NewMockCodeRepository(ctl)
+				repo.EXPECT().Store(gomock.Any(), "login", "13800000000", gomock.Any()).Return(nil)
+				smsSvc := smsmock.NewMockSmsService(ctl)
+				smsSvc.EXPECT().Send(gomock.Any(), sms.TemplateLoginCode, gomock.Len(1), "13800000000").Return(nil)
+				return repo, smsSvc, captchaSvc, CodeLimits{Captcha: risk, Phone: phone}
+			},
+			captcha: answer,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			repo, smsSvc, captchaSvc, limits := tc.mock(ctl)
+			svc := NewCodeService(repo, smsSvc, nil, captchaSvc, limits, zap.NewNop())
+			err := svc.Send(context.Background(), "login", "13800000000", "1.2.3.4", tc.captcha)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCodeServiceImpl_SendByEmail(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) CodeLimits
+		wantErr error
+	}{
+		{
+			name: "邮箱触发风控没有带图形验证码",
+			mock: func(ctl *gomock.Controller) CodeLimits {
+				risk := limitmock.NewMockLimiter(ctl)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:ip:1.2.3.4").Return(false, nil)
+				risk.EXPECT().Limit(gomock.Any(), "code:captcha:email:a@b.com").Return(true, nil)
+				return CodeLimits{Captcha: risk}
+			},
+			wantErr: ErrCaptchaRequired,
+		},
+		{
+			name: "IP 超限不占用邮箱的次数",
+			mock: func(ctl *gomock.Controller) CodeLimits {
+				ip := limitmock.NewMockLimiter(ctl)
+				ip.EXPECT().Limit(gomock.Any(), "code:send:ip:1.2.3.4").Return(true, nil)
+				return CodeLimits{Ip: ip, Phone: limitmock.NewMockLimiter(ctl)}
+			},
+			wantErr: ErrCodeIpLimited,
+		},
+		{
+			name: "邮箱超限",
+			mock: func(ctl *gomock.Controller) CodeLimits {
+				ip, email := limitmock.NewMockLimiter(ctl), limitmock.NewMockLimiter(ctl)
+				ip.EXPECT().Limit(gomock.Any(), "code:send:ip:1.2.3.4").Return(false, nil)
+				email.EXPECT().Limit(gomock.Any(), "code:send:email:a@b.com").Return(true, nil)
+				return CodeLimits{Ip: ip, Phone: email}
+			},
+			wantErr: ErrCodeEmailLimited,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := NewCodeService(repomock.NewMockCodeRepository(ctl), smsmock.NewMockSmsService(ctl), nil,
+				svcmock.NewMockCaptchaService(ctl), tc.mock(ctl), zap.NewNop())
+			err := svc.SendByEmail(context.Background(), "reset_password", "a@b.com", "1.2.3.4", domain.CaptchaAnswer{})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
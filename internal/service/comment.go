@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/chongyanovo/zkit/slice"
+	"go.uber.org/zap"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrCommentNotFound = repository.ErrCommentNotFound
+	ErrInvalidComment  = errors.New("评论内容不合法")
+)
+
+const (
+	// maxCommentLength 评论最多的字符数
+	maxCommentLength = 1000
+	// maxCommentPageSize 评论列表每页最多返回的条数
+	maxCommentPageSize = 50
+)
+
+type CommentService interface {
+	// Create 发表评论，ParentId 不为 0 的时候是回复
+	Create(ctx context.Context, c domain.Comment) (int64, error)
+	// Roots 根评论，maxId 为上一页最后一条的 id，uid 屏蔽的人的评论不返回
+	Roots(ctx context.Context, uid int64, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error)
+	// Replies 根评论下面的回复，minId 为上一页最后一条的 id，uid 屏蔽的人的回复不返回
+	Replies(ctx context.Context, uid int64, rootId int64, minId int64, limit int) ([]domain.Comment, error)
+	// Delete 只能删除自己的评论，回复会一起删除
+	Delete(ctx context.Context, uid int64, id int64) error
+	Like(ctx context.Context, uid int64, id int64) error
+	CancelLike(ctx context.Context, uid int64, id int64) error
+}
+
+type CommentServiceImpl struct {
+	repo           repository.CommentRepository
+	articleRepo    repository.ArticleRepository
+	blockRepo      repository.BlockRepository
+	interactiveSvc InteractiveService
+	notifySvc      NotificationService
+	sensitiveSvc   SensitiveService
+	logger         *zap.Logger
+}
+
+func NewCommentService(repo repository.CommentRepository, articleRepo repository.ArticleRepository,
+	blockRepo repository.BlockRepository, interactiveSvc InteractiveService, notifySvc NotificationService,
+	sensitiveSvc SensitiveService, l *zap.Logger) CommentService {
+	return &CommentServiceImpl{
+		repo:           repo,
+		articleRepo:    articleRepo,
+		blockRepo:      blockRepo,
+		interactiveSvc: interactiveSvc,
+		notifySvc:      notifySvc,
+		sensitiveSvc:   sensitiveSvc,
+		logger:         l,
+	}
+}
+
+func (svc *CommentServiceImpl) Create(ctx context.Context, c domain.Comment) (int64, error) {
+	c.Content = strings.TrimSpace(c.Content)
+	if c.Content == "" || utf8.RuneCountInString(c.Content) > maxCommentLength {
+		return 0, ErrInvalidComment
+	}
+	if c.ParentId > 0 {
+		parent, err := svc.repo.FindById(ctx, c.ParentId)
+		if err != nil {
+			return 0, err
+		}
+		// 回复跟着父评论走，客户端传的评论对象不算数
+		c.Biz = parent.Biz
+		c.BizId = parent.BizId
+		c.RootId = parent.RootId
+		if c.RootId == 0 {
+			c.RootId = parent.Id
+		}
+		return svc.create(ctx, parent.Uid, c)
+	}
+	if c.Biz != domain.BizArticle {
+		return 0, ErrInvalidComment
+	}
+	a, err := svc.articleRepo.FindPublishedById(ctx, c.BizId)
+	if err != nil {
+		return 0, err
+	}
+	if a.Status != domain.ArticleStatusPublished {
+		return 0, ErrArticleNotFound
+	}
+	c.RootId = 0
+	return svc.create(ctx, a.Author.Id, c)
+}
+
+func (svc *CommentServiceImpl) Roots(ctx context.Context, uid int64, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	comments, err := svc.repo.FindRoots(ctx, biz, bizId, maxId, svc.limit(limit))
+	if err != nil {
+		return nil, err
+	}
+	comments, err = svc.filterHidden(ctx, uid, comments)
+	if err != nil {
+		return nil, err
+	}
+	return svc.withLikeCount(ctx, comments), nil
+}
+
+func (svc *CommentServiceImpl) Replies(ctx context.Context, uid int64, rootId int64, minId int64, limit int) ([]domain.Comment, error) {
+	comments, err := svc.repo.FindReplies(ctx, rootId, minId, svc.limit(limit))
+	if err != nil {
+		return nil, err
+	}
+	comments, err = svc.filterHidden(ctx, uid, comments)
+	if err != nil {
+		return nil, err
+	}
+	return svc.withLikeCount(ctx, comments), nil
+}
+
+func (svc *CommentServiceImpl) Delete(ctx context.Context, uid int64, id int64) error {
+	c, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Uid != uid {
+		return ErrCommentNotFound
+	}
+	return svc.repo.Delete(ctx, c)
+}
+
+func (svc *CommentServiceImpl) Like(ctx context.Context, uid int64, id int64) error {
+	if _, err := svc.repo.FindById(ctx, id); err != nil {
+		return err
+	}
+	return svc.interactiveSvc.IncreaseLikeCount(ctx, domain.BizComment, id, uid)
+}
+
+func (svc *CommentServiceImpl) CancelLike(ctx context.Context, uid int64, id int64) error {
+	return svc.interactiveSvc.CancelLike(ctx, domain.BizComment, id, uid)
+}
+
+// create owner 是被评论的文章或者评论的作者，被 owner 拉黑之后不能评论
+func (svc *CommentServiceImpl) create(ctx context.Context, owner int64, c domain.Comment) (int64, error) {
+	blocked, err := svc.blockRepo.IsBlocked(ctx, owner, c.Uid)
+	if err != nil {
+		return 0, err
+	}
+	if blocked {
+		return 0, ErrBlocked
+	}
+	hits, err := svc.sensitiveSvc.Check(domain.BizComment, SensitiveField{Name: "content", Text: &c.Content})
+	if err != nil {
+		return 0, err
+	}
+	id, err := svc.repo.Create(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	c.Id = id
+	if len(hits) > 0 {
+		if er := svc.sensitiveSvc.Review(ctx, domain.BizComment, id, hits); er != nil {
+			svc.logger.Error("评论提交审核失败", zap.Int64("commentId", id), zap.Error(er))
+		}
+	}
+	svc.notifySvc.NotifyComment(c)
+	return id, nil
+}
+
+// filterHidden 去掉 uid 屏蔽或者拉黑的人发的评论
+func (svc *CommentServiceImpl) filterHidden(ctx context.Context, uid int64, comments []domain.Comment) ([]domain.Comment, error) {
+	relations, err := svc.blockRepo.Relations(ctx, uid)
+	if err != nil || len(relations) == 0 {
+		return comments, err
+	}
+	visible := comments[:0]
+	for _, c := range comments {
+		if _, hidden := relations[c.Uid]; !hidden {
+			visible = append(visible, c)
+		}
+	}
+	return visible, nil
+}
+
+// withLikeCount 填充点赞数，查询失败的时候不影响评论列表
+func (svc *CommentServiceImpl) withLikeCount(ctx context.Context, comments []domain.Comment) []domain.Comment {
+	if len(comments) == 0 {
+		return comments
+	}
+	ids := slice.Map[domain.Comment, int64](comments, func(idx int, src domain.Comment) int64 {
+		return src.Id
+	})
+	inters, err := svc.interactiveSvc.GetByIds(ctx, domain.BizComment, ids)
+	if err != nil {
+		svc.logger.Error("查询评论点赞数失败", zap.Error(err))
+		return comments
+	}
+	for i := range comments {
+		comments[i].LikeCount = inters[comments[i].Id].LikeCount
+	}
+	return comments
+}
+
+func (svc *CommentServiceImpl) limit(limit int) int {
+	if limit <= 0 || limit > maxCommentPageSize {
+		return maxCommentPageSize
+	}
+	return limit
+}
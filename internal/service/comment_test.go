@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	svcmock "github.com/ChongYanOvO/little-blue-book/internal/service/mock"
+	"github.com/ChongYanOvO/little-blue-book/pkg/sensitive"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestCommentServiceImpl_Create(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository, repository.BlockRepository, NotificationService)
+		comment domain.Comment
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "评论文章",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository, repository.BlockRepository, NotificationService) {
+				cr := repomock.NewMockCommentRepository(ctl)
+				ar := repomock.NewMockArticleRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).
+					Return(domain.Article{Id: 10, Author: domain.Author{Id: 2}, Status: domain.ArticleStatusPublished}, nil)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(2), int64(1)).Return(false, nil)
+				cr.EXPECT().Create(gomock.Any(), domain.Comment{
+					Uid: 1, Biz: domain.BizArticle, BizId: 10, Content: "hello",
+				}).Return(int64(100), nil)
+				ns := svcmock.NewMockNotificationService(ctl)
+				ns.EXPECT().NotifyComment(domain.Comment{
+					Id: 100, Uid: 1, Biz: domain.BizArticle, BizId: 10, Content: "hello",
+				})
+				return cr, ar, br, ns
+			},
+			comment: domain.Comment{Uid: 1, Biz: domain.BizArticle, BizId: 10, Content: " hello "},
+			wantId:  100,
+		},
+		{
+			name: "回复的回复挂在根评论下面",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository, repository.BlockRepository, NotificationService) {
+				cr := repomock.NewMockCommentRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(101)).Return(domain.Comment{
+					Id: 101, Uid: 3, Biz: domain.BizArticle, BizId: 10, RootId: 100, ParentId: 100,
+				}, nil)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(3), int64(2)).Return(false, nil)
+				cr.EXPECT().Create(gomock.Any(), domain.Comment{
+					Uid: 2, Biz: domain.BizArticle, BizId: 10, RootId: 100, ParentId: 101, Content: "reply",
+				}).Return(int64(102), nil)
+				ns := svcmock.NewMockNotificationService(ctl)
+				ns.EXPECT().NotifyComment(gomock.Any())
+				return cr, repomock.NewMockArticleRepository(ctl), br, ns
+			},
+			comment: domain.Comment{Uid: 2, Biz: "other", BizId: 99, ParentId: 101, Content: "reply"},
+			wantId:  102,
+		},
+		{
+			name: "被作者拉黑",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository, repository.BlockRepository, NotificationService) {
+				ar := repomock.NewMockArticleRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).
+					Return(domain.Article{Id: 10, Author: domain.Author{Id: 2}, Status: domain.ArticleStatusPublished}, nil)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(2), int64(1)).Return(true, nil)
+				return repomock.NewMockCommentRepository(ctl), ar, br, svcmock.NewMockNotificationService(ctl)
+			},
+			comment: domain.Comment{Uid: 1, Biz: domain.BizArticle, BizId: 10, Content: "hello"},
+			wantErr: ErrBlocked,
+		},
+		{
+			name: "文章没有发布",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository, repository.BlockRepository, NotificationService) {
+				ar := repomock.NewMockArticleRepository(ctl)
+				ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).
+					Return(domain.Article{Id: 10, Status: domain.ArticleStatusPrivate}, nil)
+				return repomock.NewMockCommentRepository(ctl), ar, repomock.NewMockBlockRepository(ctl), svcmock.NewMockNotificationService(ctl)
+			},
+			comment: domain.Comment{Uid: 1, Biz: domain.BizArticle, BizId: 10, Content: "hello"},
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "评论内容为空",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository, repository.BlockRepository, NotificationService) {
+				return repomock.NewMockCommentRepository(ctl), repomock.NewMockArticleRepository(ctl),
+					repomock.NewMockBlockRepository(ctl), svcmock.NewMockNotificationService(ctl)
+			},
+			comment: domain.Comment{Uid: 1, Biz: domain.BizArticle, BizId: 10, Content: "  "},
+			wantErr: ErrInvalidComment,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			cr, ar, br, ns := tc.mock(ctl)
+			ss := NewSensitiveService(sensitive.NewACFilter(nil), nil, nil, nil)
+			svc := NewCommentService(cr, ar, br, nil, ns, ss, nil)
+			id, err := svc.Create(context.Background(), tc.comment)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func TestCommentServiceImpl_Roots(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	cr := repomock.NewMockCommentRepository(ctl)
+	br := repomock.NewMockBlockRepository(ctl)
+	ir := repomock.NewMockInteractiveRepository(ctl)
+	cr.EXPECT().FindRoots(gomock.Any(), domain.BizArticle, int64(10), int64(0), maxCommentPageSize).
+		Return([]domain.Comment{{Id: 3, Uid: 2}, {Id: 2, Uid: 4}, {Id: 1, Uid: 5}}, nil)
+	br.EXPECT().Relations(gomock.Any(), int64(1)).Return(map[int64]domain.BlockType{4: domain.BlockTypeMute}, nil)
+	ir.EXPECT().GetByIds(gomock.Any(), domain.BizComment, []int64{3, 1}).
+		Return(map[int64]domain.Interactive{3: {LikeCount: 7}, 1: {}}, nil)
+	svc := NewCommentService(cr, nil, br, NewInteractiveServiceImpl(ir, nil), nil, nil, nil)
+	comments, err := svc.Roots(context.Background(), 1, domain.BizArticle, 10, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Comment{{Id: 3, Uid: 2, LikeCount: 7}, {Id: 1, Uid: 5}}, comments)
+}
+
+func TestCommentServiceImpl_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) repository.CommentRepository
+		uid     int64
+		wantErr error
+	}{
+		{
+			name: "删除自己的评论",
+			mock: func(ctl *gomock.Controller) repository.CommentRepository {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{Id: 100, Uid: 1}, nil)
+				cr.EXPECT().Delete(gomock.Any(), domain.Comment{Id: 100, Uid: 1}).Return(nil)
+				return cr
+			},
+			uid: 1,
+		},
+		{
+			name: "不能删除别人的评论",
+			mock: func(ctl *gomock.Controller) repository.CommentRepository {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{Id: 100, Uid: 1}, nil)
+				return cr
+			},
+			uid:     2,
+			wantErr: ErrCommentNotFound,
+		},
+		{
+			name: "评论不存在",
+			mock: func(ctl *gomock.Controller) repository.CommentRepository {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{}, repository.ErrCommentNotFound)
+				return cr
+			},
+			uid:     1,
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := NewCommentService(tc.mock(ctl), nil, nil, nil, nil, nil, nil)
+			assert.Equal(t, tc.wantErr, svc.Delete(context.Background(), tc.uid, 100))
+		})
+	}
+}
+
+func TestCommentServiceImpl_Like(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) (repository.CommentRepository, InteractiveService)
+		wantErr error
+	}{
+		{
+			name: "点赞评论并通知",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, InteractiveService) {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{Id: 100, Uid: 2}, nil)
+				ir := repomock.NewMockInteractiveRepository(ctl)
+				ir.EXPECT().IncreaseLikeCount(gomock.Any(), domain.BizComment, int64(100), int64(1)).Return(true, nil)
+				ns := svcmock.NewMockNotificationService(ctl)
+				ns.EXPECT().NotifyLike(int64(1), domain.BizComment, int64(100))
+				return cr, NewInteractiveServiceImpl(ir, ns)
+			},
+		},
+		{
+			name: "重复点赞不通知",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, InteractiveService) {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{Id: 100, Uid: 2}, nil)
+				ir := repomock.NewMockInteractiveRepository(ctl)
+				ir.EXPECT().IncreaseLikeCount(gomock.Any(), domain.BizComment, int64(100), int64(1)).Return(false, nil)
+				return cr, NewInteractiveServiceImpl(ir, svcmock.NewMockNotificationService(ctl))
+			},
+		},
+		{
+			name: "评论不存在",
+			mock: func(ctl *gomock.Controller) (repository.CommentRepository, InteractiveService) {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{}, repository.ErrCommentNotFound)
+				return cr, NewInteractiveServiceImpl(repomock.NewMockInteractiveRepository(ctl), nil)
+			},
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			cr, is := tc.mock(ctl)
+			svc := NewCommentService(cr, nil, nil, is, nil, nil, nil)
+			assert.Equal(t, tc.wantErr, svc.Like(context.Background(), 1, 100))
+		})
+	}
+}
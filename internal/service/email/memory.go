@@ -0,0 +1,27 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+)
+
+type MemoryService struct {
+	logger *zap.Logger
+}
+
+func NewMemoryService(l *zap.Logger) EmailService {
+	return &MemoryService{
+		logger: l,
+	}
+}
+
+func (m *MemoryService) Send(ctx context.Context, subject string, content string, to ...string) error {
+	fmt.Println("====================")
+	fmt.Println("收件人：", strings.Join(to, ","))
+	fmt.Println("主题：", subject)
+	fmt.Println("内容：", content)
+	fmt.Println("====================")
+	return nil
+}
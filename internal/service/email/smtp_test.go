@@ -0,0 +1,121 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"mime"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSmtpServer 本地的 SMTP 替身，只实现发信需要的几个命令
+type fakeSmtpServer struct {
+	listener net.Listener
+	from     string
+	rcpts    []string
+	data     string
+	done     chan struct{}
+}
+
+func newFakeSmtpServer(t *testing.T) *fakeSmtpServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSmtpServer{listener: l, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSmtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSmtpServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	write := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+	write("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			write("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			write("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpts = append(s.rcpts, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			write("250 OK")
+		case cmd == "DATA":
+			write("354 End data with <CR><LF>.<CR><LF>")
+			var sb strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				sb.WriteString(l)
+			}
+			s.data = sb.String()
+			write("250 OK")
+		case cmd == "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("502 Command not implemented")
+		}
+	}
+}
+
+func TestSmtpService_Send(t *testing.T) {
+	server := newFakeSmtpServer(t)
+	defer server.listener.Close()
+
+	svc := NewSmtpService("127.0.0.1", server.port(), "", "", "noreply@little-blue-book.com", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := svc.Send(ctx, "小蓝书验证码", "您的验证码是 123456", "a@test.com", "b@test.com")
+	require.NoError(t, err)
+
+	select {
+	case <-server.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP 替身没有收到完整的会话")
+	}
+	assert.Equal(t, "noreply@little-blue-book.com", server.from)
+	assert.Equal(t, []string{"a@test.com", "b@test.com"}, server.rcpts)
+	assert.Contains(t, server.data, "To: a@test.com, b@test.com\r\n")
+	assert.Contains(t, server.data, "Subject: "+mime.BEncoding.Encode("UTF-8", "小蓝书验证码")+"\r\n")
+	assert.True(t, strings.HasSuffix(server.data, "\r\n\r\n您的验证码是 123456\r\n"))
+}
+
+func TestSmtpService_SendFailed(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	svc := NewSmtpService("127.0.0.1", port, "", "", "noreply@little-blue-book.com", nil)
+	err = svc.Send(context.Background(), "主题", "内容", "a@test.com")
+	assert.Error(t, err, "连接不上 "+strconv.Itoa(port)+" 应该返回错误")
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+const (
+	// feedPushThreshold 粉丝数少于这个值的作者发布文章时推到粉丝的收件箱，否则读的时候再去拉
+	feedPushThreshold = 1000
+	// feedFanoutBatch 推模式每批写入的粉丝数
+	feedFanoutBatch = 500
+	// maxFeedFollowees 读关注流的时候最多考虑的关注数
+	maxFeedFollowees = 2000
+	// maxFeedPageSize 关注流每页最多返回的条数
+	maxFeedPageSize = 50
+	// feedFanoutTimeout 一篇文章推到所有粉丝收件箱的超时时间
+	feedFanoutTimeout = time.Minute
+)
+
+// FeedService 关注流，推拉结合
+// 粉丝少的作者发布文章时直接写到粉丝的收件箱（推），粉丝多的作者在读的时候查他们的线上文章（拉），两边合并之后返回
+type FeedService interface {
+	// Fanout 文章发布之后调用，粉丝少的作者把文章推到粉丝的收件箱，异步执行不阻塞发布
+	Fanout(articleId int64)
+	// Feed 用户关注的作者发布的文章，按发布时间倒序，同时返回下一页的游标，零值表示已经到底
+	Feed(ctx context.Context, uid int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, domain.FeedCursor, error)
+}
+
+type FeedServiceImpl struct {
+	repo       repository.FeedRepository
+	followRepo repository.FollowRepository
+	blockRepo  repository.BlockRepository
+	logger     *zap.Logger
+}
+
+func NewFeedService(repo repository.FeedRepository, followRepo repository.FollowRepository,
+	blockRepo repository.BlockRepository, l *zap.Logger) FeedService {
+	return &FeedServiceImpl{
+		repo:       repo,
+		followRepo: followRepo,
+		blockRepo:  blockRepo,
+		logger:     l,
+	}
+}
+
+func (svc *FeedServiceImpl) Fanout(articleId int64) {
+	// 请求结束之后 ctx 会被取消，这里单独起一个
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), feedFanoutTimeout)
+		defer cancel()
+		if err := svc.fanout(ctx, articleId); err != nil {
+			svc.logger.Error("文章推送到关注流失败", zap.Int64("articleId", articleId), zap.Error(err))
+		}
+	}()
+}
+
+func (svc *FeedServiceImpl) fanout(ctx context.Context, articleId int64) error {
+	item, err := svc.repo.FindPublishedById(ctx, articleId)
+	if err != nil {
+		return err
+	}
+	authorId := item.Article.Author.Id
+	// 和读的时候用同一个口径判断，统计缓存可能是旧的
+	popular, err := svc.followRepo.FilterPopular(ctx, []int64{authorId}, feedPushThreshold)
+	if err != nil {
+		return err
+	}
+	if len(popular) > 0 {
+		// 粉丝太多，记下来之后读的时候一直拉
+		return svc.repo.MarkPull(ctx, authorId)
+	}
+	var afterId int64
+	for {
+		var uids []int64
+		uids, afterId, err = svc.followRepo.FollowerIds(ctx, authorId, afterId, feedFanoutBatch)
+		if err != nil {
+			return err
+		}
+		if err = svc.repo.Push(ctx, item, uids); err != nil {
+			return err
+		}
+		if len(uids) < feedFanoutBatch {
+			return nil
+		}
+	}
+}
+
+func (svc *FeedServiceImpl) Feed(ctx context.Context, uid int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, domain.FeedCursor, error) {
+	if limit <= 0 || limit > maxFeedPageSize {
+		limit = maxFeedPageSize
+	}
+	followees, err := svc.followRepo.FolloweeIds(ctx, uid, maxFeedFollowees)
+	if err != nil || len(followees) == 0 {
+		return []domain.FeedItem{}, domain.FeedCursor{}, err
+	}
+	// 屏蔽的作者在查询之前就去掉，这样分页不会被过滤打乱
+	relations, err := svc.blockRepo.Relations(ctx, uid)
+	if err != nil {
+		return nil, domain.FeedCursor{}, err
+	}
+	visible := followees[:0]
+	for _, followee := range followees {
+		if _, hidden := relations[followee]; !hidden {
+			visible = append(visible, followee)
+		}
+	}
+	followees = visible
+	if len(followees) == 0 {
+		return []domain.FeedItem{}, domain.FeedCursor{}, nil
+	}
+	// 收件箱按当前的关注过滤，取消关注之后之前推过来的文章也不再展示
+	pushed, scanned, err := svc.repo.FindInbox(ctx, uid, followees, cursor, limit)
+	if err != nil {
+		return nil, domain.FeedCursor{}, err
+	}
+	pullAuthors, err := svc.pullAuthors(ctx, followees)
+	if err != nil {
+		return nil, domain.FeedCursor{}, err
+	}
+	pulled, err := svc.repo.FindPublished(ctx, pullAuthors, cursor, limit)
+	if err != nil {
+		return nil, domain.FeedCursor{}, err
+	}
+	items := svc.merge(pushed, pulled)
+	if scanned != (domain.FeedCursor{}) {
+		// 收件箱只扫到 scanned，比它旧的拉模式文章留到下一页，不然中间的推模式文章会被跳过
+		kept := items[:0]
+		for _, item := range items {
+			if !item.Cursor().Before(scanned) {
+				kept = append(kept, item)
+			}
+		}
+		items = kept
+	}
+	if len(items) >= limit {
+		items = items[:limit]
+		return items, items[limit-1].Cursor(), nil
+	}
+	// 这一页的文章可能都被下架了，游标要停在扫过的位置，不能当成已经到底
+	return items, scanned, nil
+}
+
+// pullAuthors 需要拉的作者，当前粉丝数超过阈值的，加上以前超过阈值、发布时没有推的
+func (svc *FeedServiceImpl) pullAuthors(ctx context.Context, followees []int64) ([]int64, error) {
+	popular, err := svc.followRepo.FilterPopular(ctx, followees, feedPushThreshold)
+	if err != nil {
+		return nil, err
+	}
+	marked, err := svc.repo.FilterPullAuthors(ctx, followees)
+	if err != nil {
+		return nil, err
+	}
+	authors := make([]int64, 0, len(popular)+len(marked))
+	seen := make(map[int64]struct{}, len(popular)+len(marked))
+	for _, id := range append(popular, marked...) {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		authors = append(authors, id)
+	}
+	return authors, nil
+}
+
+// merge 合并推拉两边的结果，作者粉丝数跨过阈值前后的文章可能两边都有，按文章 id 去重
+func (svc *FeedServiceImpl) merge(pushed, pulled []domain.FeedItem) []domain.FeedItem {
+	items := make([]domain.FeedItem, 0, len(pushed)+len(pulled))
+	seen := make(map[int64]struct{}, len(pushed)+len(pulled))
+	for _, item := range append(pushed, pulled...) {
+		if _, ok := seen[item.Article.Id]; ok {
+			continue
+		}
+		seen[item.Article.Id] = struct{}{}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Ctime.Equal(items[j].Ctime) {
+			return items[i].Ctime.After(items[j].Ctime)
+		}
+		return items[i].Article.Id > items[j].Article.Id
+	})
+	return items
+}
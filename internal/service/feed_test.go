@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestFeedServiceImpl_Feed(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	item := func(id int64, ago time.Duration) domain.FeedItem {
+		return domain.FeedItem{Article: domain.Article{Id: id}, Ctime: now.Add(-ago)}
+	}
+	testCases := []struct {
+		name       string
+		mock       func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository, repository.BlockRepository)
+		limit      int
+		wantIds    []int64
+		wantCursor domain.FeedCursor
+	}{
+		{
+			name: "推拉合并去重，屏蔽的作者不查",
+			mock: func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository, repository.BlockRepository) {
+				fr := repomock.NewMockFeedRepository(ctl)
+				fo := repomock.NewMockFollowRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				fo.EXPECT().FolloweeIds(gomock.Any(), int64(1), maxFeedFollowees).Return([]int64{2, 3, 4}, nil)
+				br.EXPECT().Relations(gomock.Any(), int64(1)).Return(map[int64]domain.BlockType{4: domain.BlockTypeMute}, nil)
+				fo.EXPECT().FilterPopular(gomock.Any(), []int64{2, 3}, int64(feedPushThreshold)).Return([]int64{3}, nil)
+				fr.EXPECT().FilterPullAuthors(gomock.Any(), []int64{2, 3}).Return([]int64{}, nil)
+				fr.EXPECT().FindInbox(gomock.Any(), int64(1), []int64{2, 3}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{item(10, time.Minute), item(8, time.Hour)}, domain.FeedCursor{}, nil)
+				// 文章 8 是作者粉丝数跨过阈值之前推过来的，拉的时候也会查到
+				fr.EXPECT().FindPublished(gomock.Any(), []int64{3}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{item(11, time.Second), item(8, time.Hour), item(5, time.Hour*2)}, nil)
+				return fr, fo, br
+			},
+			limit:      3,
+			wantIds:    []int64{11, 10, 8},
+			wantCursor: item(8, time.Hour).Cursor(),
+		},
+		{
+			name: "粉丝数降回阈值以下的作者继续拉",
+			mock: func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository, repository.BlockRepository) {
+				fr := repomock.NewMockFeedRepository(ctl)
+				fo := repomock.NewMockFollowRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				fo.EXPECT().FolloweeIds(gomock.Any(), int64(1), maxFeedFollowees).Return([]int64{2, 3}, nil)
+				br.EXPECT().Relations(gomock.Any(), int64(1)).Return(map[int64]domain.BlockType{}, nil)
+				fo.EXPECT().FilterPopular(gomock.Any(), []int64{2, 3}, int64(feedPushThreshold)).Return([]int64{}, nil)
+				fr.EXPECT().FilterPullAuthors(gomock.Any(), []int64{2, 3}).Return([]int64{3}, nil)
+				fr.EXPECT().FindInbox(gomock.Any(), int64(1), []int64{2, 3}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{item(10, time.Minute)}, domain.FeedCursor{}, nil)
+				fr.EXPECT().FindPublished(gomock.Any(), []int64{3}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{item(11, time.Second)}, nil)
+				return fr, fo, br
+			},
+			limit:   3,
+			wantIds: []int64{11, 10},
+		},
+		{
+			name: "收件箱这一页都被下架，游标停在扫过的位置",
+			mock: func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository, repository.BlockRepository) {
+				fr := repomock.NewMockFeedRepository(ctl)
+				fo := repomock.NewMockFollowRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				fo.EXPECT().FolloweeIds(gomock.Any(), int64(1), maxFeedFollowees).Return([]int64{2}, nil)
+				br.EXPECT().Relations(gomock.Any(), int64(1)).Return(map[int64]domain.BlockType{}, nil)
+				fo.EXPECT().FilterPopular(gomock.Any(), []int64{2}, int64(feedPushThreshold)).Return([]int64{}, nil)
+				fr.EXPECT().FilterPullAuthors(gomock.Any(), []int64{2}).Return([]int64{}, nil)
+				fr.EXPECT().FindInbox(gomock.Any(), int64(1), []int64{2}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{}, item(7, time.Hour).Cursor(), nil)
+				fr.EXPECT().FindPublished(gomock.Any(), []int64{}, domain.FeedCursor{}, 3).Return([]domain.FeedItem{}, nil)
+				return fr, fo, br
+			},
+			limit:      3,
+			wantIds:    []int64{},
+			wantCursor: item(7, time.Hour).Cursor(),
+		},
+		{
+			name: "比收件箱扫过的位置更旧的拉模式文章留到下一页",
+			mock: func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository, repository.BlockRepository) {
+				fr := repomock.NewMockFeedRepository(ctl)
+				fo := repomock.NewMockFollowRepository(ctl)
+				br := repomock.NewMockBlockRepository(ctl)
+				fo.EXPECT().FolloweeIds(gomock.Any(), int64(1), maxFeedFollowees).Return([]int64{2, 3}, nil)
+				br.EXPECT().Relations(gomock.Any(), int64(1)).Return(map[int64]domain.BlockType{}, nil)
+				fo.EXPECT().FilterPopular(gomock.Any(), []int64{2, 3}, int64(feedPushThreshold)).Return([]int64{3}, nil)
+				fr.EXPECT().FilterPullAuthors(gomock.Any(), []int64{2, 3}).Return([]int64{3}, nil)
+				// 收件箱扫了 3 条，只有文章 10 还在线上
+				fr.EXPECT().FindInbox(gomock.Any(), int64(1), []int64{2, 3}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{item(10, time.Minute)}, item(9, time.Hour).Cursor(), nil)
+				fr.EXPECT().FindPublished(gomock.Any(), []int64{3}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{item(12, time.Second), item(5, time.Hour*2)}, nil)
+				return fr, fo, br
+			},
+			limit:      3,
+			wantIds:    []int64{12, 10},
+			wantCursor: item(9, time.Hour).Cursor(),
+		},
+		{
+			name: "没有关注任何人",
+			mock: func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository, repository.BlockRepository) {
+				fo := repomock.NewMockFollowRepository(ctl)
+				fo.EXPECT().FolloweeIds(gomock.Any(), int64(1), maxFeedFollowees).Return([]int64{}, nil)
+				return repomock.NewMockFeedRepository(ctl), fo, repomock.NewMockBlockRepository(ctl)
+			},
+			limit:   3,
+			wantIds: []int64{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			fr, fo, br := tc.mock(ctl)
+			svc := NewFeedService(fr, fo, br, nil)
+			items, next, err := svc.Feed(context.Background(), 1, domain.FeedCursor{}, tc.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCursor, next)
+			ids := make([]int64, 0, len(items))
+			for _, it := range items {
+				ids = append(ids, it.Article.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func TestFeedServiceImpl_Fanout(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository)
+	}{
+		{
+			name: "粉丝少推到收件箱",
+			mock: func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository) {
+				fr := repomock.NewMockFeedRepository(ctl)
+				fo := repomock.NewMockFollowRepository(ctl)
+				item := domain.FeedItem{Article: domain.Article{Id: 1, Author: domain.Author{Id: 2}}}
+				fr.EXPECT().FindPublishedById(gomock.Any(), int64(1)).Return(item, nil)
+				fo.EXPECT().FilterPopular(gomock.Any(), []int64{2}, int64(feedPushThreshold)).Return([]int64{}, nil)
+				fo.EXPECT().FollowerIds(gomock.Any(), int64(2), int64(0), feedFanoutBatch).Return([]int64{3, 4}, int64(9), nil)
+				fr.EXPECT().Push(gomock.Any(), item, []int64{3, 4}).Return(nil)
+				return fr, fo
+			},
+		},
+		{
+			name: "粉丝多记下来读的时候再拉",
+			mock: func(ctl *gomock.Controller) (repository.FeedRepository, repository.FollowRepository) {
+				fr := repomock.NewMockFeedRepository(ctl)
+				fo := repomock.NewMockFollowRepository(ctl)
+				item := domain.FeedItem{Article: domain.Article{Id: 1, Author: domain.Author{Id: 2}}}
+				fr.EXPECT().FindPublishedById(gomock.Any(), int64(1)).Return(item, nil)
+				fo.EXPECT().FilterPopular(gomock.Any(), []int64{2}, int64(feedPushThreshold)).Return([]int64{2}, nil)
+				fr.EXPECT().MarkPull(gomock.Any(), int64(2)).Return(nil)
+				return fr, fo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			fr, fo := tc.mock(ctl)
+			svc := NewFeedService(fr, fo, nil, nil).(*FeedServiceImpl)
+			assert.NoError(t, svc.fanout(context.Background(), 1))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrFollowSelf = errors.New("不能关注自己")
+
+// maxFollowPageSize 关注列表每页最多返回的条数
+const maxFollowPageSize = 100
+
+type FollowService interface {
+	Follow(ctx context.Context, follower, followee int64) error
+	Unfollow(ctx context.Context, follower, followee int64) error
+	// Followers 用户的粉丝列表
+	Followers(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	// Followees 用户的关注列表
+	Followees(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	Statics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type FollowServiceImpl struct {
+	repo      repository.FollowRepository
+	userRepo  repository.UserRepository
+	blockRepo repository.BlockRepository
+	notifySvc NotificationService
+	logger    *zap.Logger
+}
+
+func NewFollowService(repo repository.FollowRepository, userRepo repository.UserRepository,
+	blockRepo repository.BlockRepository, notifySvc NotificationService, l *zap.Logger) FollowService {
+	return &FollowServiceImpl{
+		repo:      repo,
+		userRepo:  userRepo,
+		blockRepo: blockRepo,
+		notifySvc: notifySvc,
+		logger:    l,
+	}
+}
+
+func (svc *FollowServiceImpl) Follow(ctx context.Context, follower, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	if _, err := svc.userRepo.FindById(ctx, followee); err != nil {
+		return err
+	}
+	blocked, err := svc.blockRepo.IsBlocked(ctx, followee, follower)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	changed, err := svc.repo.Follow(ctx, follower, followee)
+	if err != nil {
+		return err
+	}
+	if changed {
+		svc.notifySvc.NotifyFollow(follower, followee)
+	}
+	return nil
+}
+
+func (svc *FollowServiceImpl) Unfollow(ctx context.Context, follower, followee int64) error {
+	return svc.repo.Unfollow(ctx, follower, followee)
+}
+
+func (svc *FollowServiceImpl) Followers(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	offset, limit = svc.page(offset, limit)
+	return svc.repo.ListFollowers(ctx, uid, offset, limit)
+}
+
+func (svc *FollowServiceImpl) Followees(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	offset, limit = svc.page(offset, limit)
+	return svc.repo.ListFollowees(ctx, uid, offset, limit)
+}
+
+func (svc *FollowServiceImpl) Statics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	return svc.repo.GetStatics(ctx, uid)
+}
+
+func (svc *FollowServiceImpl) page(offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxFollowPageSize {
+		limit = maxFollowPageSize
+	}
+	return offset, limit
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	svcmock "github.com/ChongYanOvO/little-blue-book/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestFollowServiceImpl_Follow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, repository.BlockRepository, NotificationService)
+		follower int64
+		followee int64
+		wantErr  error
+	}{
+		{
+			name: "关注成功",
+			mock: func(ctl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, repository.BlockRepository, NotificationService) {
+				fr := repomock.NewMockFollowRepository(ctl)
+				ur := repomock.NewMockUserRepository(ctl)
+				ur.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(2), int64(1)).Return(false, nil)
+				fr.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).Return(true, nil)
+				ns := svcmock.NewMockNotificationService(ctl)
+				ns.EXPECT().NotifyFollow(int64(1), int64(2))
+				return fr, ur, br, ns
+			},
+			follower: 1,
+			followee: 2,
+		},
+		{
+			name: "不能关注自己",
+			mock: func(ctl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, repository.BlockRepository, NotificationService) {
+				return repomock.NewMockFollowRepository(ctl), repomock.NewMockUserRepository(ctl),
+					repomock.NewMockBlockRepository(ctl), svcmock.NewMockNotificationService(ctl)
+			},
+			follower: 1,
+			followee: 1,
+			wantErr:  ErrFollowSelf,
+		},
+		{
+			name: "被关注的用户不存在",
+			mock: func(ctl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, repository.BlockRepository, NotificationService) {
+				ur := repomock.NewMockUserRepository(ctl)
+				ur.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{}, repository.ErrUserNotFound)
+				return repomock.NewMockFollowRepository(ctl), ur, repomock.NewMockBlockRepository(ctl), svcmock.NewMockNotificationService(ctl)
+			},
+			follower: 1,
+			followee: 2,
+			wantErr:  ErrUserNotFound,
+		},
+		{
+			name: "被对方拉黑",
+			mock: func(ctl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, repository.BlockRepository, NotificationService) {
+				ur := repomock.NewMockUserRepository(ctl)
+				ur.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(2), int64(1)).Return(true, nil)
+				return repomock.NewMockFollowRepository(ctl), ur, br, svcmock.NewMockNotificationService(ctl)
+			},
+			follower: 1,
+			followee: 2,
+			wantErr:  ErrBlocked,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			fr, ur, br, ns := tc.mock(ctl)
+			svc := NewFollowService(fr, ur, br, ns, nil)
+			err := svc.Follow(context.Background(), tc.follower, tc.followee)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
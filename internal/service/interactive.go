@@ -2,27 +2,52 @@ package service
 
 import (
 	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
 	"github.com/ChongYanOvO/little-blue-book/internal/repository"
-	"github.com/gin-gonic/gin"
 )
 
 type InteractiveService interface {
 	IncreaseReadCount(ctx context.Context, biz string, bizId int64) error
-	IncreaseLikeCount(ctx *gin.Context, biz string, bizId int64, uid int64) error
+	IncreaseLikeCount(ctx context.Context, biz string, bizId int64, uid int64) error
+	// CancelLike 取消点赞
+	CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	// GetByIds 批量查询计数
+	GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
 }
 
 type InteractiveServiceImpl struct {
-	repo repository.InteractiveRepository
+	repo      repository.InteractiveRepository
+	notifySvc NotificationService
 }
 
-func (svc *InteractiveServiceImpl) IncreaseLikeCount(ctx *gin.Context, biz string, bizId int64, uid int64) error {
-	return svc.repo.IncreaseLikeCount(ctx, biz, bizId, uid)
+// IncreaseLikeCount 真的点了赞才通知作者，重复点赞不通知
+func (svc *InteractiveServiceImpl) IncreaseLikeCount(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := svc.repo.IncreaseLikeCount(ctx, biz, bizId, uid)
+	if err != nil {
+		return err
+	}
+	if changed {
+		svc.notifySvc.NotifyLike(uid, biz, bizId)
+	}
+	return nil
+}
+
+func (svc *InteractiveServiceImpl) CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	_, err := svc.repo.DecreaseLikeCount(ctx, biz, bizId, uid)
+	return err
 }
 
 func (svc *InteractiveServiceImpl) IncreaseReadCount(ctx context.Context, biz string, bizId int64) error {
 	return svc.repo.IncreaseReadCount(ctx, biz, bizId)
 }
 
-func NewInteractiveServiceImpl() *InteractiveServiceImpl {
-	return &InteractiveServiceImpl{}
+func (svc *InteractiveServiceImpl) GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error) {
+	return svc.repo.GetByIds(ctx, biz, bizIds)
+}
+
+func NewInteractiveServiceImpl(repo repository.InteractiveRepository, notifySvc NotificationService) *InteractiveServiceImpl {
+	return &InteractiveServiceImpl{
+		repo:      repo,
+		notifySvc: notifySvc,
+	}
 }
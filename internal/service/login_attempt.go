@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository/cache"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+var ErrLoginLocked = errors.New("登录失败次数太多，已被锁定")
+
+var (
+	// accountLockPolicy 同一个账号失败 5 次锁定 1 分钟，之后每 5 次翻倍
+	accountLockPolicy = cache.LoginLockPolicy{
+		Window:    time.Hour * 24,
+		Threshold: 5,
+		Base:      time.Minute,
+		Max:       time.Hour * 24,
+	}
+	// ipLockPolicy 同一个 IP 可能尝试很多账号，阈值放宽一点，锁得更久
+	ipLockPolicy = cache.LoginLockPolicy{
+		Window:    time.Hour * 24,
+		Threshold: 20,
+		Base:      time.Minute * 5,
+		Max:       time.Hour * 24,
+	}
+)
+
+// LoginAttemptService 按账号和 IP 统计登录失败次数，防止暴力破解密码
+type LoginAttemptService interface {
+	// Check 账号或者 IP 被锁定时返回 ErrLoginLocked 和剩余的锁定时间
+	Check(ctx context.Context, account string, ip string) (time.Duration, error)
+	// Fail 记录一次登录失败
+	Fail(ctx context.Context, account string, ip string) error
+	// Succeed 登录成功之后清掉账号的失败次数，IP 的失败次数保留
+	Succeed(ctx context.Context, account string) error
+}
+
+type LoginAttemptServiceImpl struct {
+	repo   repository.LoginAttemptRepository
+	logger *zap.Logger
+}
+
+func NewLoginAttemptService(repo repository.LoginAttemptRepository, l *zap.Logger) LoginAttemptService {
+	return &LoginAttemptServiceImpl{
+		repo:   repo,
+		logger: l,
+	}
+}
+
+func (svc *LoginAttemptServiceImpl) Check(ctx context.Context, account string, ip string) (time.Duration, error) {
+	var remain time.Duration
+	for _, key := range []string{svc.accountKey(account), svc.ipKey(ip)} {
+		ttl, err := svc.repo.LockTTL(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		remain = max(remain, ttl)
+	}
+	if remain > 0 {
+		return remain, ErrLoginLocked
+	}
+	return 0, nil
+}
+
+func (svc *LoginAttemptServiceImpl) Fail(ctx context.Context, account string, ip string) error {
+	lock, err := svc.repo.IncrFail(ctx, svc.accountKey(account), accountLockPolicy)
+	if err != nil {
+		return err
+	}
+	if lock > 0 {
+		svc.logger.Warn("账号登录失败次数太多，已锁定", zap.String("account", account), zap.Duration("lock", lock))
+	}
+	lock, err = svc.repo.IncrFail(ctx, svc.ipKey(ip), ipLockPolicy)
+	if err != nil {
+		return err
+	}
+	if lock > 0 {
+		svc.logger.Warn("IP 登录失败次数太多，已锁定", zap.String("ip", ip), zap.Duration("lock", lock))
+	}
+	return nil
+}
+
+func (svc *LoginAttemptServiceImpl) Succeed(ctx context.Context, account string) error {
+	return svc.repo.Reset(ctx, svc.accountKey(account))
+}
+
+// accountKey 邮箱不区分大小写，换个大小写不能绕过锁定
+func (svc *LoginAttemptServiceImpl) accountKey(account string) string {
+	return fmt.Sprintf("account:%s", strings.ToLower(strings.TrimSpace(account)))
+}
+
+func (svc *LoginAttemptServiceImpl) ipKey(ip string) string {
+	return fmt.Sprintf("ip:%s", ip)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestLoginAttemptServiceImpl_AccountKey(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	repo := repomock.NewMockLoginAttemptRepository(ctl)
+	// 大小写和首尾空格不同的邮箱算同一个账号
+	repo.EXPECT().IncrFail(gomock.Any(), "account:test@gmail.com", accountLockPolicy).Return(time.Duration(0), nil)
+	repo.EXPECT().IncrFail(gomock.Any(), "ip:127.0.0.1", ipLockPolicy).Return(time.Duration(0), nil)
+	repo.EXPECT().LockTTL(gomock.Any(), "account:test@gmail.com").Return(time.Minute, nil)
+	repo.EXPECT().LockTTL(gomock.Any(), "ip:127.0.0.1").Return(time.Duration(0), nil)
+	repo.EXPECT().Reset(gomock.Any(), "account:test@gmail.com").Return(nil)
+
+	svc := NewLoginAttemptService(repo, zap.NewNop())
+	assert.NoError(t, svc.Fail(context.Background(), " Test@Gmail.com", "127.0.0.1"))
+	remain, err := svc.Check(context.Background(), "TEST@gmail.com ", "127.0.0.1")
+	assert.Equal(t, ErrLoginLocked, err)
+	assert.Equal(t, time.Minute, remain)
+	assert.NoError(t, svc.Succeed(context.Background(), "test@GMAIL.com"))
+}
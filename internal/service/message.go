@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrMessageSelf        = errors.New("不能给自己发私信")
+	ErrInvalidMessage     = errors.New("私信内容不合法")
+	ErrMessageTooFrequent = errors.New("发送私信太频繁")
+	// ErrBlockingPeer 自己拉黑了对方，解除之前不能给对方发私信
+	ErrBlockingPeer = errors.New("你已将对方拉黑")
+)
+
+const (
+	// maxMessageLength 私信最多的字符数
+	maxMessageLength = 500
+	// maxMessagePageSize 私信和会话列表每页最多返回的条数
+	maxMessagePageSize = 50
+)
+
+// MessageLimiter 私信发送的限流器，单独一个类型方便依赖注入
+type MessageLimiter ratelimit.Limiter
+
+// NewMessageLimiter 每个用户每分钟最多发 20 条
+func NewMessageLimiter(cmd redis.Cmdable) MessageLimiter {
+	return ratelimit.NewRedisSlidingWindowLimiter(cmd, time.Minute, 20)
+}
+
+type MessageService interface {
+	Send(ctx context.Context, m domain.Message) (int64, error)
+	// Conversations 会话列表，带上未读数和最后一条私信
+	Conversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error)
+	// Messages 和某个人的私信，maxId 为上一页最后一条的 id
+	Messages(ctx context.Context, uid, peer int64, maxId int64, limit int) ([]domain.Message, error)
+	MarkRead(ctx context.Context, uid, peer int64) error
+}
+
+type MessageServiceImpl struct {
+	repo      repository.MessageRepository
+	userRepo  repository.UserRepository
+	blockRepo repository.BlockRepository
+	pushSvc   PushService
+	limiter   MessageLimiter
+	logger    *zap.Logger
+}
+
+// messagePush 推给在线接收方的私信内容
+type messagePush struct {
+	Id      int64  `json:"id"`
+	Sender  int64  `json:"sender"`
+	Content string `json:"content"`
+	Ctime   string `json:"ctime"`
+}
+
+func NewMessageService(repo repository.MessageRepository, userRepo repository.UserRepository,
+	blockRepo repository.BlockRepository, pushSvc PushService, limiter MessageLimiter, l *zap.Logger) MessageService {
+	return &MessageServiceImpl{
+		repo:      repo,
+		userRepo:  userRepo,
+		blockRepo: blockRepo,
+		pushSvc:   pushSvc,
+		limiter:   limiter,
+		logger:    l,
+	}
+}
+
+func (svc *MessageServiceImpl) Send(ctx context.Context, m domain.Message) (int64, error) {
+	m.Content = strings.TrimSpace(m.Content)
+	if m.Content == "" || utf8.RuneCountInString(m.Content) > maxMessageLength {
+		return 0, ErrInvalidMessage
+	}
+	if m.Sender == m.Receiver {
+		return 0, ErrMessageSelf
+	}
+	limited, err := svc.limiter.Limit(ctx, fmt.Sprintf("message:send:%d", m.Sender))
+	if err != nil {
+		return 0, err
+	}
+	if limited {
+		return 0, ErrMessageTooFrequent
+	}
+	if _, err = svc.userRepo.FindById(ctx, m.Receiver); err != nil {
+		return 0, err
+	}
+	if err = svc.checkBlocked(ctx, m.Sender, m.Receiver); err != nil {
+		return 0, err
+	}
+	id, err := svc.repo.Create(ctx, m)
+	if err != nil {
+		return 0, err
+	}
+	err = svc.pushSvc.Push(ctx, m.Receiver, domain.PushTypeMessage, messagePush{
+		Id:      id,
+		Sender:  m.Sender,
+		Content: m.Content,
+		Ctime:   time.Now().Format(time.DateTime),
+	})
+	if err != nil {
+		svc.logger.Error("推送私信失败", zap.Int64("uid", m.Receiver), zap.Error(err))
+	}
+	return id, nil
+}
+
+// checkBlocked 被对方拉黑的不能发，自己拉黑了对方的也不能发，屏蔽不影响私信
+func (svc *MessageServiceImpl) checkBlocked(ctx context.Context, sender, receiver int64) error {
+	blocked, err := svc.blockRepo.IsBlocked(ctx, receiver, sender)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	blocking, err := svc.blockRepo.IsBlocked(ctx, sender, receiver)
+	if err != nil {
+		return err
+	}
+	if blocking {
+		return ErrBlockingPeer
+	}
+	return nil
+}
+
+func (svc *MessageServiceImpl) Conversations(ctx context.Context, uid int64, offset, limit int) ([]domain.Conversation, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	return svc.repo.ListConversations(ctx, uid, offset, svc.limit(limit))
+}
+
+func (svc *MessageServiceImpl) Messages(ctx context.Context, uid, peer int64, maxId int64, limit int) ([]domain.Message, error) {
+	return svc.repo.ListMessages(ctx, uid, peer, maxId, svc.limit(limit))
+}
+
+func (svc *MessageServiceImpl) MarkRead(ctx context.Context, uid, peer int64) error {
+	return svc.repo.MarkRead(ctx, uid, peer)
+}
+
+func (svc *MessageServiceImpl) limit(limit int) int {
+	if limit <= 0 || limit > maxMessagePageSize {
+		return maxMessagePageSize
+	}
+	return limit
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	svcmock "github.com/ChongYanOvO/little-blue-book/internal/service/mock"
+	limitmock "github.com/ChongYanOvO/little-blue-book/pkg/ratelimit/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+type messageDeps struct {
+	repo      repository.MessageRepository
+	userRepo  repository.UserRepository
+	blockRepo repository.BlockRepository
+	pushSvc   PushService
+	limiter   MessageLimiter
+}
+
+func newMessageDeps(ctl *gomock.Controller) messageDeps {
+	return messageDeps{
+		repo:      repomock.NewMockMessageRepository(ctl),
+		userRepo:  repomock.NewMockUserRepository(ctl),
+		blockRepo: repomock.NewMockBlockRepository(ctl),
+		pushSvc:   svcmock.NewMockPushService(ctl),
+		limiter:   limitmock.NewMockLimiter(ctl),
+	}
+}
+
+func TestMessageServiceImpl_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) messageDeps
+		msg     domain.Message
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "发送成功并推送",
+			mock: func(ctl *gomock.Controller) messageDeps {
+				d := newMessageDeps(ctl)
+				d.limiter.(*limitmock.MockLimiter).EXPECT().Limit(gomock.Any(), "message:send:1").Return(false, nil)
+				d.userRepo.(*repomock.MockUserRepository).EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				br := d.blockRepo.(*repomock.MockBlockRepository)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(2), int64(1)).Return(false, nil)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(1), int64(2)).Return(false, nil)
+				d.repo.(*repomock.MockMessageRepository).EXPECT().Create(gomock.Any(), domain.Message{
+					Sender: 1, Receiver: 2, Content: "hi",
+				}).Return(int64(10), nil)
+				d.pushSvc.(*svcmock.MockPushService).EXPECT().
+					Push(gomock.Any(), int64(2), domain.PushTypeMessage, gomock.Any()).Return(nil)
+				return d
+			},
+			msg:    domain.Message{Sender: 1, Receiver: 2, Content: " hi "},
+			wantId: 10,
+		},
+		{
+			name: "被对方拉黑",
+			mock: func(ctl *gomock.Controller) messageDeps {
+				d := newMessageDeps(ctl)
+				d.limiter.(*limitmock.MockLimiter).EXPECT().Limit(gomock.Any(), "message:send:1").Return(false, nil)
+				d.userRepo.(*repomock.MockUserRepository).EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				d.blockRepo.(*repomock.MockBlockRepository).EXPECT().IsBlocked(gomock.Any(), int64(2), int64(1)).Return(true, nil)
+				return d
+			},
+			msg:     domain.Message{Sender: 1, Receiver: 2, Content: "hi"},
+			wantErr: ErrBlocked,
+		},
+		{
+			name: "自己拉黑了对方",
+			mock: func(ctl *gomock.Controller) messageDeps {
+				d := newMessageDeps(ctl)
+				d.limiter.(*limitmock.MockLimiter).EXPECT().Limit(gomock.Any(), "message:send:1").Return(false, nil)
+				d.userRepo.(*repomock.MockUserRepository).EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				br := d.blockRepo.(*repomock.MockBlockRepository)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(2), int64(1)).Return(false, nil)
+				br.EXPECT().IsBlocked(gomock.Any(), int64(1), int64(2)).Return(true, nil)
+				return d
+			},
+			msg:     domain.Message{Sender: 1, Receiver: 2, Content: "hi"},
+			wantErr: ErrBlockingPeer,
+		},
+		{
+			name: "触发限流",
+			mock: func(ctl *gomock.Controller) messageDeps {
+				d := newMessageDeps(ctl)
+				d.limiter.(*limitmock.MockLimiter).EXPECT().Limit(gomock.Any(), "message:send:1").Return(true, nil)
+				return d
+			},
+			msg:     domain.Message{Sender: 1, Receiver: 2, Content: "hi"},
+			wantErr: ErrMessageTooFrequent,
+		},
+		{
+			name: "不能给自己发",
+			mock: func(ctl *gomock.Controller) messageDeps {
+				return messageDeps{}
+			},
+			msg:     domain.Message{Sender: 1, Receiver: 1, Content: "hi"},
+			wantErr: ErrMessageSelf,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			d := tc.mock(ctl)
+			svc := NewMessageService(d.repo, d.userRepo, d.blockRepo, d.pushSvc, d.limiter, nil)
+			id, err := svc.Send(context.Background(), tc.msg)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "github.com/ChongYanOvO/little-blue-book/internal/domain"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,17 +41,31 @@ func (m *MockCodeService) EXPECT() *MockCodeServiceMockRecorder {
 }
 
 // Send mocks base method.
-func (m *MockCodeService) Send(ctx context.Context, biz, phone string) error {
+func (m *MockCodeService) Send(ctx context.Context, biz, phone, ip string, captcha domain.CaptchaAnswer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Send", ctx, biz, phone)
+	ret := m.ctrl.Call(m, "Send", ctx, biz, phone, ip, captcha)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Send indicates an expected call of Send.
-func (mr *MockCodeServiceMockRecorder) Send(ctx, biz, phone any) *gomock.Call {
+func (mr *MockCodeServiceMockRecorder) Send(ctx, biz, phone, ip, captcha any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeService)(nil).Send), ctx, biz, phone)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeService)(nil).Send), ctx, biz, phone, ip, captcha)
+}
+
+// SendByEmail mocks base method.
+func (m *MockCodeService) SendByEmail(ctx context.Context, biz, email, ip string, captcha domain.CaptchaAnswer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendByEmail", ctx, biz, email, ip, captcha)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendByEmail indicates an expected call of SendByEmail.
+func (mr *MockCodeServiceMockRecorder) SendByEmail(ctx, biz, email, ip, captcha any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendByEmail", reflect.TypeOf((*MockCodeService)(nil).SendByEmail), ctx, biz, email, ip, captcha)
 }
 
 // Verify mocks base method.
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/push.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/service/push.go -package=mock -destination=internal/service/mock/push.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/ChongYanOvO/little-blue-book/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPushService is a mock of PushService interface.
+type MockPushService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushServiceMockRecorder
+}
+
+// MockPushServiceMockRecorder is the mock recorder for MockPushService.
+type MockPushServiceMockRecorder struct {
+	mock *MockPushService
+}
+
+// NewMockPushService creates a new mock instance.
+func NewMockPushService(ctrl *gomock.Controller) *MockPushService {
+	mock := &MockPushService{ctrl: ctrl}
+	mock.recorder = &MockPushServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushService) EXPECT() *MockPushServiceMockRecorder {
+	return m.recorder
+}
+
+// Push mocks base method.
+func (m *MockPushService) Push(ctx context.Context, uid int64, typ string, data any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", ctx, uid, typ, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockPushServiceMockRecorder) Push(ctx, uid, typ, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockPushService)(nil).Push), ctx, uid, typ, data)
+}
+
+// Register mocks base method.
+func (m *MockPushService) Register(uid int64) (<-chan domain.PushEvent, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", uid)
+	ret0, _ := ret[0].(<-chan domain.PushEvent)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockPushServiceMockRecorder) Register(uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockPushService)(nil).Register), uid)
+}
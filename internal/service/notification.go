@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// maxNotificationPageSize 通知列表每页最多返回的条数
+	maxNotificationPageSize = 50
+	// notifyTimeout 异步写通知的超时时间
+	notifyTimeout = time.Second * 5
+)
+
+// NotificationService 站内通知，Notify 开头的方法都是异步的，失败只记录日志，不影响点赞、评论这些主流程
+type NotificationService interface {
+	// NotifyLike 通知被点赞内容的作者
+	NotifyLike(actor int64, biz string, bizId int64)
+	// NotifyComment 根评论通知文章作者，回复通知被回复的人
+	NotifyComment(c domain.Comment)
+	NotifyFollow(follower, followee int64)
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error)
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	MarkAllRead(ctx context.Context, uid int64) error
+}
+
+type NotificationServiceImpl struct {
+	repo        repository.NotificationRepository
+	articleRepo repository.ArticleRepository
+	commentRepo repository.CommentRepository
+	blockRepo   repository.BlockRepository
+	pushSvc     PushService
+	logger      *zap.Logger
+}
+
+// notificationPush 推给在线用户的通知内容，客户端收到之后自己刷新未读数和列表
+type notificationPush struct {
+	Type  uint8  `json:"type"`
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	Actor int64  `json:"actor"`
+}
+
+func NewNotificationService(repo repository.NotificationRepository, articleRepo repository.ArticleRepository,
+	commentRepo repository.CommentRepository, blockRepo repository.BlockRepository,
+	pushSvc PushService, l *zap.Logger) NotificationService {
+	return &NotificationServiceImpl{
+		repo:        repo,
+		articleRepo: articleRepo,
+		commentRepo: commentRepo,
+		blockRepo:   blockRepo,
+		pushSvc:     pushSvc,
+		logger:      l,
+	}
+}
+
+func (svc *NotificationServiceImpl) NotifyLike(actor int64, biz string, bizId int64) {
+	svc.async(func(ctx context.Context) error {
+		return svc.notifyLike(ctx, actor, biz, bizId)
+	})
+}
+
+func (svc *NotificationServiceImpl) NotifyComment(c domain.Comment) {
+	svc.async(func(ctx context.Context) error {
+		return svc.notifyComment(ctx, c)
+	})
+}
+
+func (svc *NotificationServiceImpl) NotifyFollow(follower, followee int64) {
+	svc.async(func(ctx context.Context) error {
+		return svc.send(ctx, domain.Notification{
+			Uid:       followee,
+			Type:      domain.NotificationTypeFollow,
+			Biz:       domain.BizUser,
+			BizId:     followee,
+			LastActor: follower,
+		})
+	})
+}
+
+func (svc *NotificationServiceImpl) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+	return svc.repo.List(ctx, uid, offset, limit)
+}
+
+func (svc *NotificationServiceImpl) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	return svc.repo.UnreadCount(ctx, uid)
+}
+
+func (svc *NotificationServiceImpl) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	return svc.repo.MarkRead(ctx, uid, ids)
+}
+
+func (svc *NotificationServiceImpl) MarkAllRead(ctx context.Context, uid int64) error {
+	return svc.repo.MarkAllRead(ctx, uid)
+}
+
+func (svc *NotificationServiceImpl) notifyLike(ctx context.Context, actor int64, biz string, bizId int64) error {
+	var author int64
+	switch biz {
+	case domain.BizArticle:
+		a, err := svc.articleRepo.FindPublishedById(ctx, bizId)
+		if err != nil {
+			return err
+		}
+		author = a.Author.Id
+	case domain.BizComment:
+		c, err := svc.commentRepo.FindById(ctx, bizId)
+		if err != nil {
+			return err
+		}
+		author = c.Uid
+	default:
+		return nil
+	}
+	return svc.send(ctx, domain.Notification{
+		Uid:       author,
+		Type:      domain.NotificationTypeLike,
+		Biz:       biz,
+		BizId:     bizId,
+		LastActor: actor,
+	})
+}
+
+func (svc *NotificationServiceImpl) notifyComment(ctx context.Context, c domain.Comment) error {
+	if c.ParentId > 0 {
+		parent, err := svc.commentRepo.FindById(ctx, c.ParentId)
+		if err != nil {
+			return err
+		}
+		return svc.send(ctx, domain.Notification{
+			Uid:       parent.Uid,
+			Type:      domain.NotificationTypeReply,
+			Biz:       domain.BizComment,
+			BizId:     parent.Id,
+			LastActor: c.Uid,
+		})
+	}
+	if c.Biz != domain.BizArticle {
+		return nil
+	}
+	a, err := svc.articleRepo.FindPublishedById(ctx, c.BizId)
+	if err != nil {
+		return err
+	}
+	return svc.send(ctx, domain.Notification{
+		Uid:       a.Author.Id,
+		Type:      domain.NotificationTypeComment,
+		Biz:       domain.BizArticle,
+		BizId:     c.BizId,
+		LastActor: c.Uid,
+	})
+}
+
+// send 自己给自己点赞、回复不用通知，屏蔽的人的动作也不通知，实时推送失败不影响通知本身
+func (svc *NotificationServiceImpl) send(ctx context.Context, n domain.Notification) error {
+	if n.Uid == 0 || n.Uid == n.LastActor {
+		return nil
+	}
+	relations, err := svc.blockRepo.Relations(ctx, n.Uid)
+	if err != nil {
+		return err
+	}
+	if _, hidden := relations[n.LastActor]; hidden {
+		return nil
+	}
+	if err = svc.repo.Create(ctx, n); err != nil {
+		return err
+	}
+	err = svc.pushSvc.Push(ctx, n.Uid, domain.PushTypeNotification, notificationPush{
+		Type:  n.Type.ToUint8(),
+		Biz:   n.Biz,
+		BizId: n.BizId,
+		Actor: n.LastActor,
+	})
+	if err != nil {
+		svc.logger.Error("推送通知失败", zap.Int64("uid", n.Uid), zap.Error(err))
+	}
+	return nil
+}
+
+// async 调用方的 ctx 在请求结束之后会被取消，这里单独起一个
+func (svc *NotificationServiceImpl) async(fn func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			svc.logger.Error("发送通知失败", zap.Error(err))
+		}
+	}()
+}
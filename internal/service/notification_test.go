@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	svcmock "github.com/ChongYanOvO/little-blue-book/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestNotificationServiceImpl_notifyComment(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) (repository.NotificationRepository, repository.ArticleRepository, repository.CommentRepository, repository.BlockRepository, PushService)
+		comment domain.Comment
+		wantErr error
+	}{
+		{
+			name: "根评论通知文章作者",
+			mock: func(ctl *gomock.Controller) (repository.NotificationRepository, repository.ArticleRepository, repository.CommentRepository, repository.BlockRepository, PushService) {
+				nr := repomock.NewMockNotificationRepository(ctl)
+				ar := repomock.NewMockArticleRepository(ctl)
+				ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).
+					Return(domain.Article{Id: 10, Author: domain.Author{Id: 2}}, nil)
+				nr.EXPECT().Create(gomock.Any(), domain.Notification{
+					Uid: 2, Type: domain.NotificationTypeComment, Biz: domain.BizArticle, BizId: 10, LastActor: 1,
+				}).Return(nil)
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().Relations(gomock.Any(), int64(2)).Return(map[int64]domain.BlockType{}, nil)
+				ps := svcmock.NewMockPushService(ctl)
+				ps.EXPECT().Push(gomock.Any(), int64(2), domain.PushTypeNotification, gomock.Any()).Return(nil)
+				return nr, ar, repomock.NewMockCommentRepository(ctl), br, ps
+			},
+			comment: domain.Comment{Id: 100, Uid: 1, Biz: domain.BizArticle, BizId: 10},
+		},
+		{
+			name: "回复通知被回复的人",
+			mock: func(ctl *gomock.Controller) (repository.NotificationRepository, repository.ArticleRepository, repository.CommentRepository, repository.BlockRepository, PushService) {
+				nr := repomock.NewMockNotificationRepository(ctl)
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{Id: 100, Uid: 3}, nil)
+				nr.EXPECT().Create(gomock.Any(), domain.Notification{
+					Uid: 3, Type: domain.NotificationTypeReply, Biz: domain.BizComment, BizId: 100, LastActor: 1,
+				}).Return(nil)
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().Relations(gomock.Any(), int64(3)).Return(map[int64]domain.BlockType{}, nil)
+				ps := svcmock.NewMockPushService(ctl)
+				ps.EXPECT().Push(gomock.Any(), int64(3), domain.PushTypeNotification, gomock.Any()).Return(nil)
+				return nr, repomock.NewMockArticleRepository(ctl), cr, br, ps
+			},
+			comment: domain.Comment{Id: 101, Uid: 1, Biz: domain.BizArticle, BizId: 10, RootId: 100, ParentId: 100},
+		},
+		{
+			name: "回复自己不通知",
+			mock: func(ctl *gomock.Controller) (repository.NotificationRepository, repository.ArticleRepository, repository.CommentRepository, repository.BlockRepository, PushService) {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{Id: 100, Uid: 1}, nil)
+				return repomock.NewMockNotificationRepository(ctl), repomock.NewMockArticleRepository(ctl), cr,
+					repomock.NewMockBlockRepository(ctl), svcmock.NewMockPushService(ctl)
+			},
+			comment: domain.Comment{Id: 101, Uid: 1, Biz: domain.BizArticle, BizId: 10, RootId: 100, ParentId: 100},
+		},
+		{
+			name: "屏蔽的人回复不通知",
+			mock: func(ctl *gomock.Controller) (repository.NotificationRepository, repository.ArticleRepository, repository.CommentRepository, repository.BlockRepository, PushService) {
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(100)).Return(domain.Comment{Id: 100, Uid: 3}, nil)
+				br := repomock.NewMockBlockRepository(ctl)
+				br.EXPECT().Relations(gomock.Any(), int64(3)).Return(map[int64]domain.BlockType{1: domain.BlockTypeMute}, nil)
+				return repomock.NewMockNotificationRepository(ctl), repomock.NewMockArticleRepository(ctl), cr,
+					br, svcmock.NewMockPushService(ctl)
+			},
+			comment: domain.Comment{Id: 101, Uid: 1, Biz: domain.BizArticle, BizId: 10, RootId: 100, ParentId: 100},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			nr, ar, cr, br, ps := tc.mock(ctl)
+			svc := NewNotificationService(nr, ar, cr, br, ps, nil).(*NotificationServiceImpl)
+			err := svc.notifyComment(context.Background(), tc.comment)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
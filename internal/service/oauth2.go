@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/oauth2"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrOAuth2ProviderNotFound = errors.New("不支持的第三方登录方式")
+	ErrOAuth2InvalidState     = errors.New("state 无效")
+	ErrOAuth2InvalidCode      = oauth2.ErrInvalidCode
+)
+
+type OAuth2Service interface {
+	// AuthURL 生成 state 并返回第三方授权页面地址，nonce 由调用方放到浏览器的 HttpOnly cookie 里，
+	// 回调的时候带回来，state 只有发起授权的浏览器才能用
+	AuthURL(ctx context.Context, provider string) (url string, nonce string, err error)
+	// Verify 校验 state 和 nonce 之后使用授权码换取第三方用户信息
+	Verify(ctx context.Context, provider string, code string, state string, nonce string) (domain.OAuth2Info, error)
+}
+
+type OAuth2ServiceImpl struct {
+	providers map[string]oauth2.Provider
+	stateRepo repository.OAuth2StateRepository
+	logger    *zap.Logger
+}
+
+func NewOAuth2Service(providers []oauth2.Provider, stateRepo repository.OAuth2StateRepository, l *zap.Logger) OAuth2Service {
+	m := make(map[string]oauth2.Provider, len(providers))
+	for _, p := range providers {
+		m[p.Name()] = p
+	}
+	return &OAuth2ServiceImpl{
+		providers: m,
+		stateRepo: stateRepo,
+		logger:    l,
+	}
+}
+
+func (svc *OAuth2ServiceImpl) AuthURL(ctx context.Context, provider string) (string, string, error) {
+	p, ok := svc.providers[provider]
+	if !ok {
+		return "", "", ErrOAuth2ProviderNotFound
+	}
+	state, err := svc.generateState()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := svc.generateState()
+	if err != nil {
+		return "", "", err
+	}
+	if err = svc.stateRepo.Store(ctx, state, stateBinding(provider, nonce)); err != nil {
+		return "", "", err
+	}
+	url, err := p.AuthURL(ctx, state)
+	return url, nonce, err
+}
+
+func (svc *OAuth2ServiceImpl) Verify(ctx context.Context, provider string, code string, state string,
+	nonce string) (domain.OAuth2Info, error) {
+	p, ok := svc.providers[provider]
+	if !ok {
+		return domain.OAuth2Info{}, ErrOAuth2ProviderNotFound
+	}
+	// state 用过即删，同时要求和发起授权时的提供方、浏览器一致，
+	// 否则攻击者可以把自己拿到的 code 和 state 交给别人的浏览器提交，把自己的第三方账号绑到别人身上
+	stored, err := svc.stateRepo.Consume(ctx, state)
+	if errors.Is(err, repository.ErrOAuth2StateNotExist) {
+		return domain.OAuth2Info{}, ErrOAuth2InvalidState
+	}
+	if err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(stored), []byte(stateBinding(provider, nonce))) != 1 {
+		return domain.OAuth2Info{}, ErrOAuth2InvalidState
+	}
+	return p.VerifyCode(ctx, code)
+}
+
+// stateBinding state 对应的提供方和浏览器
+func stateBinding(provider string, nonce string) string {
+	return provider + ":" + nonce
+}
+
+func (svc *OAuth2ServiceImpl) generateState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
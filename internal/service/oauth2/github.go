@@ -0,0 +1,134 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubUserURL  = "https://api.github.com/user"
+)
+
+type GithubProvider struct {
+	clientId     string
+	clientSecret string
+	redirectURL  string
+	authURL      string
+	tokenURL     string
+	userURL      string
+	client       *http.Client
+}
+
+func NewGithubProvider(clientId, clientSecret, redirectURL string, client *http.Client) *GithubProvider {
+	return &GithubProvider{
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		authURL:      githubAuthURL,
+		tokenURL:     githubTokenURL,
+		userURL:      githubUserURL,
+		client:       client,
+	}
+}
+
+// Endpoints 替换默认的授权地址，主要用于 GitHub Enterprise 或者测试
+func (g *GithubProvider) Endpoints(authURL, tokenURL, userURL string) *GithubProvider {
+	g.authURL = authURL
+	g.tokenURL = tokenURL
+	g.userURL = userURL
+	return g
+}
+
+func (g *GithubProvider) Name() string {
+	return "github"
+}
+
+func (g *GithubProvider) AuthURL(ctx context.Context, state string) (string, error) {
+	query := url.Values{}
+	query.Set("client_id", g.clientId)
+	query.Set("redirect_uri", g.redirectURL)
+	query.Set("scope", "read:user user:email")
+	query.Set("state", state)
+	return g.authURL + "?" + query.Encode(), nil
+}
+
+func (g *GithubProvider) VerifyCode(ctx context.Context, code string) (domain.OAuth2Info, error) {
+	token, err := g.exchange(ctx, code)
+	if err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.userURL, nil)
+	if err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return domain.OAuth2Info{}, fmt.Errorf("获取 GitHub 用户信息失败，状态码 %d", resp.StatusCode)
+	}
+	var user struct {
+		Id    int64  `json:"id"`
+		Login string `json:"login"`
+		Email string `json:"email"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	if user.Id == 0 {
+		return domain.OAuth2Info{}, fmt.Errorf("GitHub 用户信息缺少 id")
+	}
+	return domain.OAuth2Info{
+		Provider: g.Name(),
+		OpenId:   strconv.FormatInt(user.Id, 10),
+		Name:     user.Login,
+		Email:    user.Email,
+	}, nil
+}
+
+// exchange 授权码换 access token
+func (g *GithubProvider) exchange(ctx context.Context, code string) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", g.clientId)
+	form.Set("client_secret", g.clientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", g.redirectURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	if res.Error == "bad_verification_code" {
+		return "", ErrInvalidCode
+	}
+	if res.Error != "" || res.AccessToken == "" {
+		return "", fmt.Errorf("换取 GitHub access token 失败，%s，%s", res.Error, res.ErrorDescription)
+	}
+	return res.AccessToken, nil
+}
@@ -0,0 +1,101 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeGithub 本地的 GitHub 替身，只认 code 为 good-code 的授权码
+func newFakeGithub(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+		assert.Equal(t, "client-secret", r.PostForm.Get("client_secret"))
+		if r.PostForm.Get("code") != "good-code" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token-123"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 9527, "login": "octocat", "email": "octocat@github.com"})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGithubProvider(t *testing.T) {
+	server := newFakeGithub(t)
+	defer server.Close()
+	p := NewGithubProvider("client-id", "client-secret", "http://localhost/callback", server.Client()).
+		Endpoints(server.URL+"/login/oauth/authorize", server.URL+"/login/oauth/access_token", server.URL+"/user")
+
+	authURL, err := p.AuthURL(context.Background(), "state-abc")
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state-abc", u.Query().Get("state"))
+	assert.Equal(t, "client-id", u.Query().Get("client_id"))
+
+	testCases := []struct {
+		name     string
+		code     string
+		wantInfo domain.OAuth2Info
+		wantErr  error
+	}{
+		{
+			name: "授权成功",
+			code: "good-code",
+			wantInfo: domain.OAuth2Info{
+				Provider: "github",
+				OpenId:   "9527",
+				Name:     "octocat",
+				Email:    "octocat@github.com",
+			},
+		},
+		{
+			name:    "授权码无效",
+			code:    "bad-code",
+			wantErr: ErrInvalidCode,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := p.VerifyCode(context.Background(), tc.code)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantInfo, info)
+		})
+	}
+}
+
+func TestWechatProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("code") != "good-code" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"errcode": 40029, "errmsg": "invalid code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token-123", "openid": "wx-openid"})
+	}))
+	defer server.Close()
+	p := NewWechatProvider("app-id", "app-secret", "http://localhost/callback", server.Client()).
+		Endpoints(server.URL+"/connect/qrconnect", server.URL+"/sns/oauth2/access_token")
+
+	info, err := p.VerifyCode(context.Background(), "good-code")
+	require.NoError(t, err)
+	assert.Equal(t, domain.OAuth2Info{Provider: "wechat", OpenId: "wx-openid"}, info)
+
+	_, err = p.VerifyCode(context.Background(), "bad-code")
+	assert.Equal(t, ErrInvalidCode, err)
+}
@@ -0,0 +1,18 @@
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+)
+
+var ErrInvalidCode = errors.New("授权码无效")
+
+type Provider interface {
+	// Name 提供方名称，同时也是路由中的 provider 参数
+	Name() string
+	// AuthURL 构造跳转到第三方授权页面的地址，state 用于防止 CSRF
+	AuthURL(ctx context.Context, state string) (string, error)
+	// VerifyCode 使用授权码换取第三方用户信息
+	VerifyCode(ctx context.Context, code string) (domain.OAuth2Info, error)
+}
@@ -0,0 +1,96 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"net/http"
+	"net/url"
+)
+
+const (
+	wechatAuthURL  = "https://open.weixin.qq.com/connect/qrconnect"
+	wechatTokenURL = "https://api.weixin.qq.com/sns/oauth2/access_token"
+)
+
+// 微信授权码无效或者已经使用过
+const wechatInvalidCode = 40029
+
+type WechatProvider struct {
+	appId       string
+	appSecret   string
+	redirectURL string
+	authURL     string
+	tokenURL    string
+	client      *http.Client
+}
+
+func NewWechatProvider(appId, appSecret, redirectURL string, client *http.Client) *WechatProvider {
+	return &WechatProvider{
+		appId:       appId,
+		appSecret:   appSecret,
+		redirectURL: redirectURL,
+		authURL:     wechatAuthURL,
+		tokenURL:    wechatTokenURL,
+		client:      client,
+	}
+}
+
+// Endpoints 替换默认的授权地址，主要用于测试
+func (w *WechatProvider) Endpoints(authURL, tokenURL string) *WechatProvider {
+	w.authURL = authURL
+	w.tokenURL = tokenURL
+	return w
+}
+
+func (w *WechatProvider) Name() string {
+	return "wechat"
+}
+
+func (w *WechatProvider) AuthURL(ctx context.Context, state string) (string, error) {
+	query := url.Values{}
+	query.Set("appid", w.appId)
+	query.Set("redirect_uri", w.redirectURL)
+	query.Set("response_type", "code")
+	query.Set("scope", "snsapi_login")
+	query.Set("state", state)
+	return w.authURL + "?" + query.Encode() + "#wechat_redirect", nil
+}
+
+// VerifyCode 微信换 access token 的时候直接返回了 openid，不需要再查用户信息
+func (w *WechatProvider) VerifyCode(ctx context.Context, code string) (domain.OAuth2Info, error) {
+	query := url.Values{}
+	query.Set("appid", w.appId)
+	query.Set("secret", w.appSecret)
+	query.Set("code", code)
+	query.Set("grant_type", "authorization_code")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.tokenURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		AccessToken string `json:"access_token"`
+		OpenId      string `json:"openid"`
+		ErrCode     int    `json:"errcode"`
+		ErrMsg      string `json:"errmsg"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return domain.OAuth2Info{}, err
+	}
+	if res.ErrCode == wechatInvalidCode {
+		return domain.OAuth2Info{}, ErrInvalidCode
+	}
+	if res.ErrCode != 0 || res.OpenId == "" {
+		return domain.OAuth2Info{}, fmt.Errorf("换取微信 access token 失败，%d，%s", res.ErrCode, res.ErrMsg)
+	}
+	return domain.OAuth2Info{
+		Provider: w.Name(),
+		OpenId:   res.OpenId,
+	}, nil
+}
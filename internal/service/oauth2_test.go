@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/oauth2"
+	oauth2mock "github.com/ChongYanOvO/little-blue-book/internal/service/oauth2/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestOAuth2ServiceImpl_Verify(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository)
+		provider string
+		nonce    string
+		wantInfo domain.OAuth2Info
+		wantErr  error
+	}{
+		{
+			name: "校验通过",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				p.EXPECT().VerifyCode(gomock.Any(), "code").Return(domain.OAuth2Info{Provider: "github", OpenId: "123"}, nil)
+				sr := repomock.NewMockOAuth2StateRepository(ctl)
+				sr.EXPECT().Consume(gomock.Any(), "state").Return("github:nonce", nil)
+				return p, sr
+			},
+			provider: "github",
+			nonce:    "nonce",
+			wantInfo: domain.OAuth2Info{Provider: "github", OpenId: "123"},
+		},
+		{
+			name: "不支持的提供方",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				return p, repomock.NewMockOAuth2StateRepository(ctl)
+			},
+			provider: "gitlab",
+			nonce:    "nonce",
+			wantErr:  ErrOAuth2ProviderNotFound,
+		},
+		{
+			name: "state 不存在或者已经用过",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				sr := repomock.NewMockOAuth2StateRepository(ctl)
+				sr.EXPECT().Consume(gomock.Any(), "state").Return("", repository.ErrOAuth2StateNotExist)
+				return p, sr
+			},
+			provider: "github",
+			nonce:    "nonce",
+			wantErr:  ErrOAuth2InvalidState,
+		},
+		{
+			name: "state 属于其他提供方",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				sr := repomock.NewMockOAuth2StateRepository(ctl)
+				sr.EXPECT().Consume(gomock.Any(), "state").Return("wechat:nonce", nil)
+				return p, sr
+			},
+			provider: "github",
+			nonce:    "nonce",
+			wantErr:  ErrOAuth2InvalidState,
+		},
+		{
+			name: "state 不是这个浏览器发起的",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				sr := repomock.NewMockOAuth2StateRepository(ctl)
+				sr.EXPECT().Consume(gomock.Any(), "state").Return("github:other", nil)
+				return p, sr
+			},
+			provider: "github",
+			nonce:    "nonce",
+			wantErr:  ErrOAuth2InvalidState,
+		},
+		{
+			name: "没有带 nonce",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				sr := repomock.NewMockOAuth2StateRepository(ctl)
+				sr.EXPECT().Consume(gomock.Any(), "state").Return("github:", nil)
+				return p, sr
+			},
+			provider: "github",
+			wantErr:  ErrOAuth2InvalidState,
+		},
+		{
+			name: "读取 state 失败",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				sr := repomock.NewMockOAuth2StateRepository(ctl)
+				sr.EXPECT().Consume(gomock.Any(), "state").Return("", errors.New("redis 错误"))
+				return p, sr
+			},
+			provider: "github",
+			nonce:    "nonce",
+			wantErr:  errors.New("redis 错误"),
+		},
+		{
+			name: "授权码无效",
+			mock: func(ctl *gomock.Controller) (oauth2.Provider, repository.OAuth2StateRepository) {
+				p := oauth2mock.NewMockProvider(ctl)
+				p.EXPECT().Name().Return("github").AnyTimes()
+				p.EXPECT().VerifyCode(gomock.Any(), "code").Return(domain.OAuth2Info{}, oauth2.ErrInvalidCode)
+				sr := repomock.NewMockOAuth2StateRepository(ctl)
+				sr.EXPECT().Consume(gomock.Any(), "state").Return("github:nonce", nil)
+				return p, sr
+			},
+			provider: "github",
+			nonce:    "nonce",
+			wantErr:  ErrOAuth2InvalidCode,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			p, sr := tc.mock(ctl)
+			svc := NewOAuth2Service([]oauth2.Provider{p}, sr, nil)
+			info, err := svc.Verify(context.Background(), tc.provider, "code", "state", tc.nonce)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantInfo, info)
+		})
+	}
+}
+
+func TestOAuth2ServiceImpl_AuthURL(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	p := oauth2mock.NewMockProvider(ctl)
+	p.EXPECT().Name().Return("github").AnyTimes()
+	sr := repomock.NewMockOAuth2StateRepository(ctl)
+	var stored, binding string
+	sr.EXPECT().Store(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, state string, b string) error {
+			stored, binding = state, b
+			return nil
+		})
+	p.EXPECT().AuthURL(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, state string) (string, error) {
+			// 跳转地址里的 state 必须和保存下来的一致
+			assert.Equal(t, stored, state)
+			return "https://github.com/login/oauth/authorize?state=" + state, nil
+		})
+	svc := NewOAuth2Service([]oauth2.Provider{p}, sr, nil)
+	url, nonce, err := svc.AuthURL(context.Background(), "github")
+	assert.NoError(t, err)
+	assert.Len(t, stored, 32)
+	// state 和提供方、浏览器的 nonce 绑在一起
+	assert.Len(t, nonce, 32)
+	assert.Equal(t, "github:"+nonce, binding)
+	assert.Equal(t, "https://github.com/login/oauth/authorize?state="+stored, url)
+
+	_, _, err = svc.AuthURL(context.Background(), "gitlab")
+	assert.Equal(t, ErrOAuth2ProviderNotFound, err)
+}
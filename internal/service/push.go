@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var ErrTooManyConnections = errors.New("连接数过多")
+
+const (
+	// maxPushConnsPerUser 每个用户在一个实例上最多同时保持的连接数
+	maxPushConnsPerUser = 5
+	// pushBufferSize 每个连接缓冲的事件数，客户端读得太慢的时候后面的事件直接丢掉
+	pushBufferSize = 16
+	// pushResubscribeInterval 订阅断开之后重新订阅的间隔
+	pushResubscribeInterval = time.Second * 3
+)
+
+// PushService 实时推送，连接只注册在当前实例，事件通过 redis 广播给所有实例
+type PushService interface {
+	// Push 推给用户所有在线的连接，不在线的时候什么都不做
+	Push(ctx context.Context, uid int64, typ string, data any) error
+	// Register 注册一个连接，连接断开的时候要调用返回的 cancel
+	Register(uid int64) (<-chan domain.PushEvent, func(), error)
+}
+
+type PushServiceImpl struct {
+	repo   repository.PushRepository
+	mu     sync.RWMutex
+	conns  map[int64]map[chan domain.PushEvent]struct{}
+	logger *zap.Logger
+}
+
+func NewPushService(repo repository.PushRepository, l *zap.Logger) PushService {
+	svc := &PushServiceImpl{
+		repo:   repo,
+		conns:  make(map[int64]map[chan domain.PushEvent]struct{}),
+		logger: l,
+	}
+	go svc.dispatch(context.Background())
+	return svc
+}
+
+func (svc *PushServiceImpl) Push(ctx context.Context, uid int64, typ string, data any) error {
+	val, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return svc.repo.Publish(ctx, domain.PushEvent{
+		Uid:  uid,
+		Type: typ,
+		Data: val,
+	})
+}
+
+func (svc *PushServiceImpl) Register(uid int64) (<-chan domain.PushEvent, func(), error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	conns, ok := svc.conns[uid]
+	if !ok {
+		conns = make(map[chan domain.PushEvent]struct{})
+		svc.conns[uid] = conns
+	}
+	if len(conns) >= maxPushConnsPerUser {
+		return nil, nil, ErrTooManyConnections
+	}
+	ch := make(chan domain.PushEvent, pushBufferSize)
+	conns[ch] = struct{}{}
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			svc.mu.Lock()
+			defer svc.mu.Unlock()
+			delete(svc.conns[uid], ch)
+			if len(svc.conns[uid]) == 0 {
+				delete(svc.conns, uid)
+			}
+			close(ch)
+		})
+	}, nil
+}
+
+// dispatch 把 redis 广播过来的事件分发给本实例上的连接，订阅断开之后一直重试
+func (svc *PushServiceImpl) dispatch(ctx context.Context) {
+	for {
+		events, err := svc.repo.Subscribe(ctx)
+		if err != nil {
+			svc.logger.Error("订阅推送事件失败", zap.Error(err))
+		} else {
+			for evt := range events {
+				svc.deliver(evt)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pushResubscribeInterval):
+		}
+	}
+}
+
+func (svc *PushServiceImpl) deliver(evt domain.PushEvent) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	for ch := range svc.conns[evt.Uid] {
+		select {
+		case ch <- evt:
+		default:
+			svc.logger.Warn("推送缓冲已满，丢弃事件", zap.Int64("uid", evt.Uid), zap.String("type", evt.Type))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestPushServiceImpl_Register(t *testing.T) {
+	// 不走 NewPushService，避免起订阅协程
+	svc := &PushServiceImpl{
+		conns:  make(map[int64]map[chan domain.PushEvent]struct{}),
+		logger: zap.NewNop(),
+	}
+	var cancels []func()
+	for i := 0; i < maxPushConnsPerUser; i++ {
+		_, cancel, err := svc.Register(1)
+		require.NoError(t, err)
+		cancels = append(cancels, cancel)
+	}
+	_, _, err := svc.Register(1)
+	assert.Equal(t, ErrTooManyConnections, err)
+
+	// 其他用户不受影响，事件只发给对应的用户
+	events, cancel, err := svc.Register(2)
+	require.NoError(t, err)
+	svc.deliver(domain.PushEvent{Uid: 2, Type: domain.PushTypeNotification})
+	svc.deliver(domain.PushEvent{Uid: 3, Type: domain.PushTypeNotification})
+	assert.Equal(t, domain.PushEvent{Uid: 2, Type: domain.PushTypeNotification}, <-events)
+	assert.Len(t, events, 0)
+
+	// 注销之后 channel 关闭，可以重新注册
+	cancel()
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+	cancels[0]()
+	_, _, err = svc.Register(1)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrReportNotFound  = repository.ErrReportNotFound
+	ErrReportDuplicate = repository.ErrReportDuplicate
+	ErrInvalidReport   = errors.New("举报内容不合法")
+	// ErrReportClaimed 已经被别人认领或者已经结案
+	ErrReportClaimed = errors.New("举报已被认领")
+	// ErrReportNotClaimed 只有认领的人可以处理
+	ErrReportNotClaimed = errors.New("没有认领该举报")
+	// ErrReportClaimExpired 认领太久没处理，别人可能已经可以重新认领了
+	ErrReportClaimExpired  = errors.New("认领已过期")
+	ErrInvalidReportAction = errors.New("处理方式不合法")
+)
+
+const (
+	// maxReportReasonLength 举报理由最多的字符数
+	maxReportReasonLength = 200
+	// maxReportPageSize 举报列表和审核记录每页最多返回的条数
+	maxReportPageSize = 100
+	// reportClaimExpiration 认领之后这么久没处理，别人可以重新认领
+	reportClaimExpiration = time.Minute * 30
+)
+
+type ReportService interface {
+	// Report 举报文章或者评论，同一个对象没处理之前不能重复举报
+	Report(ctx context.Context, r domain.Report) (int64, error)
+	List(ctx context.Context, status domain.ReportStatus, offset, limit int) ([]domain.Report, error)
+	Claim(ctx context.Context, id, moderator int64) error
+	// Resolve 按处理方式处置举报对象后结案，同一个对象上其他待处理的举报一起结案
+	Resolve(ctx context.Context, id, moderator int64, action domain.ReportAction, remark string) error
+	// Logs 审核记录，reportId 为 0 的时候返回所有记录
+	Logs(ctx context.Context, reportId int64, offset, limit int) ([]domain.ModerationLog, error)
+}
+
+type ReportServiceImpl struct {
+	repo        repository.ReportRepository
+	articleRepo repository.ArticleRepository
+	commentRepo repository.CommentRepository
+	logger      *zap.Logger
+}
+
+func NewReportService(repo repository.ReportRepository, articleRepo repository.ArticleRepository,
+	commentRepo repository.CommentRepository, l *zap.Logger) ReportService {
+	return &ReportServiceImpl{
+		repo:        repo,
+		articleRepo: articleRepo,
+		commentRepo: commentRepo,
+		logger:      l,
+	}
+}
+
+func (svc *ReportServiceImpl) Report(ctx context.Context, r domain.Report) (int64, error) {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" || utf8.RuneCountInString(r.Reason) > maxReportReasonLength {
+		return 0, ErrInvalidReport
+	}
+	switch r.Biz {
+	case domain.BizArticle:
+		if _, err := svc.articleRepo.FindPublishedById(ctx, r.BizId); err != nil {
+			return 0, err
+		}
+	case domain.BizComment:
+		if _, err := svc.commentRepo.FindById(ctx, r.BizId); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, ErrInvalidReport
+	}
+	return svc.repo.Create(ctx, r)
+}
+
+func (svc *ReportServiceImpl) List(ctx context.Context, status domain.ReportStatus, offset, limit int) ([]domain.Report, error) {
+	offset, limit = svc.page(offset, limit)
+	return svc.repo.List(ctx, status, offset, limit)
+}
+
+func (svc *ReportServiceImpl) Claim(ctx context.Context, id, moderator int64) error {
+	r, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	// 重复认领自己的不算错
+	if r.Status == domain.ReportStatusClaimed && r.Moderator == moderator {
+		return nil
+	}
+	ok, err := svc.repo.Claim(ctx, id, moderator, time.Now().Add(-reportClaimExpiration))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReportClaimed
+	}
+	return nil
+}
+
+func (svc *ReportServiceImpl) Resolve(ctx context.Context, id, moderator int64, action domain.ReportAction, remark string) error {
+	r, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if r.Status != domain.ReportStatusClaimed || r.Moderator != moderator {
+		return ErrReportNotClaimed
+	}
+	// 过期的认领别人可以抢走，不能再拿它去处置
+	claimedAfter := time.Now().Add(-reportClaimExpiration)
+	if r.Utime.Before(claimedAfter) {
+		return ErrReportClaimExpired
+	}
+	if !action.Valid(r.Biz) {
+		return ErrInvalidReportAction
+	}
+	// 先处置再结案，结案失败重试的时候处置是幂等的
+	if err = svc.execute(ctx, r, action); err != nil {
+		return err
+	}
+	ok, err := svc.repo.Resolve(ctx, id, moderator, action, strings.TrimSpace(remark), claimedAfter)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReportNotClaimed
+	}
+	return nil
+}
+
+func (svc *ReportServiceImpl) Logs(ctx context.Context, reportId int64, offset, limit int) ([]domain.ModerationLog, error) {
+	offset, limit = svc.page(offset, limit)
+	return svc.repo.Logs(ctx, reportId, offset, limit)
+}
+
+// execute 举报对象已经不在了就不用再处置
+func (svc *ReportServiceImpl) execute(ctx context.Context, r domain.Report, action domain.ReportAction) error {
+	switch action {
+	case domain.ReportActionHideArticle:
+		// 和管理员下架一样，作者不能再修改或者重新发布
+		a, err := svc.articleRepo.FindPublishedById(ctx, r.BizId)
+		if errors.Is(err, repository.ErrArticleNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if a.Status == domain.ArticleStatusTakenDown {
+			return nil
+		}
+		return svc.articleRepo.UpdateStatus(ctx, r.BizId, domain.ArticleStatusTakenDown)
+	case domain.ReportActionDeleteComment:
+		c, err := svc.commentRepo.FindById(ctx, r.BizId)
+		if errors.Is(err, repository.ErrCommentNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return svc.commentRepo.Delete(ctx, c)
+	}
+	return nil
+}
+
+func (svc *ReportServiceImpl) page(offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxReportPageSize {
+		limit = maxReportPageSize
+	}
+	return offset, limit
+}
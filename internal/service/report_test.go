@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/ChongYanOvO/little-blue-book/pkg/sensitive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestReportServiceImpl_Resolve(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name      string
+		mock      func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository)
+		id        int64
+		moderator int64
+		action    domain.ReportAction
+		wantErr   error
+	}{
+		{
+			name: "下架文章",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizArticle, BizId: 10, Status: domain.ReportStatusClaimed, Moderator: 7, Utime: now,
+				}, nil)
+				ar := repomock.NewMockArticleRepository(ctl)
+				ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).Return(domain.Article{Id: 10}, nil)
+				ar.EXPECT().UpdateStatus(gomock.Any(), int64(10), domain.ArticleStatusTakenDown).Return(nil)
+				rr.EXPECT().Resolve(gomock.Any(), int64(1), int64(7), domain.ReportActionHideArticle, "", gomock.Any()).Return(true, nil)
+				return rr, ar, repomock.NewMockCommentRepository(ctl)
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionHideArticle,
+		},
+		{
+			name: "文章已经被下架，不再重复下架",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizArticle, BizId: 10, Status: domain.ReportStatusClaimed, Moderator: 7, Utime: now,
+				}, nil)
+				ar := repomock.NewMockArticleRepository(ctl)
+				ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).
+					Return(domain.Article{Id: 10, Status: domain.ArticleStatusTakenDown}, nil)
+				rr.EXPECT().Resolve(gomock.Any(), int64(1), int64(7), domain.ReportActionHideArticle, "", gomock.Any()).Return(true, nil)
+				return rr, ar, repomock.NewMockCommentRepository(ctl)
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionHideArticle,
+		},
+		{
+			name: "删除评论",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizComment, BizId: 20, Status: domain.ReportStatusClaimed, Moderator: 7, Utime: now,
+				}, nil)
+				cr := repomock.NewMockCommentRepository(ctl)
+				c := domain.Comment{Id: 20, Uid: 3}
+				cr.EXPECT().FindById(gomock.Any(), int64(20)).Return(c, nil)
+				cr.EXPECT().Delete(gomock.Any(), c).Return(nil)
+				rr.EXPECT().Resolve(gomock.Any(), int64(1), int64(7), domain.ReportActionDeleteComment, "", gomock.Any()).Return(true, nil)
+				return rr, repomock.NewMockArticleRepository(ctl), cr
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionDeleteComment,
+		},
+		{
+			name: "评论已经被删除，直接结案",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizComment, BizId: 20, Status: domain.ReportStatusClaimed, Moderator: 7, Utime: now,
+				}, nil)
+				cr := repomock.NewMockCommentRepository(ctl)
+				cr.EXPECT().FindById(gomock.Any(), int64(20)).Return(domain.Comment{}, repository.ErrCommentNotFound)
+				rr.EXPECT().Resolve(gomock.Any(), int64(1), int64(7), domain.ReportActionDeleteComment, "", gomock.Any()).Return(true, nil)
+				return rr, repomock.NewMockArticleRepository(ctl), cr
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionDeleteComment,
+		},
+		{
+			name: "别人认领的举报",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizArticle, BizId: 10, Status: domain.ReportStatusClaimed, Moderator: 8,
+				}, nil)
+				return rr, repomock.NewMockArticleRepository(ctl), repomock.NewMockCommentRepository(ctl)
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionDismiss,
+			wantErr:   ErrReportNotClaimed,
+		},
+		{
+			name: "处理方式和举报对象不匹配",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizArticle, BizId: 10, Status: domain.ReportStatusClaimed, Moderator: 7, Utime: now,
+				}, nil)
+				return rr, repomock.NewMockArticleRepository(ctl), repomock.NewMockCommentRepository(ctl)
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionDeleteComment,
+			wantErr:   ErrInvalidReportAction,
+		},
+		{
+			name: "认领已经过期，不处置也不结案",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizArticle, BizId: 10, Status: domain.ReportStatusClaimed, Moderator: 7,
+					Utime: now.Add(-reportClaimExpiration - time.Minute),
+				}, nil)
+				return rr, repomock.NewMockArticleRepository(ctl), repomock.NewMockCommentRepository(ctl)
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionHideArticle,
+			wantErr:   ErrReportClaimExpired,
+		},
+		{
+			name: "认领过期被别人抢走",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id: 1, Biz: domain.BizArticle, BizId: 10, Status: domain.ReportStatusClaimed, Moderator: 7, Utime: now,
+				}, nil)
+				rr.EXPECT().Resolve(gomock.Any(), int64(1), int64(7), domain.ReportActionDismiss, "", gomock.Any()).Return(false, nil)
+				return rr, repomock.NewMockArticleRepository(ctl), repomock.NewMockCommentRepository(ctl)
+			},
+			id:        1,
+			moderator: 7,
+			action:    domain.ReportActionDismiss,
+			wantErr:   ErrReportNotClaimed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			rr, ar, cr := tc.mock(ctl)
+			svc := NewReportService(rr, ar, cr, nil)
+			err := svc.Resolve(context.Background(), tc.id, tc.moderator, tc.action, "")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestReportServiceImpl_Report(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository)
+		report  domain.Report
+		wantErr error
+	}{
+		{
+			name: "举报成功",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				ar := repomock.NewMockArticleRepository(ctl)
+				ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).Return(domain.Article{Id: 10}, nil)
+				rr := repomock.NewMockReportRepository(ctl)
+				rr.EXPECT().Create(gomock.Any(), domain.Report{
+					Reporter: 1, Biz: domain.BizArticle, BizId: 10, Reason: "广告",
+				}).Return(int64(1), nil)
+				return rr, ar, repomock.NewMockCommentRepository(ctl)
+			},
+			report: domain.Report{Reporter: 1, Biz: domain.BizArticle, BizId: 10, Reason: " 广告 "},
+		},
+		{
+			name: "理由为空",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				return repomock.NewMockReportRepository(ctl), repomock.NewMockArticleRepository(ctl), repomock.NewMockCommentRepository(ctl)
+			},
+			report:  domain.Report{Reporter: 1, Biz: domain.BizArticle, BizId: 10, Reason: "  "},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name: "不支持举报用户",
+			mock: func(ctl *gomock.Controller) (repository.ReportRepository, repository.ArticleRepository, repository.CommentRepository) {
+				return repomock.NewMockReportRepository(ctl), repomock.NewMockArticleRepository(ctl), repomock.NewMockCommentRepository(ctl)
+			},
+			report:  domain.Report{Reporter: 1, Biz: domain.BizUser, BizId: 2, Reason: "骚扰"},
+			wantErr: ErrInvalidReport,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			rr, ar, cr := tc.mock(ctl)
+			svc := NewReportService(rr, ar, cr, nil)
+			_, err := svc.Report(context.Background(), tc.report)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestReportServiceImpl_HideArticleBlocksRepublish(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	// 线上库和制作库的状态，Sync 和 dao 一样不能改被下架的文章
+	status := domain.ArticleStatusPublished
+	ar := repomock.NewMockArticleRepository(ctl)
+	ar.EXPECT().FindPublishedById(gomock.Any(), int64(10)).
+		DoAndReturn(func(ctx context.Context, id int64) (domain.Article, error) {
+			return domain.Article{Id: id, Status: status}, nil
+		})
+	ar.EXPECT().UpdateStatus(gomock.Any(), int64(10), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id int64, s domain.ArticleStates) error {
+			status = s
+			return nil
+		})
+	ar.EXPECT().Sync(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, a *domain.Article) (int64, error) {
+			if status == domain.ArticleStatusTakenDown {
+				return 0, repository.ErrArticleNotFound
+			}
+			status = a.Status
+			return a.Id, nil
+		})
+	rr := repomock.NewMockReportRepository(ctl)
+	rr.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+		Id: 1, Biz: domain.BizArticle, BizId: 10, Status: domain.ReportStatusClaimed, Moderator: 7, Utime: time.Now(),
+	}, nil)
+	rr.EXPECT().Resolve(gomock.Any(), int64(1), int64(7), domain.ReportActionHideArticle, "", gomock.Any()).Return(true, nil)
+	ur := repomock.NewMockUserRepository(ctl)
+	ur.EXPECT().FindById(gomock.Any(), int64(3)).Return(domain.User{Id: 3, Verified: true}, nil)
+
+	require.NoError(t, NewReportService(rr, ar, nil, nil).Resolve(context.Background(), 1, 7, domain.ReportActionHideArticle, ""))
+	assert.Equal(t, domain.ArticleStatusTakenDown, status)
+
+	// 作者不能再重新发布
+	ss := NewSensitiveService(sensitive.NewACFilter(nil), SensitivePolicies{}, nil, nil)
+	_, err := NewArticleService(ar, ur, nil, ss, nil).
+		Publish(context.Background(), &domain.Article{Id: 10, Title: "标题", Content: "内容", Author: domain.Author{Id: 3}})
+	assert.ErrorIs(t, err, repository.ErrArticleNotFound)
+	assert.Equal(t, domain.ArticleStatusTakenDown, status)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrRoleNotFound = errors.New("角色不存在")
+
+type RoleService interface {
+	// Roles 用户拥有的角色，签发 token 时写入
+	Roles(ctx context.Context, uid int64) ([]string, error)
+	// Grant 授予角色，用户需要重新登录才能拿到新角色
+	Grant(ctx context.Context, uid int64, role string) error
+	// Revoke 收回角色，已经签发的 token 全部失效
+	Revoke(ctx context.Context, uid int64, role string) error
+}
+
+type RoleServiceImpl struct {
+	repo        repository.RoleRepository
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	logger      *zap.Logger
+}
+
+func NewRoleService(repo repository.RoleRepository,
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	l *zap.Logger) RoleService {
+	return &RoleServiceImpl{
+		repo:        repo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		logger:      l,
+	}
+}
+
+func (svc *RoleServiceImpl) Roles(ctx context.Context, uid int64) ([]string, error) {
+	return svc.repo.FindByUid(ctx, uid)
+}
+
+func (svc *RoleServiceImpl) Grant(ctx context.Context, uid int64, role string) error {
+	if !domain.RoleExists(role) {
+		return ErrRoleNotFound
+	}
+	if _, err := svc.userRepo.FindById(ctx, uid); err != nil {
+		return err
+	}
+	if err := svc.repo.Grant(ctx, uid, role); err != nil {
+		return err
+	}
+	_, err := svc.sessionRepo.Revoke(ctx, uid)
+	return err
+}
+
+func (svc *RoleServiceImpl) Revoke(ctx context.Context, uid int64, role string) error {
+	if !domain.RoleExists(role) {
+		return ErrRoleNotFound
+	}
+	if err := svc.repo.Revoke(ctx, uid, role); err != nil {
+		return err
+	}
+	_, err := svc.sessionRepo.Revoke(ctx, uid)
+	return err
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/pkg/sensitive"
+	"go.uber.org/zap"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrSensitiveContent = errors.New("内容包含敏感词")
+
+// SensitiveError 拒绝策略下返回，带上命中的位置给前端标出来
+type SensitiveError struct {
+	Hits []domain.SensitiveHit
+}
+
+func (e *SensitiveError) Error() string {
+	return ErrSensitiveContent.Error()
+}
+
+func (e *SensitiveError) Unwrap() error {
+	return ErrSensitiveContent
+}
+
+// SensitivePolicies 每种业务命中敏感词之后的处理策略，没有配置的业务按拒绝处理
+type SensitivePolicies map[string]domain.SensitivePolicy
+
+// SensitiveField 需要检查的字段，掩码策略下会直接改 Text 指向的内容
+type SensitiveField struct {
+	Name string
+	Text *string
+}
+
+// systemReporter 系统自动提交审核时用的举报人
+const systemReporter int64 = 0
+
+// maxReviewReasonLength 提交审核时举报理由最多的字符数
+const maxReviewReasonLength = 200
+
+type SensitiveService interface {
+	// Check 按 biz 的策略检查字段，拒绝策略返回 *SensitiveError，掩码策略直接替换字段内容，
+	// 审核策略返回命中的位置，调用方在内容公开之后调用 Review
+	Check(biz string, fields ...SensitiveField) ([]domain.SensitiveHit, error)
+	// Review 以系统的身份举报，进入审核队列，同一个对象没处理之前不会重复提交
+	Review(ctx context.Context, biz string, bizId int64, hits []domain.SensitiveHit) error
+}
+
+type SensitiveServiceImpl struct {
+	filter     sensitive.Filter
+	policies   SensitivePolicies
+	reportRepo repository.ReportRepository
+	logger     *zap.Logger
+}
+
+func NewSensitiveService(filter sensitive.Filter, policies SensitivePolicies,
+	reportRepo repository.ReportRepository, l *zap.Logger) SensitiveService {
+	return &SensitiveServiceImpl{
+		filter:     filter,
+		policies:   policies,
+		reportRepo: reportRepo,
+		logger:     l,
+	}
+}
+
+func (svc *SensitiveServiceImpl) Check(biz string, fields ...SensitiveField) ([]domain.SensitiveHit, error) {
+	policy, ok := svc.policies[biz]
+	if !ok {
+		policy = domain.SensitivePolicyReject
+	}
+	var hits []domain.SensitiveHit
+	for _, f := range fields {
+		var found []sensitive.Hit
+		if policy == domain.SensitivePolicyMask {
+			*f.Text, found = svc.filter.Mask(*f.Text, '*')
+		} else {
+			found = svc.filter.Find(*f.Text)
+		}
+		for _, h := range found {
+			hits = append(hits, domain.SensitiveHit{
+				Field: f.Name,
+				Word:  h.Word,
+				Start: h.Start,
+				End:   h.End,
+			})
+		}
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+	switch policy {
+	case domain.SensitivePolicyMask:
+		return nil, nil
+	case domain.SensitivePolicyReview:
+		return hits, nil
+	default:
+		return nil, &SensitiveError{Hits: hits}
+	}
+}
+
+func (svc *SensitiveServiceImpl) Review(ctx context.Context, biz string, bizId int64, hits []domain.SensitiveHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	words := make([]string, 0, len(hits))
+	seen := make(map[string]struct{}, len(hits))
+	for _, h := range hits {
+		if _, ok := seen[h.Word]; ok {
+			continue
+		}
+		seen[h.Word] = struct{}{}
+		words = append(words, h.Word)
+	}
+	reason := "命中敏感词: " + strings.Join(words, ", ")
+	if utf8.RuneCountInString(reason) > maxReviewReasonLength {
+		reason = string([]rune(reason)[:maxReviewReasonLength])
+	}
+	_, err := svc.reportRepo.Create(ctx, domain.Report{
+		Reporter: systemReporter,
+		Biz:      biz,
+		BizId:    bizId,
+		Reason:   reason,
+	})
+	if errors.Is(err, repository.ErrReportDuplicate) {
+		return nil
+	}
+	return err
+}
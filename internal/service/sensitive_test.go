@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/ChongYanOvO/little-blue-book/pkg/sensitive"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestSensitiveServiceImpl_Check(t *testing.T) {
+	policies := SensitivePolicies{
+		domain.BizArticle: domain.SensitivePolicyMask,
+		domain.BizComment: domain.SensitivePolicyReview,
+	}
+	testCases := []struct {
+		name     string
+		biz      string
+		text     string
+		wantText string
+		wantHits []domain.SensitiveHit
+		wantErr  error
+	}{
+		{
+			name:     "没有命中",
+			biz:      domain.BizArticle,
+			text:     "你好",
+			wantText: "你好",
+		},
+		{
+			name:     "掩码",
+			biz:      domain.BizArticle,
+			text:     "来赌博吧",
+			wantText: "来**吧",
+		},
+		{
+			name:     "审核策略原样保存并返回位置",
+			biz:      domain.BizComment,
+			text:     "来赌博吧",
+			wantText: "来赌博吧",
+			wantHits: []domain.SensitiveHit{{Field: "content", Word: "赌博", Start: 1, End: 3}},
+		},
+		{
+			name:     "没有配置的业务拒绝",
+			biz:      domain.BizUser,
+			text:     "来赌博吧",
+			wantText: "来赌博吧",
+			wantErr: &SensitiveError{
+				Hits: []domain.SensitiveHit{{Field: "content", Word: "赌博", Start: 1, End: 3}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewSensitiveService(sensitive.NewACFilter([]string{"赌博"}), policies, nil, nil)
+			text := tc.text
+			hits, err := svc.Check(tc.biz, SensitiveField{Name: "content", Text: &text})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantHits, hits)
+			assert.Equal(t, tc.wantText, text)
+		})
+	}
+}
+
+func TestSensitiveServiceImpl_Review(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	rr := repomock.NewMockReportRepository(ctl)
+	rr.EXPECT().Create(gomock.Any(), domain.Report{
+		Biz: domain.BizComment, BizId: 1, Reason: "命中敏感词: 赌博",
+	}).Return(int64(0), repository.ErrReportDuplicate)
+	svc := NewSensitiveService(sensitive.NewACFilter(nil), nil, rr, nil)
+	// 重复提交不算错
+	err := svc.Review(context.Background(), domain.BizComment, 1, []domain.SensitiveHit{
+		{Field: "content", Word: "赌博", Start: 0, End: 2},
+		{Field: "content", Word: "赌博", Start: 5, End: 7},
+	})
+	assert.NoError(t, err)
+}
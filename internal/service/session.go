@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrSessionRevoked = errors.New("会话已失效")
+
+type SessionService interface {
+	// Version 获取用户当前的会话版本，签发 token 时使用
+	Version(ctx context.Context, uid int64) (int64, error)
+	// Revoke 作废用户已签发的所有 token，返回新的会话版本
+	Revoke(ctx context.Context, uid int64) (int64, error)
+	// Check 校验 token 携带的会话版本是否仍然有效，封禁用户的时候会话已经作废了
+	Check(ctx context.Context, uid int64, version int64) error
+}
+
+type SessionServiceImpl struct {
+	repo   repository.SessionRepository
+	logger *zap.Logger
+}
+
+func NewSessionService(repo repository.SessionRepository, l *zap.Logger) SessionService {
+	return &SessionServiceImpl{
+		repo:   repo,
+		logger: l,
+	}
+}
+
+func (svc *SessionServiceImpl) Version(ctx context.Context, uid int64) (int64, error) {
+	return svc.repo.Version(ctx, uid)
+}
+
+func (svc *SessionServiceImpl) Revoke(ctx context.Context, uid int64) (int64, error) {
+	return svc.repo.Revoke(ctx, uid)
+}
+
+func (svc *SessionServiceImpl) Check(ctx context.Context, uid int64, version int64) error {
+	current, err := svc.repo.Version(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if current != version {
+		return ErrSessionRevoked
+	}
+	return nil
+}
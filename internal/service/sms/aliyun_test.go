@@ -0,0 +1,89 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 阿里云文档里的签名示例
+func TestAliyunSign(t *testing.T) {
+	params := map[string]string{
+		"AccessKeyId":      "testId",
+		"Action":           "SendSms",
+		"Format":           "XML",
+		"OutId":            "123",
+		"PhoneNumbers":     "15300000001",
+		"RegionId":         "cn-hangzhou",
+		"SignName":         "阿里云短信测试专用",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   "45e25e9b-0a6f-4070-8c85-2956eda1b466",
+		"SignatureVersion": "1.0",
+		"TemplateCode":     "SMS_71390007",
+		"TemplateParam":    `{"customer":"test"}`,
+		"Timestamp":        "2017-07-12T02:42:19Z",
+		"Version":          "2017-05-25",
+	}
+	sign := aliyunSign(http.MethodGet, aliyunCanonicalize(params), "testSecret")
+	assert.Equal(t, "zJDF+Lrzhj/ThnlvIToysFRq6t4=", sign)
+}
+
+func TestAliyunSmsService_Send(t *testing.T) {
+	registry := NewTemplateRegistry(map[string]Template{
+		TemplateLoginCode: {
+			Params: 1,
+			Providers: map[string]ProviderTemplate{
+				ProviderAliyun: {Id: "SMS_1", ParamNames: []string{"code"}},
+			},
+		},
+	})
+	testCases := []struct {
+		name    string
+		resp    aliyunResponse
+		wantErr bool
+	}{
+		{
+			name: "发送成功",
+			resp: aliyunResponse{Code: "OK", Message: "OK"},
+		},
+		{
+			name:    "服务商返回错误",
+			resp:    aliyunResponse{Code: "isv.BUSINESS_LIMIT_CONTROL", Message: "触发流控"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				params := make(map[string]string, len(query))
+				for k := range query {
+					if k != "Signature" {
+						params[k] = query.Get(k)
+					}
+				}
+				// 服务端用同样的方式算一遍签名
+				assert.Equal(t, aliyunSign(http.MethodGet, aliyunCanonicalize(params), "secret"), query.Get("Signature"))
+				assert.Equal(t, "key", params["AccessKeyId"])
+				assert.Equal(t, "SMS_1", params["TemplateCode"])
+				assert.Equal(t, "小蓝书", params["SignName"])
+				assert.Equal(t, "13800000000,13900000000", params["PhoneNumbers"])
+				assert.JSONEq(t, `{"code":"123456"}`, params["TemplateParam"])
+				_ = json.NewEncoder(w).Encode(tc.resp)
+			}))
+			defer server.Close()
+
+			svc := NewAliyunSmsService(server.Client(), server.URL, "key", "secret", "cn-hangzhou", "小蓝书", registry)
+			err := svc.Send(context.Background(), TemplateLoginCode, []string{"123456"}, "13800000000", "13900000000")
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
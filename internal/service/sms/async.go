@@ -0,0 +1,152 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// asyncPollInterval 后台检查到期任务的间隔
+	asyncPollInterval = time.Second * 5
+	// asyncBatchSize 每次最多抢占的任务数
+	asyncBatchSize = 20
+	// asyncSendTimeout 重试时每条短信的超时时间
+	asyncSendTimeout = time.Second * 5
+	// asyncStaleTimeout 抢占之后这么久还没有结果，认为抢占的实例挂了
+	asyncStaleTimeout = time.Minute
+	asyncBaseBackoff  = time.Second * 10
+	asyncMaxBackoff   = time.Minute * 10
+	// asyncTaskTTL 验证码 10 分钟过期，超过这个时间的任务发出去也用不了
+	asyncTaskTTL = time.Minute * 10
+)
+
+// AsyncService 服务商发送失败或者被限流的时候把短信落库，后台按指数退避重试，
+// 调用方拿到的是成功，短信会晚一点到
+type AsyncService struct {
+	svc         SmsService
+	repo        repository.SmsTaskRepository
+	maxAttempts int
+	logger      *zap.Logger
+}
+
+// NewAsyncService maxAttempts 包括第一次同步发送，返回的函数用来停止后台重试
+func NewAsyncService(svc SmsService, repo repository.SmsTaskRepository, maxAttempts int, l *zap.Logger) (SmsService, func()) {
+	s := &AsyncService{
+		svc:         svc,
+		repo:        repo,
+		maxAttempts: maxAttempts,
+		logger:      l,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.loop(ctx)
+	}()
+	// 等正在处理的这一批结束，不然任务会停在发送中，要等抢占超时才能被重新处理
+	return s, func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *AsyncService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	err := s.svc.Send(ctx, tpl, args, numbers...)
+	// 模板有问题重试也没用，超时的时候服务商可能已经发出去了，重试会让用户收到两条
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || isTemplateErr(err) {
+		return err
+	}
+	s.logger.Warn("发送短信失败，转为异步重试", zap.Error(err))
+	// 请求的 ctx 可能已经超时了，落库单独用一个
+	storeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, er := s.repo.Create(storeCtx, domain.SmsTask{
+		Tpl:       tpl,
+		Args:      args,
+		Numbers:   numbers,
+		Attempts:  1,
+		NextTime:  time.Now().Add(s.backoff(1)),
+		LastError: err.Error(),
+	})
+	if er != nil {
+		s.logger.Error("短信重试任务落库失败", zap.Error(er))
+		return err
+	}
+	return nil
+}
+
+func (s *AsyncService) loop(ctx context.Context) {
+	ticker := time.NewTicker(asyncPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retry(ctx)
+		}
+	}
+}
+
+// retry 处理一批到期的任务
+func (s *AsyncService) retry(ctx context.Context) {
+	tasks, err := s.repo.Preempt(ctx, time.Now().Add(-asyncStaleTimeout), asyncBatchSize)
+	if err != nil {
+		s.logger.Error("抢占短信重试任务失败", zap.Error(err))
+	}
+	for _, t := range tasks {
+		if time.Since(t.Ctime) > asyncTaskTTL {
+			t.LastError = "验证码已经过期，不再重试"
+			t.Status = domain.SmsTaskStatusFailed
+			if er := s.repo.MarkFailed(ctx, t); er != nil {
+				s.logger.Error("更新短信任务状态失败", zap.Int64("id", t.Id), zap.Error(er))
+			}
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, asyncSendTimeout)
+		err = s.svc.Send(sendCtx, t.Tpl, t.Args, t.Numbers...)
+		cancel()
+		if err == nil {
+			if er := s.repo.MarkSuccess(ctx, t.Id); er != nil {
+				s.logger.Error("更新短信任务状态失败", zap.Int64("id", t.Id), zap.Error(er))
+			}
+			continue
+		}
+		t.Attempts++
+		t.LastError = err.Error()
+		t.Status = domain.SmsTaskStatusWaiting
+		t.NextTime = time.Now().Add(s.backoff(t.Attempts))
+		switch {
+		case isTemplateErr(err):
+			// 模板配置有问题，重试也没用
+			t.Status = domain.SmsTaskStatusFailed
+			s.logger.Error("短信模板不可用，不再重试", zap.Int64("id", t.Id), zap.Error(err))
+		case errors.Is(err, context.DeadlineExceeded):
+			// 不知道服务商有没有发出去，宁可少发也不重复发
+			t.Status = domain.SmsTaskStatusFailed
+			s.logger.Error("发送短信超时，不再重试", zap.Int64("id", t.Id), zap.Error(err))
+		case t.Attempts >= s.maxAttempts:
+			t.Status = domain.SmsTaskStatusFailed
+			s.logger.Error("短信重试次数用完", zap.Int64("id", t.Id), zap.Error(err))
+		}
+		if er := s.repo.MarkFailed(ctx, t); er != nil {
+			s.logger.Error("更新短信任务状态失败", zap.Int64("id", t.Id), zap.Error(er))
+		}
+	}
+}
+
+// backoff 第 n 次失败之后等待的时间，10s 20s 40s ... 最多 10 分钟
+func (s *AsyncService) backoff(attempts int) time.Duration {
+	d := asyncBaseBackoff
+	for i := 1; i < attempts && d < asyncMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > asyncMaxBackoff {
+		d = asyncMaxBackoff
+	}
+	return d
+}
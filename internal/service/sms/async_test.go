@@ -0,0 +1,179 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	smsmock "github.com/ChongYanOvO/little-blue-book/internal/service/sms/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestAsyncService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctl *gomock.Controller) (SmsService, repository.SmsTaskRepository)
+		wantErr error
+	}{
+		{
+			name: "直接发送成功",
+			mock: func(ctl *gomock.Controller) (SmsService, repository.SmsTaskRepository) {
+				svc := smsmock.NewMockSmsService(ctl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "10086").Return(nil)
+				return svc, repomock.NewMockSmsTaskRepository(ctl)
+			},
+		},
+		{
+			name: "发送失败转为异步重试",
+			mock: func(ctl *gomock.Controller) (SmsService, repository.SmsTaskRepository) {
+				svc := smsmock.NewMockSmsService(ctl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "10086").Return(errLimit)
+				repo := repomock.NewMockSmsTaskRepository(ctl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, task domain.SmsTask) (int64, error) {
+						assert.Equal(t, "tpl", task.Tpl)
+						assert.Equal(t, []string{"10086"}, task.Numbers)
+						assert.Equal(t, 1, task.Attempts)
+						assert.Equal(t, errLimit.Error(), task.LastError)
+						return 1, nil
+					})
+				return svc, repo
+			},
+		},
+		{
+			name: "落库也失败返回原来的错误",
+			mock: func(ctl *gomock.Controller) (SmsService, repository.SmsTaskRepository) {
+				svc := smsmock.NewMockSmsService(ctl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "10086").Return(errLimit)
+				repo := repomock.NewMockSmsTaskRepository(ctl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db error"))
+				return svc, repo
+			},
+			wantErr: errLimit,
+		},
+		{
+			name: "超时不转异步，避免重复发送",
+			mock: func(ctl *gomock.Controller) (SmsService, repository.SmsTaskRepository) {
+				svc := smsmock.NewMockSmsService(ctl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "10086").Return(context.DeadlineExceeded)
+				return svc, repomock.NewMockSmsTaskRepository(ctl)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc, repo := tc.mock(ctl)
+			s := &AsyncService{svc: svc, repo: repo, maxAttempts: 3, logger: zap.NewNop()}
+			err := s.Send(context.Background(), "tpl", []string{"123456"}, "10086")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestAsyncService_retry(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	svc := smsmock.NewMockSmsService(ctl)
+	repo := repomock.NewMockSmsTaskRepository(ctl)
+	now := time.Now()
+	repo.EXPECT().Preempt(gomock.Any(), gomock.Any(), asyncBatchSize).Return([]domain.SmsTask{
+		{Id: 1, Tpl: "tpl", Args: []string{"1"}, Numbers: []string{"a"}, Attempts: 1, Ctime: now},
+		{Id: 2, Tpl: "tpl", Args: []string{"2"}, Numbers: []string{"b"}, Attempts: 1, Ctime: now},
+		{Id: 3, Tpl: "tpl", Args: []string{"3"}, Numbers: []string{"c"}, Attempts: 2, Ctime: now},
+	}, nil)
+	svc.EXPECT().Send(gomock.Any(), "tpl", []string{"1"}, "a").Return(nil)
+	svc.EXPECT().Send(gomock.Any(), "tpl", []string{"2"}, "b").Return(errLimit)
+	svc.EXPECT().Send(gomock.Any(), "tpl", []string{"3"}, "c").Return(errLimit)
+	repo.EXPECT().MarkSuccess(gomock.Any(), int64(1)).Return(nil)
+	repo.EXPECT().MarkFailed(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, task domain.SmsTask) error {
+		assert.Equal(t, int64(2), task.Id)
+		assert.Equal(t, 2, task.Attempts)
+		assert.Equal(t, domain.SmsTaskStatusWaiting, task.Status)
+		assert.True(t, task.NextTime.After(time.Now().Add(asyncBaseBackoff)))
+		return nil
+	})
+	repo.EXPECT().MarkFailed(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, task domain.SmsTask) error {
+		// 第三次失败，次数用完
+		assert.Equal(t, int64(3), task.Id)
+		assert.Equal(t, domain.SmsTaskStatusFailed, task.Status)
+		return nil
+	})
+	s := &AsyncService{svc: svc, repo: repo, maxAttempts: 3, logger: zap.NewNop()}
+	s.retry(context.Background())
+}
+
+func TestAsyncService_retryGiveUp(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	svc := smsmock.NewMockSmsService(ctl)
+	repo := repomock.NewMockSmsTaskRepository(ctl)
+	repo.EXPECT().Preempt(gomock.Any(), gomock.Any(), asyncBatchSize).Return([]domain.SmsTask{
+		{Id: 1, Tpl: "tpl", Args: []string{"1"}, Numbers: []string{"a"}, Attempts: 1, Ctime: time.Now().Add(-asyncTaskTTL - time.Second)},
+		{Id: 2, Tpl: "tpl", Args: []string{"2"}, Numbers: []string{"b"}, Attempts: 1, Ctime: time.Now()},
+	}, nil)
+	// 过期的任务不再发送，模板出错的任务不再重试
+	svc.EXPECT().Send(gomock.Any(), "tpl", []string{"2"}, "b").Return(ErrTemplateParams)
+	repo.EXPECT().MarkFailed(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, task domain.SmsTask) error {
+		assert.Equal(t, int64(1), task.Id)
+		assert.Equal(t, 1, task.Attempts)
+		assert.Equal(t, domain.SmsTaskStatusFailed, task.Status)
+		return nil
+	})
+	repo.EXPECT().MarkFailed(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, task domain.SmsTask) error {
+		assert.Equal(t, int64(2), task.Id)
+		assert.Equal(t, domain.SmsTaskStatusFailed, task.Status)
+		return nil
+	})
+	s := &AsyncService{svc: svc, repo: repo, maxAttempts: 5, logger: zap.NewNop()}
+	s.retry(context.Background())
+}
+
+func TestAsyncService_retryTimeout(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	svc := smsmock.NewMockSmsService(ctl)
+	repo := repomock.NewMockSmsTaskRepository(ctl)
+	repo.EXPECT().Preempt(gomock.Any(), gomock.Any(), asyncBatchSize).Return([]domain.SmsTask{
+		{Id: 1, Tpl: "tpl", Args: []string{"1"}, Numbers: []string{"a"}, Attempts: 1, Ctime: time.Now()},
+	}, nil)
+	// 超时的时候服务商可能已经发出去了，不再重试
+	svc.EXPECT().Send(gomock.Any(), "tpl", []string{"1"}, "a").Return(context.DeadlineExceeded)
+	repo.EXPECT().MarkFailed(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, task domain.SmsTask) error {
+		assert.Equal(t, int64(1), task.Id)
+		assert.Equal(t, domain.SmsTaskStatusFailed, task.Status)
+		return nil
+	})
+	s := &AsyncService{svc: svc, repo: repo, maxAttempts: 5, logger: zap.NewNop()}
+	s.retry(context.Background())
+}
+
+func TestNewAsyncService_stop(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	_, stop := NewAsyncService(smsmock.NewMockSmsService(ctl), repomock.NewMockSmsTaskRepository(ctl), 3, zap.NewNop())
+	done := make(chan struct{})
+	go func() {
+		stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("后台重试没有停下来")
+	}
+}
+
+func TestAsyncService_backoff(t *testing.T) {
+	s := &AsyncService{}
+	assert.Equal(t, asyncBaseBackoff, s.backoff(1))
+	assert.Equal(t, asyncBaseBackoff*4, s.backoff(3))
+	assert.Equal(t, asyncMaxBackoff, s.backoff(20))
+}
@@ -0,0 +1,69 @@
+package sms
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+// AuditService 记录每一次调用服务商的结果，一个号码一条，包在具体的服务商外面，
+// 熔断切换和异步重试的每一次尝试都会留下记录
+type AuditService struct {
+	svc      SmsService
+	provider string
+	repo     repository.SmsRecordRepository
+	logger   *zap.Logger
+}
+
+func NewAuditService(svc SmsService, provider string, repo repository.SmsRecordRepository, l *zap.Logger) SmsService {
+	return &AuditService{
+		svc:      svc,
+		provider: provider,
+		repo:     repo,
+		logger:   l,
+	}
+}
+
+func (s *AuditService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	start := time.Now()
+	var ids []string
+	var err error
+	if sender, ok := s.svc.(RequestIdSender); ok {
+		ids, err = sender.SendWithRequestIds(ctx, tpl, args, numbers...)
+	} else {
+		err = s.svc.Send(ctx, tpl, args, numbers...)
+	}
+	// 模板的问题没有真正调用服务商，不记录
+	if isTemplateErr(err) {
+		return err
+	}
+	latency := time.Since(start)
+	records := make([]domain.SmsRecord, 0, len(numbers))
+	for i, number := range numbers {
+		r := domain.SmsRecord{
+			Provider:  s.provider,
+			Tpl:       tpl,
+			Phone:     domain.MaskPhone(number),
+			PhoneHash: domain.HashPhone(number),
+			Latency:   latency,
+			Status:    domain.SmsRecordStatusSent,
+		}
+		if i < len(ids) {
+			r.RequestId = ids[i]
+		}
+		if err != nil {
+			r.Status = domain.SmsRecordStatusFailed
+			r.Error = err.Error()
+		}
+		records = append(records, r)
+	}
+	// 请求的 ctx 可能已经超时了，落库单独用一个，记录失败不影响发送结果
+	storeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if er := s.repo.Create(storeCtx, records); er != nil {
+		s.logger.Error("短信发送记录落库失败", zap.String("provider", s.provider), zap.Error(er))
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	smsmock "github.com/ChongYanOvO/little-blue-book/internal/service/sms/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+// requestIdSmsService 返回固定请求 id 的服务商
+type requestIdSmsService struct {
+	ids []string
+	err error
+}
+
+func (s *requestIdSmsService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	_, err := s.SendWithRequestIds(ctx, tpl, args, numbers...)
+	return err
+}
+
+func (s *requestIdSmsService) SendWithRequestIds(ctx context.Context, tpl string, args []string, numbers ...string) ([]string, error) {
+	return s.ids, s.err
+}
+
+func TestAuditService_Send(t *testing.T) {
+	errProvider := errors.New("provider error")
+	testCases := []struct {
+		name        string
+		mock        func(ctl *gomock.Controller) (SmsService, repository.SmsRecordRepository)
+		numbers     []string
+		wantErr     error
+		wantRecords []domain.SmsRecord
+	}{
+		{
+			name: "每个号码一条记录，带上请求 id",
+			mock: func(ctl *gomock.Controller) (SmsService, repository.SmsRecordRepository) {
+				return &requestIdSmsService{ids: []string{"sid-1", "sid-2"}}, repomock.NewMockSmsRecordRepository(ctl)
+			},
+			numbers: []string{"13800001111", "+8613900002222"},
+			wantRecords: []domain.SmsRecord{
+				{
+					Provider:  "test",
+					Tpl:       TemplateLoginCode,
+					Phone:     "138****1111",
+					PhoneHash: domain.HashPhone("13800001111"),
+					RequestId: "sid-1",
+					Status:    domain.SmsRecordStatusSent,
+				},
+				{
+					Provider:  "test",
+					Tpl:       TemplateLoginCode,
+					Phone:     "+86*******2222",
+					PhoneHash: domain.HashPhone("13900002222"),
+					RequestId: "sid-2",
+					Status:    domain.SmsRecordStatusSent,
+				},
+			},
+		},
+		{
+			name: "发送失败记录原因",
+			mock: func(ctl *gomock.Controller) (SmsService, repository.SmsRecordRepository) {
+				svc := smsmock.NewMockSmsService(ctl)
+				svc.EXPECT().Send(gomock.Any(), TemplateLoginCode, []string{"123456"}, "13800001111").Return(errProvider)
+				return svc, repomock.NewMockSmsRecordRepository(ctl)
+			},
+			numbers: []string{"13800001111"},
+			wantErr: errProvider,
+			wantRecords: []domain.SmsRecord{
+				{
+					Provider:  "test",
+					Tpl:       TemplateLoginCode,
+					Phone:     "138****1111",
+					PhoneHash: domain.HashPhone("13800001111"),
+					Status:    domain.SmsRecordStatusFailed,
+					Error:     errProvider.Error(),
+				},
+			},
+		},
+		{
+			name: "模板错误不记录",
+			mock: func(ctl *gomock.Controller) (SmsService, repository.SmsRecordRepository) {
+				return &requestIdSmsService{err: ErrTemplateUnsupported}, repomock.NewMockSmsRecordRepository(ctl)
+			},
+			numbers: []string{"13800001111"},
+			wantErr: ErrTemplateUnsupported,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc, repo := tc.mock(ctl)
+			if tc.wantRecords != nil {
+				repo.(*repomock.MockSmsRecordRepository).EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, records []domain.SmsRecord) error {
+						for i := range records {
+							assert.GreaterOrEqual(t, records[i].Latency.Nanoseconds(), int64(0))
+							records[i].Latency = 0
+						}
+						assert.Equal(t, tc.wantRecords, records)
+						return errors.New("db error")
+					})
+			}
+			s := NewAuditService(svc, "test", repo, zap.NewNop())
+			err := s.Send(context.Background(), TemplateLoginCode, []string{"123456"}, tc.numbers...)
+			// 记录落库失败不影响发送结果
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package sms
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState uint8
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	// breakerHalfOpen 熔断时间到了，放一个请求过去探测
+	breakerHalfOpen
+)
+
+// BreakerConfig 熔断的条件，最近 Window 次请求里失败或者慢请求的比例超过阈值就熔断
+type BreakerConfig struct {
+	// Window 统计最近多少次请求
+	Window int
+	// MinRequests 请求数不够的时候不熔断，避免刚启动的时候一次失败就熔断
+	MinRequests int
+	// ErrorRate 失败比例
+	ErrorRate float64
+	// SlowThreshold 超过这个时间的请求算慢请求
+	SlowThreshold time.Duration
+	// SlowRate 慢请求比例
+	SlowRate float64
+	// OpenTimeout 熔断之后多久进入半开状态
+	OpenTimeout time.Duration
+}
+
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:        20,
+		MinRequests:   5,
+		ErrorRate:     0.5,
+		SlowThreshold: time.Second * 2,
+		SlowRate:      0.5,
+		OpenTimeout:   time.Second * 30,
+	}
+}
+
+// breaker 单个服务商的熔断器，用环形数组记录最近的请求结果
+type breaker struct {
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	state    breakerState
+	openedAt time.Time
+	// probing 半开状态下已经放了一个请求过去，结果回来之前其他请求不放
+	probing bool
+	results []callResult
+	next    int
+	count   int
+	now     func() time.Time
+}
+
+type callResult struct {
+	failed bool
+	slow   bool
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	return &breaker{
+		cfg:     cfg,
+		results: make([]callResult, cfg.Window),
+		now:     time.Now,
+	}
+}
+
+// allow 是否可以把请求发给这个服务商
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerClosed:
+		return true
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true
+	default:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+}
+
+// record 记录一次请求的结果，半开状态下探测成功就恢复，失败就重新熔断
+func (b *breaker) record(err error, cost time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := callResult{failed: err != nil, slow: cost >= b.cfg.SlowThreshold}
+	if b.state == breakerHalfOpen {
+		b.probing = false
+		if res.failed || res.slow {
+			b.open()
+			return
+		}
+		b.state = breakerClosed
+		b.count, b.next = 0, 0
+	}
+	b.results[b.next] = res
+	b.next = (b.next + 1) % len(b.results)
+	if b.count < len(b.results) {
+		b.count++
+	}
+	if b.state == breakerClosed && b.shouldOpen() {
+		b.open()
+	}
+}
+
+// release 请求没有结果可以统计的时候把探测的名额还回去，不然半开状态会一直不放请求
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		b.probing = false
+	}
+}
+
+func (b *breaker) shouldOpen() bool {
+	if b.count < b.cfg.MinRequests {
+		return false
+	}
+	var failed, slow int
+	for i := 0; i < b.count; i++ {
+		if b.results[i].failed {
+			failed++
+		}
+		if b.results[i].slow {
+			slow++
+		}
+	}
+	return float64(failed)/float64(b.count) >= b.cfg.ErrorRate ||
+		float64(slow)/float64(b.count) >= b.cfg.SlowRate
+}
+
+func (b *breaker) open() {
+	b.state = breakerOpen
+	b.openedAt = b.now()
+	b.count, b.next = 0, 0
+}
@@ -0,0 +1,77 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+// CircuitBreakerService 按错误率和响应时间给每个服务商熔断，
+// 一直用当前的服务商，失败或者被熔断的时候自动切换到下一个可用的
+type CircuitBreakerService struct {
+	svcs     []SmsService
+	breakers []*breaker
+	// current 当前优先使用的服务商
+	current atomic.Int64
+	logger  *zap.Logger
+}
+
+func NewCircuitBreakerService(svcs []SmsService, cfg BreakerConfig, l *zap.Logger) SmsService {
+	if cfg.Window <= 0 {
+		cfg = DefaultBreakerConfig()
+	}
+	breakers := make([]*breaker, len(svcs))
+	for i := range svcs {
+		breakers[i] = newBreaker(cfg)
+	}
+	return &CircuitBreakerService{
+		svcs:     svcs,
+		breakers: breakers,
+		logger:   l,
+	}
+}
+
+func (s *CircuitBreakerService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	length := int64(len(s.svcs))
+	start := s.current.Load()
+	// unsupported 没有配置这个模板的服务商个数，全都没配置的时候重试也没用
+	var unsupported int64
+	for i := start; i < start+length; i++ {
+		idx := i % length
+		if !s.breakers[idx].allow() {
+			continue
+		}
+		begin := time.Now()
+		err := s.svcs[idx].Send(ctx, tpl, args, numbers...)
+		// 调用方取消或者超时不是服务商的问题，不计入统计，也不再换下一个
+		if err != nil && ctx.Err() != nil {
+			s.breakers[idx].release()
+			return err
+		}
+		// 模板不存在或者参数不对，换哪个服务商都一样
+		if errors.Is(err, ErrTemplateNotFound) || errors.Is(err, ErrTemplateParams) {
+			s.breakers[idx].release()
+			return err
+		}
+		// 这个服务商没有配置模板，不算服务商的问题
+		if errors.Is(err, ErrTemplateUnsupported) {
+			s.breakers[idx].release()
+			unsupported++
+			continue
+		}
+		s.breakers[idx].record(err, time.Since(begin))
+		if err == nil {
+			if idx != start && s.current.CompareAndSwap(start, idx) {
+				s.logger.Warn("切换短信服务商", zap.Int64("from", start), zap.Int64("to", idx))
+			}
+			return nil
+		}
+		s.logger.Error("短信服务商发送失败", zap.Int64("provider", idx), zap.Error(err))
+	}
+	if length > 0 && unsupported == length {
+		return ErrTemplateUnsupported
+	}
+	return ErrAllProvidersFailed
+}
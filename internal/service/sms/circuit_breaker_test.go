@@ -0,0 +1,193 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeSmsService 按设定的错误和耗时返回，记录被调用的次数
+type fakeSmsService struct {
+	err   atomic.Value
+	delay time.Duration
+	calls atomic.Int64
+}
+
+func newFakeSmsService(err error, delay time.Duration) *fakeSmsService {
+	f := &fakeSmsService{delay: delay}
+	f.setErr(err)
+	return f
+}
+
+func (f *fakeSmsService) setErr(err error) {
+	f.err.Store(&err)
+}
+
+func (f *fakeSmsService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	f.calls.Add(1)
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return *f.err.Load().(*error)
+}
+
+var errProvider = errors.New("服务商异常")
+
+func testBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:        4,
+		MinRequests:   2,
+		ErrorRate:     0.5,
+		SlowThreshold: time.Millisecond * 20,
+		SlowRate:      0.5,
+		OpenTimeout:   time.Minute,
+	}
+}
+
+func TestCircuitBreakerService_Failover(t *testing.T) {
+	bad := newFakeSmsService(errProvider, 0)
+	good := newFakeSmsService(nil, 0)
+	svc := NewCircuitBreakerService([]SmsService{bad, good}, testBreakerConfig(), zap.NewNop())
+
+	// 第一个失败换第二个，之后一直用第二个
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, svc.Send(context.Background(), "tpl", nil, "10086"))
+	}
+	assert.Equal(t, int64(1), bad.calls.Load())
+	assert.Equal(t, int64(5), good.calls.Load())
+}
+
+func TestCircuitBreakerService_OpenAndHalfOpen(t *testing.T) {
+	first := newFakeSmsService(errProvider, 0)
+	second := newFakeSmsService(errProvider, 0)
+	svc := NewCircuitBreakerService([]SmsService{first, second}, testBreakerConfig(), zap.NewNop()).(*CircuitBreakerService)
+	now := time.Now()
+	for _, b := range svc.breakers {
+		b.now = func() time.Time { return now }
+	}
+
+	// 两次失败之后两个都熔断，不再调用服务商
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, ErrAllProvidersFailed, svc.Send(context.Background(), "tpl", nil, "10086"))
+	}
+	assert.Equal(t, ErrAllProvidersFailed, svc.Send(context.Background(), "tpl", nil, "10086"))
+	assert.Equal(t, int64(2), first.calls.Load())
+	assert.Equal(t, int64(2), second.calls.Load())
+
+	// 熔断时间到了半开，探测成功之后恢复
+	now = now.Add(time.Minute)
+	first.setErr(nil)
+	assert.NoError(t, svc.Send(context.Background(), "tpl", nil, "10086"))
+	assert.Equal(t, int64(3), first.calls.Load())
+	assert.Equal(t, breakerClosed, svc.breakers[0].state)
+
+	// 第二个探测失败，重新熔断
+	assert.Equal(t, breakerHalfOpen, func() breakerState {
+		svc.breakers[1].allow()
+		return svc.breakers[1].state
+	}())
+	svc.breakers[1].record(errProvider, 0)
+	assert.Equal(t, breakerOpen, svc.breakers[1].state)
+}
+
+func TestCircuitBreakerService_Slow(t *testing.T) {
+	slow := newFakeSmsService(nil, time.Millisecond*30)
+	fast := newFakeSmsService(nil, 0)
+	svc := NewCircuitBreakerService([]SmsService{slow, fast}, testBreakerConfig(), zap.NewNop())
+
+	// 慢请求也算成功，但是慢的比例超过阈值之后熔断，切到下一个
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, svc.Send(context.Background(), "tpl", nil, "10086"))
+	}
+	assert.Equal(t, int64(2), slow.calls.Load())
+	assert.Equal(t, int64(2), fast.calls.Load())
+}
+
+func TestCircuitBreakerService_CallerCanceled(t *testing.T) {
+	slow := newFakeSmsService(nil, time.Second)
+	other := newFakeSmsService(nil, 0)
+	svc := NewCircuitBreakerService([]SmsService{slow, other}, testBreakerConfig(), zap.NewNop()).(*CircuitBreakerService)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	// 调用方超时直接返回，不换服务商也不计入熔断统计
+	assert.ErrorIs(t, svc.Send(ctx, "tpl", nil, "10086"), context.DeadlineExceeded)
+	assert.Equal(t, int64(0), other.calls.Load())
+	assert.Equal(t, 0, svc.breakers[0].count)
+}
+
+func TestCircuitBreakerService_HalfOpenCanceled(t *testing.T) {
+	first := newFakeSmsService(errProvider, 0)
+	svc := NewCircuitBreakerService([]SmsService{first}, testBreakerConfig(), zap.NewNop()).(*CircuitBreakerService)
+	now := time.Now()
+	svc.breakers[0].now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, ErrAllProvidersFailed, svc.Send(context.Background(), "tpl", nil, "10086"))
+	}
+	assert.Equal(t, breakerOpen, svc.breakers[0].state)
+
+	// 半开的探测请求被调用方取消，探测名额要还回去，下一个请求还能探测
+	now = now.Add(time.Minute)
+	first.delay = time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, svc.Send(ctx, "tpl", nil, "10086"), context.Canceled)
+	assert.Equal(t, breakerHalfOpen, svc.breakers[0].state)
+	assert.False(t, svc.breakers[0].probing)
+
+	first.delay = 0
+	first.setErr(nil)
+	assert.NoError(t, svc.Send(context.Background(), "tpl", nil, "10086"))
+	assert.Equal(t, breakerClosed, svc.breakers[0].state)
+}
+
+func TestCircuitBreakerService_HalfOpenTemplateErr(t *testing.T) {
+	first := newFakeSmsService(errProvider, 0)
+	svc := NewCircuitBreakerService([]SmsService{first}, testBreakerConfig(), zap.NewNop()).(*CircuitBreakerService)
+	now := time.Now()
+	svc.breakers[0].now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, ErrAllProvidersFailed, svc.Send(context.Background(), "tpl", nil, "10086"))
+	}
+
+	// 模板错误不计入统计，也要把探测名额还回去
+	now = now.Add(time.Minute)
+	for _, err := range []error{ErrTemplateNotFound, ErrTemplateUnsupported} {
+		first.setErr(err)
+		svc.Send(context.Background(), "tpl", nil, "10086")
+		assert.Equal(t, breakerHalfOpen, svc.breakers[0].state)
+		assert.False(t, svc.breakers[0].probing)
+	}
+}
+
+func TestCircuitBreakerService_AllTemplateUnsupported(t *testing.T) {
+	first := newFakeSmsService(ErrTemplateUnsupported, 0)
+	second := newFakeSmsService(ErrTemplateUnsupported, 0)
+	svc := NewCircuitBreakerService([]SmsService{first, second}, testBreakerConfig(), zap.NewNop())
+	// 所有服务商都没配置模板，返回模板错误让异步发送直接丢弃
+	assert.Equal(t, ErrTemplateUnsupported, svc.Send(context.Background(), "tpl", nil, "10086"))
+
+	// 有服务商是真的失败了，还是要重试
+	second.setErr(errProvider)
+	assert.Equal(t, ErrAllProvidersFailed, svc.Send(context.Background(), "tpl", nil, "10086"))
+}
+
+func TestFailOverService_Send(t *testing.T) {
+	first := newFakeSmsService(errProvider, 0)
+	second := newFakeSmsService(errProvider, 0)
+	third := newFakeSmsService(nil, 0)
+	svc := NewFailOverService(zap.NewNop(), []SmsService{first, second, third})
+	// 不管从哪个开始都要把所有服务商试一遍
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, svc.Send(context.Background(), "tpl", nil, "10086"))
+	}
+	assert.Equal(t, int64(3), third.calls.Load())
+	assert.Equal(t, int64(3), first.calls.Load()+second.calls.Load())
+}
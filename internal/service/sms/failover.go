@@ -7,6 +7,9 @@ import (
 	"sync/atomic"
 )
 
+var ErrAllProvidersFailed = errors.New("短信服务全部失败")
+
+// FailOverService 轮询服务商，失败的时候换下一个，每次请求从不同的服务商开始
 type FailOverService struct {
 	logger *zap.Logger
 	svcs   []SmsService
@@ -14,27 +17,27 @@ type FailOverService struct {
 }
 
 func NewFailOverService(logger *zap.Logger, svcs []SmsService) SmsService {
-	return FailOverService{
+	return &FailOverService{
 		svcs:   svcs,
 		logger: logger,
 	}
 }
 
-func (f FailOverService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+func (f *FailOverService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
 	idx := atomic.AddUint64(&f.index, 1)
 	length := uint64(len(f.svcs))
-	for i := idx; i < length; i++ {
-		err := f.svcs[i%length].Send(ctx, tplId, args, numbers...)
-		switch err {
-		case nil:
+	for i := idx; i < idx+length; i++ {
+		err := f.svcs[i%length].Send(ctx, tpl, args, numbers...)
+		switch {
+		case err == nil:
 			f.logger.Info("发送短信成功")
 			return nil
-		case context.DeadlineExceeded, context.Canceled:
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
 			f.logger.Error("发送短信失败", zap.Error(err))
 			return err
 		default:
 			f.logger.Error("发送短信异常", zap.Error(err))
 		}
 	}
-	return errors.New("短信服务全部失败")
+	return ErrAllProvidersFailed
 }
@@ -33,7 +33,7 @@ func NewLimiterService(svc SmsService, limiter ratelimit.Limiter, logger *zap.Lo
 
 }
 
-func (l LimiterService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+func (l LimiterService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
 	limit, err := l.limiter.Limit(ctx, "sms")
 	if err != nil {
 		l.logger.Error("短信服务限流出现问题", zap.Error(err))
@@ -43,5 +43,5 @@ func (l LimiterService) Send(ctx context.Context, tplId string, args []string, n
 		l.logger.Warn("触发限流")
 		return errLimit
 	}
-	return l.svc.Send(ctx, tplId, args, numbers...)
+	return l.svc.Send(ctx, tpl, args, numbers...)
 }
@@ -7,17 +7,26 @@ import (
 	"strings"
 )
 
+// ProviderMemory 没有配置服务商的时候只打印到控制台
+const ProviderMemory = "memory"
+
 type MemoryService struct {
-	logger *zap.Logger
+	registry TemplateRegistry
+	logger   *zap.Logger
 }
 
-func NewMemoryService(l *zap.Logger) SmsService {
+func NewMemoryService(registry TemplateRegistry, l *zap.Logger) SmsService {
 	return &MemoryService{
-		logger: l,
+		registry: registry,
+		logger:   l,
 	}
 }
 
-func (m *MemoryService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+// Send 不用服务商的模板 id，只校验参数
+func (m *MemoryService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	if err := m.registry.Validate(tpl, args); err != nil {
+		return err
+	}
 	fmt.Println("====================")
 	fmt.Println("验证码：", strings.Join(args, ""))
 	fmt.Println("====================")
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/sms/sms.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/service/sms/sms.go -package=mock -destination=internal/service/sms/mock/sms.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSmsService is a mock of SmsService interface.
+type MockSmsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmsServiceMockRecorder
+}
+
+// MockSmsServiceMockRecorder is the mock recorder for MockSmsService.
+type MockSmsServiceMockRecorder struct {
+	mock *MockSmsService
+}
+
+// NewMockSmsService creates a new mock instance.
+func NewMockSmsService(ctrl *gomock.Controller) *MockSmsService {
+	mock := &MockSmsService{ctrl: ctrl}
+	mock.recorder = &MockSmsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSmsService) EXPECT() *MockSmsServiceMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockSmsService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, tpl, args}
+	for _, a := range numbers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Send", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockSmsServiceMockRecorder) Send(ctx, tpl, args any, numbers ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, tpl, args}, numbers...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSmsService)(nil).Send), varargs...)
+}
+
+// MockRequestIdSender is a mock of RequestIdSender interface.
+type MockRequestIdSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockRequestIdSenderMockRecorder
+}
+
+// MockRequestIdSenderMockRecorder is the mock recorder for MockRequestIdSender.
+type MockRequestIdSenderMockRecorder struct {
+	mock *MockRequestIdSender
+}
+
+// NewMockRequestIdSender creates a new mock instance.
+func NewMockRequestIdSender(ctrl *gomock.Controller) *MockRequestIdSender {
+	mock := &MockRequestIdSender{ctrl: ctrl}
+	mock.recorder = &MockRequestIdSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRequestIdSender) EXPECT() *MockRequestIdSenderMockRecorder {
+	return m.recorder
+}
+
+// SendWithRequestIds mocks base method.
+func (m *MockRequestIdSender) SendWithRequestIds(ctx context.Context, tpl string, args []string, numbers ...string) ([]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, tpl, args}
+	for _, a := range numbers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SendWithRequestIds", varargs...)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendWithRequestIds indicates an expected call of SendWithRequestIds.
+func (mr *MockRequestIdSenderMockRecorder) SendWithRequestIds(ctx, tpl, args any, numbers ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, tpl, args}, numbers...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWithRequestIds", reflect.TypeOf((*MockRequestIdSender)(nil).SendWithRequestIds), varargs...)
+}
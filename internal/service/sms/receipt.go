@@ -0,0 +1,133 @@
+package sms
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"time"
+)
+
+var ErrReceiptProviderNotFound = errors.New("不支持的短信回执")
+
+// receiptLocation 国内服务商回执里的时间都是北京时间
+var receiptLocation = time.FixedZone("CST", 8*60*60)
+
+// ParseReceipts 把服务商推送的送达回执转成统一的格式
+func ParseReceipts(provider string, body []byte) ([]domain.SmsReceipt, error) {
+	switch provider {
+	case ProviderAliyun:
+		return parseAliyunReceipts(body)
+	case ProviderTencent:
+		return parseTencentReceipts(body)
+	case ProviderWebhook:
+		return parseWebhookReceipts(body)
+	}
+	return nil, ErrReceiptProviderNotFound
+}
+
+// aliyunReceipt 阿里云短信状态报告，一次推送多条
+type aliyunReceipt struct {
+	PhoneNumber string `json:"phone_number"`
+	Success     bool   `json:"success"`
+	BizId       string `json:"biz_id"`
+	ErrCode     string `json:"err_code"`
+	ErrMsg      string `json:"err_msg"`
+	ReportTime  string `json:"report_time"`
+}
+
+func parseAliyunReceipts(body []byte) ([]domain.SmsReceipt, error) {
+	var reports []aliyunReceipt
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil, err
+	}
+	res := make([]domain.SmsReceipt, 0, len(reports))
+	for _, r := range reports {
+		receipt := domain.SmsReceipt{
+			RequestId: r.BizId,
+			Phone:     r.PhoneNumber,
+			Delivered: r.Success,
+			Time:      parseReceiptTime(r.ReportTime),
+		}
+		if !r.Success {
+			receipt.Error = r.ErrCode + " " + r.ErrMsg
+		}
+		res = append(res, receipt)
+	}
+	return res, nil
+}
+
+// tencentReceipt 腾讯云短信下发状态回调，sid 是发送时返回的 SerialNo
+type tencentReceipt struct {
+	UserReceiveTime string `json:"user_receive_time"`
+	NationCode      string `json:"nationcode"`
+	Mobile          string `json:"mobile"`
+	ReportStatus    string `json:"report_status"`
+	ErrMsg          string `json:"errmsg"`
+	Description     string `json:"description"`
+	Sid             string `json:"sid"`
+}
+
+func parseTencentReceipts(body []byte) ([]domain.SmsReceipt, error) {
+	var reports []tencentReceipt
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil, err
+	}
+	res := make([]domain.SmsReceipt, 0, len(reports))
+	for _, r := range reports {
+		phone := r.Mobile
+		if r.NationCode != "" && r.NationCode != "86" {
+			phone = "+" + r.NationCode + r.Mobile
+		}
+		receipt := domain.SmsReceipt{
+			RequestId: r.Sid,
+			Phone:     phone,
+			Delivered: r.ReportStatus == "SUCCESS",
+			Time:      parseReceiptTime(r.UserReceiveTime),
+		}
+		if !receipt.Delivered {
+			receipt.Error = r.ErrMsg + " " + r.Description
+		}
+		res = append(res, receipt)
+	}
+	return res, nil
+}
+
+// webhookReceipt 短信网关推送的回执，id 是发送时响应里的 id，time 是秒级时间戳
+type webhookReceipt struct {
+	Id        string `json:"id"`
+	Phone     string `json:"phone"`
+	Delivered bool   `json:"delivered"`
+	Error     string `json:"error"`
+	Time      int64  `json:"time"`
+}
+
+func parseWebhookReceipts(body []byte) ([]domain.SmsReceipt, error) {
+	var reports []webhookReceipt
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil, err
+	}
+	res := make([]domain.SmsReceipt, 0, len(reports))
+	for _, r := range reports {
+		t := time.Now()
+		if r.Time > 0 {
+			t = time.Unix(r.Time, 0)
+		}
+		res = append(res, domain.SmsReceipt{
+			RequestId: r.Id,
+			Phone:     r.Phone,
+			Delivered: r.Delivered,
+			Error:     r.Error,
+			Time:      t,
+		})
+	}
+	return res, nil
+}
+
+// parseReceiptTime 解析不了的时候用收到回执的时间
+func parseReceiptTime(s string) time.Time {
+	t, err := time.ParseInLocation(time.DateTime, s, receiptLocation)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
@@ -0,0 +1,85 @@
+package sms
+
+import (
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseReceipts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		provider string
+		body     string
+		want     []domain.SmsReceipt
+		wantErr  error
+		anyErr   bool
+	}{
+		{
+			name:     "阿里云",
+			provider: ProviderAliyun,
+			body: `[{"phone_number":"13800001111","send_time":"2024-01-01 10:00:00","report_time":"2024-01-01 10:00:05",` +
+				`"success":true,"err_code":"DELIVERED","err_msg":"用户接收成功","sms_size":"1","biz_id":"biz-1","out_id":""},` +
+				`{"phone_number":"13900002222","report_time":"2024-01-01 10:00:06","success":false,` +
+				`"err_code":"MK:0001","err_msg":"空号","biz_id":"biz-1"}]`,
+			want: []domain.SmsReceipt{
+				{RequestId: "biz-1", Phone: "13800001111", Delivered: true,
+					Time: time.Date(2024, 1, 1, 10, 0, 5, 0, receiptLocation)},
+				{RequestId: "biz-1", Phone: "13900002222", Error: "MK:0001 空号",
+					Time: time.Date(2024, 1, 1, 10, 0, 6, 0, receiptLocation)},
+			},
+		},
+		{
+			name:     "腾讯云",
+			provider: ProviderTencent,
+			body: `[{"user_receive_time":"2024-01-01 10:00:05","nationcode":"86","mobile":"13800001111",` +
+				`"report_status":"SUCCESS","errmsg":"DELIVRD","description":"用户短信送达成功","sid":"sid-1"},` +
+				`{"user_receive_time":"2024-01-01 10:00:06","nationcode":"852","mobile":"61234567",` +
+				`"report_status":"FAIL","errmsg":"MK:0001","description":"空号","sid":"sid-2"}]`,
+			want: []domain.SmsReceipt{
+				{RequestId: "sid-1", Phone: "13800001111", Delivered: true,
+					Time: time.Date(2024, 1, 1, 10, 0, 5, 0, receiptLocation)},
+				{RequestId: "sid-2", Phone: "+85261234567", Error: "MK:0001 空号",
+					Time: time.Date(2024, 1, 1, 10, 0, 6, 0, receiptLocation)},
+			},
+		},
+		{
+			name:     "短信网关",
+			provider: ProviderWebhook,
+			body:     `[{"id":"req-1","phone":"13800001111","delivered":true,"time":1704074405}]`,
+			want: []domain.SmsReceipt{
+				{RequestId: "req-1", Phone: "13800001111", Delivered: true, Time: time.Unix(1704074405, 0)},
+			},
+		},
+		{
+			name:     "不支持的服务商",
+			provider: "unknown",
+			body:     `[]`,
+			wantErr:  ErrReceiptProviderNotFound,
+		},
+		{
+			name:     "格式错误",
+			provider: ProviderAliyun,
+			body:     `{"phone_number":"13800001111"}`,
+			anyErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			receipts, err := ParseReceipts(tc.provider, []byte(tc.body))
+			if tc.anyErr {
+				require.Error(t, err)
+				return
+			}
+			assert.Equal(t, tc.wantErr, err)
+			require.Len(t, receipts, len(tc.want))
+			for i := range tc.want {
+				assert.True(t, tc.want[i].Time.Equal(receipts[i].Time))
+				receipts[i].Time = tc.want[i].Time
+			}
+			assert.Equal(t, tc.want, receipts)
+		})
+	}
+}
@@ -2,6 +2,12 @@ package sms
 
 import "context"
 
+// SmsService tpl 是逻辑模板，比如 TemplateLoginCode，每个实现自己通过 TemplateRegistry 查服务商的模板
 type SmsService interface {
-	Send(ctx context.Context, tplId string, args []string, numbers ...string) error
+	Send(ctx context.Context, tpl string, args []string, numbers ...string) error
+}
+
+// RequestIdSender 能拿到服务商请求 id 的实现，返回的 id 和 numbers 一一对应，服务商的送达回执靠它对应到发送记录
+type RequestIdSender interface {
+	SendWithRequestIds(ctx context.Context, tpl string, args []string, numbers ...string) ([]string, error)
 }
@@ -0,0 +1,86 @@
+package sms
+
+import (
+	"errors"
+)
+
+// 业务代码里只用逻辑模板，每个服务商实际的模板 id 和签名由 TemplateRegistry 查
+const (
+	TemplateLoginCode     = "login_code"
+	TemplatePasswordReset = "password_reset"
+	TemplateNotification  = "notification"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("短信模板不存在")
+	// ErrTemplateUnsupported 服务商没有配置这个模板
+	ErrTemplateUnsupported = errors.New("服务商不支持该短信模板")
+	ErrTemplateParams      = errors.New("短信模板参数个数不匹配")
+)
+
+// Template 逻辑模板，Params 是模板需要的参数个数，Providers 是每个服务商对应的模板
+type Template struct {
+	Params    int
+	Providers map[string]ProviderTemplate
+}
+
+// ProviderTemplate 服务商的模板 id 和签名，签名为空的时候用服务商默认的签名
+type ProviderTemplate struct {
+	Id        string
+	Signature string
+	// ParamNames 按名字传参的服务商用，和参数一一对应
+	ParamNames []string
+}
+
+type TemplateRegistry interface {
+	// Validate 校验模板存在并且参数个数对得上
+	Validate(name string, args []string) error
+	// Resolve 校验参数之后返回服务商的模板
+	Resolve(provider string, name string, args []string) (ProviderTemplate, error)
+}
+
+// MapTemplateRegistry 启动的时候从配置加载，之后只读
+type MapTemplateRegistry struct {
+	templates map[string]Template
+}
+
+func NewTemplateRegistry(templates map[string]Template) TemplateRegistry {
+	return &MapTemplateRegistry{
+		templates: templates,
+	}
+}
+
+func (r *MapTemplateRegistry) Validate(name string, args []string) error {
+	_, err := r.get(name, args)
+	return err
+}
+
+func (r *MapTemplateRegistry) Resolve(provider string, name string, args []string) (ProviderTemplate, error) {
+	tpl, err := r.get(name, args)
+	if err != nil {
+		return ProviderTemplate{}, err
+	}
+	pt, ok := tpl.Providers[provider]
+	if !ok || pt.Id == "" {
+		return ProviderTemplate{}, ErrTemplateUnsupported
+	}
+	return pt, nil
+}
+
+func (r *MapTemplateRegistry) get(name string, args []string) (Template, error) {
+	tpl, ok := r.templates[name]
+	if !ok {
+		return Template{}, ErrTemplateNotFound
+	}
+	if len(args) != tpl.Params {
+		return Template{}, ErrTemplateParams
+	}
+	return tpl, nil
+}
+
+// isTemplateErr 模板配置或者调用参数的问题，不是服务商的问题
+func isTemplateErr(err error) bool {
+	return errors.Is(err, ErrTemplateNotFound) ||
+		errors.Is(err, ErrTemplateUnsupported) ||
+		errors.Is(err, ErrTemplateParams)
+}
@@ -0,0 +1,62 @@
+package sms
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMapTemplateRegistry_Resolve(t *testing.T) {
+	registry := NewTemplateRegistry(map[string]Template{
+		TemplateLoginCode: {
+			Params: 1,
+			Providers: map[string]ProviderTemplate{
+				ProviderTencent: {Id: "1877556", Signature: "小蓝书"},
+			},
+		},
+		TemplateNotification: {Params: 2},
+	})
+	testCases := []struct {
+		name     string
+		provider string
+		tpl      string
+		args     []string
+		want     ProviderTemplate
+		wantErr  error
+	}{
+		{
+			name:     "查到服务商的模板",
+			provider: ProviderTencent,
+			tpl:      TemplateLoginCode,
+			args:     []string{"123456"},
+			want:     ProviderTemplate{Id: "1877556", Signature: "小蓝书"},
+		},
+		{
+			name:     "模板不存在",
+			provider: ProviderTencent,
+			tpl:      TemplatePasswordReset,
+			args:     []string{"123456"},
+			wantErr:  ErrTemplateNotFound,
+		},
+		{
+			name:     "参数个数不对",
+			provider: ProviderTencent,
+			tpl:      TemplateLoginCode,
+			args:     []string{"123456", "10"},
+			wantErr:  ErrTemplateParams,
+		},
+		{
+			name:     "服务商没有配置",
+			provider: ProviderTencent,
+			tpl:      TemplateNotification,
+			args:     []string{"a", "b"},
+			wantErr:  ErrTemplateUnsupported,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pt, err := registry.Resolve(tc.provider, tc.tpl, tc.args)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, pt)
+		})
+	}
+}
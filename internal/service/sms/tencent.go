@@ -6,41 +6,94 @@ import (
 	"fmt"
 	sms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
 	"math/rand"
+	"strings"
 )
 
+const ProviderTencent = "tencent"
+
 type TencentSmsService struct {
 	appId     string
 	signature string
 	client    *sms.Client
+	registry  TemplateRegistry
 }
 
-func NewTencentSmsService(client *sms.Client, appId string, signature string) SmsService {
+func NewTencentSmsService(client *sms.Client, appId string, signature string, registry TemplateRegistry) SmsService {
 	return &TencentSmsService{
 		appId:     appId,
 		signature: signature,
 		client:    client,
+		registry:  registry,
 	}
 }
 
-func (s TencentSmsService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+func (s TencentSmsService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	_, err := s.SendWithRequestIds(ctx, tpl, args, numbers...)
+	return err
+}
+
+// SendWithRequestIds 腾讯云每个号码有自己的 SerialNo，回执里的 sid 就是它
+func (s TencentSmsService) SendWithRequestIds(ctx context.Context, tpl string, args []string, numbers ...string) ([]string, error) {
+	pt, err := s.registry.Resolve(ProviderTencent, tpl, args)
+	if err != nil {
+		return nil, err
+	}
+	signature := s.signature
+	if pt.Signature != "" {
+		signature = pt.Signature
+	}
 	req := sms.NewSendSmsRequest()
 	req.SmsSdkAppId = &s.appId
-	req.SignName = &s.signature
-	req.TemplateId = &tplId
+	req.SignName = &signature
+	req.TemplateId = &pt.Id
 	req.TemplateParamSet = s.toStringPtrSlice(args)
 	req.PhoneNumberSet = s.toStringPtrSlice(numbers)
-	resp, err := s.client.SendSms(req)
+	resp, err := s.client.SendSmsWithContext(ctx, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	ids := make([]string, len(numbers))
+	index := tencentPhoneIndex(numbers)
 	for _, status := range resp.Response.SendStatusSet {
 		if status.Code == nil || *status.Code != "Ok" {
-			return errors.New(
-				fmt.Sprintf("发送短信失败，%s，%s", *status.Code, *status.Message),
+			return nil, errors.New(
+				fmt.Sprintf("发送短信失败，%s，%s", derefString(status.Code), derefString(status.Message)),
 			)
 		}
+		// 返回的顺序不保证和请求一致，按号码对回去
+		if status.PhoneNumber == nil || status.SerialNo == nil {
+			continue
+		}
+		if i, ok := index[tencentPhoneKey(*status.PhoneNumber)]; ok {
+			ids[i] = *status.SerialNo
+		}
+	}
+	return ids, nil
+}
+
+// tencentPhoneIndex 号码到请求里下标的映射
+func tencentPhoneIndex(numbers []string) map[string]int {
+	index := make(map[string]int, len(numbers))
+	for i, n := range numbers {
+		index[tencentPhoneKey(n)] = i
+	}
+	return index
+}
+
+// tencentPhoneKey 腾讯云返回的号码是 +86 开头的 E.164 格式，请求里可能没有带国家码
+func tencentPhoneKey(number string) string {
+	number = strings.TrimPrefix(number, "+")
+	if len(number) == 13 && strings.HasPrefix(number, "86") {
+		return number[2:]
+	}
+	return number
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
 	}
-	return nil
+	return *s
 }
 
 func (s TencentSmsService) toStringPtrSlice(slice []string) []*string {
@@ -0,0 +1,42 @@
+package sms
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	tencent "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTencentSmsService_SendWithRequestIds(t *testing.T) {
+	// 返回的顺序和请求的号码顺序不一样
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Response":{"RequestId":"req","SendStatusSet":[` +
+			`{"SerialNo":"sid-2","PhoneNumber":"+8613900002222","Code":"Ok","Message":"send success"},` +
+			`{"SerialNo":"sid-1","PhoneNumber":"+8613800001111","Code":"Ok","Message":"send success"}]}}`))
+	}))
+	defer server.Close()
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Scheme = "HTTP"
+	cpf.HttpProfile.Endpoint = strings.TrimPrefix(server.URL, "http://")
+	client, err := tencent.NewClient(common.NewCredential("id", "key"), "ap-guangzhou", cpf)
+	require.NoError(t, err)
+	registry := NewTemplateRegistry(map[string]Template{
+		TemplateLoginCode: {
+			Params:    1,
+			Providers: map[string]ProviderTemplate{ProviderTencent: {Id: "1877556"}},
+		},
+	})
+	svc := NewTencentSmsService(client, "app", "小蓝书", registry).(*TencentSmsService)
+
+	ids, err := svc.SendWithRequestIds(context.Background(), TemplateLoginCode, []string{"123456"},
+		"13800001111", "+8613900002222")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sid-1", "sid-2"}, ids)
+}
@@ -0,0 +1,121 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	ProviderWebhook = "webhook"
+
+	// WebhookSignatureHeader HMAC-SHA256(secret, timestamp + "." + body) 的十六进制
+	WebhookSignatureHeader = "X-Sms-Signature"
+	// WebhookTimestampHeader 秒级时间戳，接收方可以拒绝太旧的请求防止重放
+	WebhookTimestampHeader = "X-Sms-Timestamp"
+)
+
+// WebhookSmsService 把短信转成一个 HTTP 回调，对接没有 SDK 的服务商或者内部的短信网关
+type WebhookSmsService struct {
+	url       string
+	secret    string
+	signature string
+	client    *http.Client
+	registry  TemplateRegistry
+}
+
+func NewWebhookSmsService(client *http.Client, url, secret, signature string, registry TemplateRegistry) SmsService {
+	return &WebhookSmsService{
+		url:       url,
+		secret:    secret,
+		signature: signature,
+		client:    client,
+		registry:  registry,
+	}
+}
+
+type webhookRequest struct {
+	Template  string            `json:"template"`
+	Signature string            `json:"signature"`
+	Args      []string          `json:"args"`
+	Params    map[string]string `json:"params"`
+	Numbers   []string          `json:"numbers"`
+}
+
+// webhookResponse code 为 0 表示成功，id 是网关的请求 id，送达回执里带回来
+type webhookResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Id   string `json:"id"`
+}
+
+func (s *WebhookSmsService) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
+	_, err := s.SendWithRequestIds(ctx, tpl, args, numbers...)
+	return err
+}
+
+func (s *WebhookSmsService) SendWithRequestIds(ctx context.Context, tpl string, args []string, numbers ...string) ([]string, error) {
+	pt, err := s.registry.Resolve(ProviderWebhook, tpl, args)
+	if err != nil {
+		return nil, err
+	}
+	signature := s.signature
+	if pt.Signature != "" {
+		signature = pt.Signature
+	}
+	body, err := json.Marshal(webhookRequest{
+		Template:  pt.Id,
+		Signature: signature,
+		Args:      args,
+		Params:    namedParams(pt.ParamNames, args),
+		Numbers:   numbers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, WebhookSign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("短信回调返回状态码 %d", resp.StatusCode)
+	}
+	var res webhookResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("解析短信回调响应失败: %w", err)
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("发送短信失败，%d，%s", res.Code, res.Msg)
+	}
+	ids := make([]string, len(numbers))
+	for i := range ids {
+		ids[i] = res.Id
+	}
+	return ids, nil
+}
+
+// WebhookSign 接收方用同样的方式校验请求
+func WebhookSign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,92 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSmsService_Send(t *testing.T) {
+	registry := NewTemplateRegistry(map[string]Template{
+		TemplateLoginCode: {
+			Params: 1,
+			Providers: map[string]ProviderTemplate{
+				ProviderWebhook: {Id: "login", Signature: "小蓝书", ParamNames: []string{"code"}},
+			},
+		},
+	})
+	testCases := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{
+			name:   "发送成功",
+			status: http.StatusOK,
+			body:   `{"code":0}`,
+		},
+		{
+			name:    "业务失败",
+			status:  http.StatusOK,
+			body:    `{"code":1001,"msg":"号码无效"}`,
+			wantErr: true,
+		},
+		{
+			name:    "状态码不是 2xx",
+			status:  http.StatusBadGateway,
+			body:    `bad gateway`,
+			wantErr: true,
+		},
+		{
+			name:    "响应不是 JSON",
+			status:  http.StatusOK,
+			body:    `ok`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				timestamp := r.Header.Get(WebhookTimestampHeader)
+				assert.NotEmpty(t, timestamp)
+				assert.Equal(t, WebhookSign("secret", timestamp, body), r.Header.Get(WebhookSignatureHeader))
+
+				var req webhookRequest
+				require.NoError(t, json.Unmarshal(body, &req))
+				assert.Equal(t, webhookRequest{
+					Template:  "login",
+					Signature: "小蓝书",
+					Args:      []string{"123456"},
+					Params:    map[string]string{"code": "123456"},
+					Numbers:   []string{"13800000000"},
+				}, req)
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			svc := NewWebhookSmsService(server.Client(), server.URL, "secret", "", registry)
+			err := svc.Send(context.Background(), TemplateLoginCode, []string{"123456"}, "13800000000")
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookSmsService_TemplateUnsupported(t *testing.T) {
+	registry := NewTemplateRegistry(map[string]Template{TemplateLoginCode: {Params: 1}})
+	svc := NewWebhookSmsService(http.DefaultClient, "http://127.0.0.1:0", "secret", "", registry)
+	err := svc.Send(context.Background(), TemplateLoginCode, []string{"123456"}, "13800000000")
+	assert.Equal(t, ErrTemplateUnsupported, err)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrSmsReceiptProviderNotFound = sms.ErrReceiptProviderNotFound
+	// ErrSmsCallbackUnauthorized 回执带的密钥或者签名不对
+	ErrSmsCallbackUnauthorized = errors.New("短信回执校验失败")
+	ErrInvalidSmsReceipt       = errors.New("短信回执格式错误")
+)
+
+// maxSmsRecordPageSize 发送记录每页最多返回的条数
+const maxSmsRecordPageSize = 100
+
+// smsCallbackMaxSkew 回执时间戳和当前时间最多差多少，超过的当成重放拒绝
+const smsCallbackMaxSkew = time.Minute * 5
+
+type SmsRecordService interface {
+	// Receipt 处理服务商推送的送达回执，返回更新了多少条发送记录
+	Receipt(ctx context.Context, provider string, auth SmsCallbackAuth, body []byte) (int, error)
+	// List 按手机号查询发送记录，最新的在前面
+	List(ctx context.Context, phone string, offset, limit int) ([]domain.SmsRecord, error)
+}
+
+// SmsCallbackAuth 回执带的校验信息。阿里云和腾讯云的回执没法签名，
+// 密钥放在回执地址里；webhook 服务商和发送的时候一样用请求头里的签名
+type SmsCallbackAuth struct {
+	Secret    string
+	Timestamp string
+	Signature string
+}
+
+type SmsRecordServiceImpl struct {
+	repo repository.SmsRecordRepository
+	// secrets 每个服务商的回执密钥，没有配置的服务商不接收回执
+	secrets map[string]string
+	logger  *zap.Logger
+}
+
+func NewSmsRecordService(repo repository.SmsRecordRepository, secrets map[string]string, l *zap.Logger) SmsRecordService {
+	return &SmsRecordServiceImpl{
+		repo:    repo,
+		secrets: secrets,
+		logger:  l,
+	}
+}
+
+func (svc *SmsRecordServiceImpl) Receipt(ctx context.Context, provider string, auth SmsCallbackAuth,
+	body []byte) (int, error) {
+	secret := svc.secrets[provider]
+	if secret == "" {
+		return 0, ErrSmsReceiptProviderNotFound
+	}
+	if !svc.verify(provider, secret, auth, body) {
+		return 0, ErrSmsCallbackUnauthorized
+	}
+	receipts, err := sms.ParseReceipts(provider, body)
+	if errors.Is(err, sms.ErrReceiptProviderNotFound) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidSmsReceipt, err)
+	}
+	updated := 0
+	for _, receipt := range receipts {
+		r := domain.SmsRecord{
+			Provider:     provider,
+			RequestId:    receipt.RequestId,
+			PhoneHash:    domain.HashPhone(receipt.Phone),
+			Status:       domain.SmsRecordStatusDelivered,
+			DeliveryTime: receipt.Time,
+		}
+		if !receipt.Delivered {
+			r.Status = domain.SmsRecordStatusUndelivered
+			r.Error = receipt.Error
+		}
+		ok, er := svc.repo.UpdateDelivery(ctx, r)
+		if er != nil {
+			return updated, er
+		}
+		if !ok {
+			// 重复推送或者记录落库失败了，回执本身没问题
+			svc.logger.Warn("短信回执没有对应的发送记录", zap.String("provider", provider),
+				zap.String("requestId", receipt.RequestId))
+			continue
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func (svc *SmsRecordServiceImpl) List(ctx context.Context, phone string, offset, limit int) ([]domain.SmsRecord, error) {
+	if limit <= 0 || limit > maxSmsRecordPageSize {
+		limit = maxSmsRecordPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return svc.repo.FindByPhoneHash(ctx, domain.HashPhone(phone), offset, limit)
+}
+
+// verify webhook 服务商校验签名和时间戳，其他服务商校验回执地址里的密钥
+func (svc *SmsRecordServiceImpl) verify(provider string, secret string, auth SmsCallbackAuth, body []byte) bool {
+	if provider != sms.ProviderWebhook {
+		return subtle.ConstantTimeCompare([]byte(auth.Secret), []byte(secret)) == 1
+	}
+	ts, err := strconv.ParseInt(auth.Timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew > smsCallbackMaxSkew || skew < -smsCallbackMaxSkew {
+		return false
+	}
+	expected := sms.WebhookSign(secret, auth.Timestamp, body)
+	return subtle.ConstantTimeCompare([]byte(auth.Signature), []byte(expected)) == 1
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"github.com/ChongYanOvO/little-blue-book/internal/domain"
+	"github.com/ChongYanOvO/little-blue-book/internal/repository"
+	repomock "github.com/ChongYanOvO/little-blue-book/internal/repository/mock"
+	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSmsRecordServiceImpl_Receipt(t *testing.T) {
+	body := `[{"id":"req-1","phone":"13800001111","delivered":true,"time":1704074405},` +
+		`{"id":"req-1","phone":"13900002222","delivered":false,"error":"空号","time":1704074406},` +
+		`{"id":"req-2","phone":"13700003333","delivered":true,"time":1704074407}]`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	testCases := []struct {
+		name        string
+		mock        func(ctl *gomock.Controller) repository.SmsRecordRepository
+		auth        SmsCallbackAuth
+		provider    string
+		body        string
+		wantUpdated int
+		wantErr     error
+	}{
+		{
+			name: "更新送达状态，没有对应记录的跳过",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				repo := repomock.NewMockSmsRecordRepository(ctl)
+				repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, r domain.SmsRecord) (bool, error) {
+						assert.Equal(t, "webhook", r.Provider)
+						assert.Equal(t, "req-1", r.RequestId)
+						assert.Equal(t, domain.HashPhone("13800001111"), r.PhoneHash)
+						assert.Equal(t, domain.SmsRecordStatusDelivered, r.Status)
+						return true, nil
+					})
+				repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, r domain.SmsRecord) (bool, error) {
+						assert.Equal(t, domain.SmsRecordStatusUndelivered, r.Status)
+						assert.Equal(t, "空号", r.Error)
+						return true, nil
+					})
+				repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(false, nil)
+				return repo
+			},
+			auth:        SmsCallbackAuth{Timestamp: now, Signature: sms.WebhookSign("secret", now, []byte(body))},
+			provider:    "webhook",
+			body:        body,
+			wantUpdated: 2,
+		},
+		{
+			name: "签名不对",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				return repomock.NewMockSmsRecordRepository(ctl)
+			},
+			auth:     SmsCallbackAuth{Timestamp: now, Signature: sms.WebhookSign("bad", now, []byte(body))},
+			provider: "webhook",
+			body:     body,
+			wantErr:  ErrSmsCallbackUnauthorized,
+		},
+		{
+			name: "时间戳太旧",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				return repomock.NewMockSmsRecordRepository(ctl)
+			},
+			auth:     SmsCallbackAuth{Timestamp: stale, Signature: sms.WebhookSign("secret", stale, []byte(body))},
+			provider: "webhook",
+			body:     body,
+			wantErr:  ErrSmsCallbackUnauthorized,
+		},
+		{
+			name: "不支持的服务商",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				return repomock.NewMockSmsRecordRepository(ctl)
+			},
+			auth:     SmsCallbackAuth{Timestamp: now, Signature: sms.WebhookSign("secret", now, []byte(body))},
+			provider: "unknown",
+			body:     body,
+			wantErr:  ErrSmsReceiptProviderNotFound,
+		},
+		{
+			name: "阿里云用回执地址里的密钥",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				return repomock.NewMockSmsRecordRepository(ctl)
+			},
+			auth:     SmsCallbackAuth{Secret: "path-secret"},
+			provider: "aliyun",
+			body:     `[]`,
+		},
+		{
+			name: "阿里云的密钥不对",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				return repomock.NewMockSmsRecordRepository(ctl)
+			},
+			auth:     SmsCallbackAuth{Secret: "bad"},
+			provider: "aliyun",
+			body:     `[]`,
+			wantErr:  ErrSmsCallbackUnauthorized,
+		},
+		{
+			name: "没有配置密钥的服务商不接收回执",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				return repomock.NewMockSmsRecordRepository(ctl)
+			},
+			auth:     SmsCallbackAuth{Secret: ""},
+			provider: "tencent",
+			body:     `[]`,
+			wantErr:  ErrSmsReceiptProviderNotFound,
+		},
+		{
+			name: "格式错误",
+			mock: func(ctl *gomock.Controller) repository.SmsRecordRepository {
+				return repomock.NewMockSmsRecordRepository(ctl)
+			},
+			auth:     SmsCallbackAuth{Timestamp: now, Signature: sms.WebhookSign("secret", now, []byte(`not json`))},
+			provider: "webhook",
+			body:     `not json`,
+			wantErr:  ErrInvalidSmsReceipt,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := NewSmsRecordService(tc.mock(ctl), map[string]string{
+				"webhook": "secret",
+				"aliyun":  "path-secret",
+			}, zap.NewNop())
+			updated, err := svc.Receipt(context.Background(), tc.provider, tc.auth, []byte(tc.body))
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantUpdated, updated)
+		})
+	}
+}
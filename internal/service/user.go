@@ -10,9 +10,13 @@ import (
 )
 
 var (
-	ErrUserDuplicateEmail = repository.ErrUserDuplicateEmail
-	ErrUserNotFound       = repository.ErrUserNotFound
-	ErrInvalidUserOrEmail = errors.New("邮箱或密码不对")
+	ErrUserDuplicateEmail  = repository.ErrUserDuplicateEmail
+	ErrUserDuplicateOAuth2 = repository.ErrUserDuplicateOAuth2
+	ErrUserDuplicatePhone  = repository.ErrUserDuplicatePhone
+	ErrUserNotFound        = repository.ErrUserNotFound
+	ErrInvalidUserOrEmail  = errors.New("邮箱或密码不对")
+	ErrInvalidPassword     = errors.New("密码不对")
+	ErrUserBanned          = errors.New("用户已被封禁")
 )
 
 type UserService interface {
@@ -20,6 +24,26 @@ type UserService interface {
 	SignUp(ctx context.Context, u domain.User) error
 	Profile(ctx context.Context, id int64) (domain.User, error)
 	FindOrCreate(ctx context.Context, phone string) (domain.User, error)
+	// VerifyPassword 校验用户的密码，没有设置密码的用户直接校验失败
+	VerifyPassword(ctx context.Context, id int64, password string) error
+	// ChangePassword 校验旧密码之后修改密码
+	ChangePassword(ctx context.Context, id int64, oldPassword, newPassword string) error
+	// ResetPassword 忘记密码时通过手机号或者邮箱找到用户并重置密码，调用方需要先完成验证码校验
+	ResetPassword(ctx context.Context, u domain.User, password string) (domain.User, error)
+	// VerifyEmail 标记邮箱已验证，调用方需要先完成验证码校验
+	VerifyEmail(ctx context.Context, email string) error
+	// FindOrCreateByOAuth2 第三方登录，没有绑定过的账号直接创建新用户
+	FindOrCreateByOAuth2(ctx context.Context, info domain.OAuth2Info) (domain.User, error)
+	// BindOAuth2 已登录用户绑定第三方账号
+	BindOAuth2(ctx context.Context, id int64, info domain.OAuth2Info) error
+	// BindPhone 绑定或更换手机号，调用方需要先完成验证码校验
+	BindPhone(ctx context.Context, id int64, phone string) error
+	// BindEmail 绑定或更换邮箱，调用方需要先完成验证码校验
+	BindEmail(ctx context.Context, id int64, email string) error
+	// Ban 封禁用户，调用方需要同时作废用户的会话
+	Ban(ctx context.Context, id int64) error
+	// Unban 解封用户
+	Unban(ctx context.Context, id int64) error
 }
 
 type UserServiceImpl struct {
@@ -50,6 +74,10 @@ func (svc *UserServiceImpl) Login(ctx context.Context, email, password string) (
 		// DEBUG
 		return domain.User{}, ErrInvalidUserOrEmail
 	}
+	// 密码对了才告诉对方被封禁，避免暴露账号状态
+	if u.Status == domain.UserStatusBanned {
+		return domain.User{}, ErrUserBanned
+	}
 	return u, nil
 }
 
@@ -76,11 +104,113 @@ func (svc *UserServiceImpl) Profile(ctx context.Context, id int64) (domain.User,
 func (svc UserServiceImpl) FindOrCreate(ctx context.Context, phone string) (domain.User, error) {
 
 	if user, err := svc.repo.FindByPhone(ctx, phone); err == nil {
+		if user.Status == domain.UserStatusBanned {
+			return domain.User{}, ErrUserBanned
+		}
 		return user, err
 	} else if err := svc.repo.Create(ctx, domain.User{
 		Phone: phone,
+		// 能收到短信验证码，手机号自然是验证过的
+		Verified: true,
 	}); err != nil {
 		return domain.User{}, err
 	}
 	return svc.repo.FindByPhone(ctx, phone)
 }
+
+func (svc *UserServiceImpl) VerifyPassword(ctx context.Context, id int64, password string) error {
+	u, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	// 手机号注册的用户没有密码
+	if u.Password == "" {
+		return ErrInvalidPassword
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// ChangePassword 没有密码的用户只能走重置密码
+func (svc *UserServiceImpl) ChangePassword(ctx context.Context, id int64, oldPassword, newPassword string) error {
+	if err := svc.VerifyPassword(ctx, id, oldPassword); err != nil {
+		return err
+	}
+	return svc.updatePassword(ctx, id, newPassword)
+}
+
+func (svc *UserServiceImpl) ResetPassword(ctx context.Context, u domain.User, password string) (domain.User, error) {
+	var (
+		found domain.User
+		err   error
+	)
+	if u.Phone != "" {
+		found, err = svc.repo.FindByPhone(ctx, u.Phone)
+	} else {
+		found, err = svc.repo.FindByEmail(ctx, u.Email)
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err = svc.updatePassword(ctx, found.Id, password); err != nil {
+		return domain.User{}, err
+	}
+	return found, nil
+}
+
+func (svc *UserServiceImpl) VerifyEmail(ctx context.Context, email string) error {
+	return svc.repo.VerifyEmail(ctx, email)
+}
+
+func (svc *UserServiceImpl) FindOrCreateByOAuth2(ctx context.Context, info domain.OAuth2Info) (domain.User, error) {
+	u, err := svc.repo.FindByOAuth2(ctx, info.Provider, info.OpenId)
+	if err == nil && u.Status == domain.UserStatusBanned {
+		return domain.User{}, ErrUserBanned
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return u, err
+	}
+	// 第三方账号的邮箱不一定验证过，不能用来自动关联已有账号，需要用户登录之后主动绑定
+	u = domain.User{Verified: true}
+	switch info.Provider {
+	case "github":
+		u.GithubId = info.OpenId
+	case "wechat":
+		u.WechatOpenId = info.OpenId
+	}
+	if err = svc.repo.Create(ctx, u); err != nil && !errors.Is(err, ErrUserDuplicateEmail) {
+		return domain.User{}, err
+	}
+	// 唯一索引冲突说明并发创建了，直接再查一次
+	return svc.repo.FindByOAuth2(ctx, info.Provider, info.OpenId)
+}
+
+func (svc *UserServiceImpl) BindOAuth2(ctx context.Context, id int64, info domain.OAuth2Info) error {
+	return svc.repo.BindOAuth2(ctx, id, info.Provider, info.OpenId)
+}
+
+func (svc *UserServiceImpl) BindPhone(ctx context.Context, id int64, phone string) error {
+	return svc.repo.UpdatePhone(ctx, id, phone)
+}
+
+func (svc *UserServiceImpl) BindEmail(ctx context.Context, id int64, email string) error {
+	return svc.repo.UpdateEmail(ctx, id, email)
+}
+
+func (svc *UserServiceImpl) Ban(ctx context.Context, id int64) error {
+	return svc.repo.UpdateStatus(ctx, id, domain.UserStatusBanned)
+}
+
+func (svc *UserServiceImpl) Unban(ctx context.Context, id int64) error {
+	return svc.repo.UpdateStatus(ctx, id, domain.UserStatusNormal)
+}
+
+func (svc *UserServiceImpl) updatePassword(ctx context.Context, id int64, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return svc.repo.UpdatePassword(ctx, id, string(hash))
+}
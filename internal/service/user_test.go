@@ -84,6 +84,23 @@ func TestUserServiceImpl_Login(t *testing.T) {
 			wantUser: domain.User{},
 			wantErr:  ErrInvalidUserOrEmail,
 		},
+		{
+			name: "用户已被封禁",
+			mock: func(ctl *gomock.Controller) repository.UserRepository {
+				ur := repomock.NewMockUserRepository(ctl)
+				ur.EXPECT().FindByEmail(gomock.Any(), "test@gmail.com").
+					Return(domain.User{
+						Email:    "test@gmail.com",
+						Password: "$2a$10$DEFY1AeFZidKeHuKVleFSueNUOP9mjiNq7YmCmyXA/Miwqyrk.1Ze",
+						Status:   domain.UserStatusBanned,
+					}, nil)
+				return ur
+			},
+			email:    "test@gmail.com",
+			password: "1qaz@WSX",
+			wantUser: domain.User{},
+			wantErr:  ErrUserBanned,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -97,3 +114,69 @@ func TestUserServiceImpl_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestUserServiceImpl_ChangePassword(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(ctl *gomock.Controller) repository.UserRepository
+		oldPassword string
+		newPassword string
+		wantErr     error
+	}{
+		{
+			name: "修改成功",
+			mock: func(ctl *gomock.Controller) repository.UserRepository {
+				ur := repomock.NewMockUserRepository(ctl)
+				ur.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.User{
+						Id:       1,
+						Password: "$2a$10$DEFY1AeFZidKeHuKVleFSueNUOP9mjiNq7YmCmyXA/Miwqyrk.1Ze",
+					}, nil)
+				ur.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				return ur
+			},
+			oldPassword: "1qaz@WSX",
+			newPassword: "2wsx#EDC",
+			wantErr:     nil,
+		},
+		{
+			name: "旧密码错误",
+			mock: func(ctl *gomock.Controller) repository.UserRepository {
+				ur := repomock.NewMockUserRepository(ctl)
+				ur.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.User{
+						Id:       1,
+						Password: "$2a$10$DEFY1AeFZidKeHuKVleFSueNUOP9mjiNq7YmCmyXA/Miwqyrk.1Ze",
+					}, nil)
+				return ur
+			},
+			oldPassword: "1qaz@WSX2",
+			newPassword: "2wsx#EDC",
+			wantErr:     ErrInvalidPassword,
+		},
+		{
+			name: "手机号用户没有密码",
+			mock: func(ctl *gomock.Controller) repository.UserRepository {
+				ur := repomock.NewMockUserRepository(ctl)
+				ur.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.User{
+						Id:    1,
+						Phone: "1234567890",
+					}, nil)
+				return ur
+			},
+			oldPassword: "",
+			newPassword: "2wsx#EDC",
+			wantErr:     ErrInvalidPassword,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := NewUserService(tc.mock(ctl), nil)
+			err := svc.ChangePassword(context.Background(), 1, tc.oldPassword, tc.newPassword)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
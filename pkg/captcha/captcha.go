@@ -0,0 +1,162 @@
+package captcha
+
+import (
+	"bytes"
+	"crypto/rand"
+	"image"
+	"image/color"
+	"image/png"
+	"math/big"
+	mrand "math/rand"
+)
+
+// Generator 生成图形验证码，返回答案和 PNG 图片
+type Generator interface {
+	Generate() (answer string, img []byte, err error)
+}
+
+// digitGlyphs 5x7 的数字点阵
+var digitGlyphs = [10][7]string{
+	{"01110", "10001", "10011", "10101", "11001", "10001", "01110"},
+	{"00100", "01100", "00100", "00100", "00100", "00100", "01110"},
+	{"01110", "10001", "00001", "00010", "00100", "01000", "11111"},
+	{"11111", "00010", "00100", "00010", "00001", "10001", "01110"},
+	{"00010", "00110", "01010", "10010", "11111", "00010", "00010"},
+	{"11111", "10000", "11110", "00001", "00001", "10001", "01110"},
+	{"00110", "01000", "10000", "11110", "10001", "10001", "01110"},
+	{"11111", "00001", "00010", "00100", "01000", "01000", "01000"},
+	{"01110", "10001", "10001", "01110", "10001", "10001", "01110"},
+	{"01110", "10001", "10001", "01111", "00001", "00010", "01100"},
+}
+
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// DigitGenerator 数字验证码，每个数字随机倾斜、上下错位、换颜色，再加上干扰线和噪点，
+// 不依赖字体文件
+type DigitGenerator struct {
+	length int
+	width  int
+	height int
+}
+
+func NewDigitGenerator(length, width, height int) Generator {
+	return &DigitGenerator{
+		length: length,
+		width:  width,
+		height: height,
+	}
+}
+
+func (g *DigitGenerator) Generate() (string, []byte, error) {
+	digits := make([]byte, g.length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", nil, err
+		}
+		digits[i] = byte(n.Int64())
+	}
+	img, err := g.draw(digits)
+	if err != nil {
+		return "", nil, err
+	}
+	answer := make([]byte, len(digits))
+	for i, d := range digits {
+		answer[i] = '0' + d
+	}
+	return string(answer), img, nil
+}
+
+func (g *DigitGenerator) draw(digits []byte) ([]byte, error) {
+	r := mrand.New(mrand.NewSource(mrand.Int63()))
+	img := image.NewNRGBA(image.Rect(0, 0, g.width, g.height))
+	bg := color.NRGBA{R: uint8(220 + r.Intn(36)), G: uint8(220 + r.Intn(36)), B: uint8(220 + r.Intn(36)), A: 255}
+	for x := 0; x < g.width; x++ {
+		for y := 0; y < g.height; y++ {
+			img.Set(x, y, bg)
+		}
+	}
+	// 每个数字占一格，按高度和格子宽度算缩放倍数
+	cell := g.width / (len(digits) + 1)
+	scale := min(cell/(glyphWidth+1), g.height/(glyphHeight+3))
+	if scale < 1 {
+		scale = 1
+	}
+	for i, d := range digits {
+		c := randomDark(r)
+		x0 := cell/2 + i*cell + r.Intn(max(cell-glyphWidth*scale, 1))
+		y0 := r.Intn(max(g.height-glyphHeight*scale, 1))
+		// 倾斜，每往下一行水平偏移多少像素
+		shear := float64(r.Intn(5)-2) / 4
+		for row, line := range digitGlyphs[d] {
+			for col, bit := range line {
+				if bit != '1' {
+					continue
+				}
+				offset := int(shear * float64((glyphHeight-row)*scale))
+				fillRect(img, x0+col*scale+offset, y0+row*scale, scale, scale, c)
+			}
+		}
+	}
+	for i := 0; i < len(digits); i++ {
+		drawLine(img, r.Intn(g.width), r.Intn(g.height), r.Intn(g.width), r.Intn(g.height), randomDark(r))
+	}
+	for i := 0; i < g.width*g.height/20; i++ {
+		img.Set(r.Intn(g.width), r.Intn(g.height), randomDark(r))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func randomDark(r *mrand.Rand) color.NRGBA {
+	return color.NRGBA{R: uint8(r.Intn(120)), G: uint8(r.Intn(120)), B: uint8(r.Intn(120)), A: 255}
+}
+
+func fillRect(img *image.NRGBA, x, y, w, h int, c color.Color) {
+	for i := x; i < x+w; i++ {
+		for j := y; j < y+h; j++ {
+			img.Set(i, j, c)
+		}
+	}
+}
+
+// drawLine Bresenham 画线
+func drawLine(img *image.NRGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,30 @@
+package captcha
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image/png"
+	"testing"
+)
+
+func TestDigitGenerator_Generate(t *testing.T) {
+	g := NewDigitGenerator(4, 120, 40)
+	answer, img, err := g.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{4}$`, answer)
+
+	decoded, err := png.Decode(bytes.NewReader(img))
+	require.NoError(t, err)
+	assert.Equal(t, 120, decoded.Bounds().Dx())
+	assert.Equal(t, 40, decoded.Bounds().Dy())
+
+	another, _, err := g.Generate()
+	require.NoError(t, err)
+	// 4 位数字重复的概率是万分之一，多生成几次基本不可能一直相同
+	for i := 0; i < 5 && another == answer; i++ {
+		another, _, err = g.Generate()
+		require.NoError(t, err)
+	}
+	assert.NotEqual(t, answer, another)
+}
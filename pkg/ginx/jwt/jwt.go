@@ -23,19 +23,17 @@ type UserClaims struct {
 	jwt.RegisteredClaims
 	Uid   int64
 	Email string
+	// Version 会话版本，用户修改密码等操作后版本递增，旧版本的 token 全部失效
+	Version int64
+	// Roles 用户的角色，签发时写入，角色变更后会话版本递增
+	Roles []string
 }
 
 // SetJwtToken 设置Token
-func SetJwtToken(ctx *gin.Context, id int64, email string) error {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512,
-		&UserClaims{
-			Uid:   id,
-			Email: email,
-			RegisteredClaims: jwt.RegisteredClaims{
-				//ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 15)),
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)),
-			},
-		})
+func SetJwtToken(ctx *gin.Context, uc UserClaims) error {
+	//uc.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute * 15))
+	uc.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, &uc)
 	tokenStr, err := token.SignedString(AccessKey)
 	if err != nil {
 		return err
@@ -52,3 +52,20 @@ func FailWithData(msg string, data any) Result {
 		Data: data,
 	}
 }
+
+// FailWithCode 带业务错误码的失败结果，前端根据错误码区分失败原因
+func FailWithCode(code int, msg string) Result {
+	return Result{
+		Code: code,
+		Msg:  msg,
+		Data: nil,
+	}
+}
+
+func FailWithCodeData(code int, msg string, data any) Result {
+	return Result{
+		Code: code,
+		Msg:  msg,
+		Data: data,
+	}
+}
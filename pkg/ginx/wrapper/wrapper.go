@@ -32,9 +32,10 @@ func WrapperBodyWitJwt[T any](l *zap.Logger, fn func(ctx *gin.Context, req T, uc
 		}
 		var uc *jwt.UserClaims
 		uc, err := jwt.ExtractJwtClaims(ctx)
-		if err != nil {
+		if err != nil || uc == nil {
 			l.Error("获取UserClaims错误", zap.Error(err))
 			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
 		}
 		res, err := fn(ctx, req, uc)
 		if err != nil {
@@ -42,10 +43,6 @@ func WrapperBodyWitJwt[T any](l *zap.Logger, fn func(ctx *gin.Context, req T, uc
 				zap.String("path", ctx.Request.URL.String()),
 				zap.String("router", ctx.FullPath()),
 			)
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-		}
-		if err != nil {
-			l.Error("获取UserClaims错误", zap.Error(err))
 		}
 		ctx.JSON(http.StatusOK, res)
 	}
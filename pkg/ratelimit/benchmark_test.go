@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// benchmarkRedis 从 REDIS_ADDR 读 Redis 地址，默认本机，连不上的时候跳过
+func benchmarkRedis(b *testing.B) redis.Cmdable {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		b.Skipf("连不上 Redis %s: %v", addr, err)
+	}
+	b.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+// benchmarkLimiter keys 个限流对象轮流请求，限流的阈值足够大，比较的是算法本身的开销
+func benchmarkLimiter(b *testing.B, l Limiter, keys int) {
+	prefix := fmt.Sprintf("benchmark:%d:", time.Now().UnixNano())
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := l.Limit(ctx, prefix+strconv.Itoa(i%keys)); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkLimiter(b *testing.B) {
+	const rate = 1000000
+	for _, keys := range []int{1, 1000} {
+		b.Run(fmt.Sprintf("RedisSlidingWindow/keys=%d", keys), func(b *testing.B) {
+			benchmarkLimiter(b, NewRedisSlidingWindowLimiter(benchmarkRedis(b), time.Second, rate), keys)
+		})
+		b.Run(fmt.Sprintf("RedisTokenBucket/keys=%d", keys), func(b *testing.B) {
+			benchmarkLimiter(b, NewRedisTokenBucketLimiter(benchmarkRedis(b), time.Second, rate, rate), keys)
+		})
+		b.Run(fmt.Sprintf("RedisLeakyBucket/keys=%d", keys), func(b *testing.B) {
+			benchmarkLimiter(b, NewRedisLeakyBucketLimiter(benchmarkRedis(b), time.Second, rate, rate), keys)
+		})
+		b.Run(fmt.Sprintf("LocalTokenBucket/keys=%d", keys), func(b *testing.B) {
+			benchmarkLimiter(b, NewLocalTokenBucketLimiter(time.Second, rate, rate), keys)
+		})
+		b.Run(fmt.Sprintf("LocalLeakyBucket/keys=%d", keys), func(b *testing.B) {
+			benchmarkLimiter(b, NewLocalLeakyBucketLimiter(time.Second, rate, rate), keys)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LocalTokenBucketLimiter 进程内的令牌桶，算法和 RedisTokenBucketLimiter 一样，
+// 只适合单实例部署，多实例的时候每个实例各算各的
+type LocalTokenBucketLimiter struct {
+	// Interval 内生成 Rate 个令牌
+	Interval time.Duration
+	Rate     int
+	Capacity int
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLocalTokenBucketLimiter capacity 小于 1 的时候和 rate 一样
+func NewLocalTokenBucketLimiter(interval time.Duration, rate int, capacity int) Limiter {
+	if capacity < 1 {
+		capacity = rate
+	}
+	return &LocalTokenBucketLimiter{
+		Interval: interval,
+		Rate:     rate,
+		Capacity: capacity,
+		buckets:  make(map[string]*tokenBucket),
+		now:      time.Now,
+	}
+}
+
+func (l *LocalTokenBucketLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	res, err := l.LimitDetail(ctx, key)
+	return res.Limited, err
+}
+
+func (l *LocalTokenBucketLimiter) LimitDetail(ctx context.Context, key string) (Result, error) {
+	now := l.now()
+	speed := float64(l.Rate) / float64(l.Interval)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now, speed)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.Capacity), last: now}
+		l.buckets[key] = b
+	}
+	if now.After(b.last) {
+		b.tokens = min(float64(l.Capacity), b.tokens+float64(now.Sub(b.last))*speed)
+		b.last = now
+	}
+	res := Result{Limit: l.Capacity}
+	if b.tokens < 1 {
+		res.Limited = true
+		res.RetryAfter = time.Duration((1 - b.tokens) / speed)
+	} else {
+		b.tokens--
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((float64(l.Capacity) - b.tokens) / speed)
+	return res, nil
+}
+
+// sweep 每个 Interval 清理一次已经装满的桶，装满的桶和不存在是一样的
+func (l *LocalTokenBucketLimiter) sweep(now time.Time, speed float64) {
+	if now.Sub(l.lastSweep) < l.Interval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+float64(now.Sub(b.last))*speed >= float64(l.Capacity) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// LocalLeakyBucketLimiter 进程内的漏桶，算法和 RedisLeakyBucketLimiter 一样，只适合单实例部署
+type LocalLeakyBucketLimiter struct {
+	// Interval 内漏出 Rate 个请求
+	Interval time.Duration
+	Rate     int
+	Capacity int
+
+	mu sync.Mutex
+	// drained 每个 key 桶里的水漏完的时间
+	drained   map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewLocalLeakyBucketLimiter capacity 小于 1 的时候和 rate 一样
+func NewLocalLeakyBucketLimiter(interval time.Duration, rate int, capacity int) Limiter {
+	if capacity < 1 {
+		capacity = rate
+	}
+	return &LocalLeakyBucketLimiter{
+		Interval: interval,
+		Rate:     rate,
+		Capacity: capacity,
+		drained:  make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (l *LocalLeakyBucketLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	res, err := l.LimitDetail(ctx, key)
+	return res.Limited, err
+}
+
+func (l *LocalLeakyBucketLimiter) LimitDetail(ctx context.Context, key string) (Result, error) {
+	now := l.now()
+	emission := l.Interval / time.Duration(l.Rate)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	drained, ok := l.drained[key]
+	if !ok || drained.Before(now) {
+		drained = now
+	}
+	after := drained.Add(emission)
+	full := emission * time.Duration(l.Capacity)
+	if after.Sub(now) > full {
+		return Result{
+			Limited:    true,
+			Limit:      l.Capacity,
+			RetryAfter: after.Sub(now) - full,
+			Reset:      drained.Sub(now),
+		}, nil
+	}
+	l.drained[key] = after
+	return Result{
+		Limit:     l.Capacity,
+		Remaining: int((full - after.Sub(now)) / emission),
+		Reset:     after.Sub(now),
+	}, nil
+}
+
+// sweep 每个 Interval 清理一次已经漏完的桶
+func (l *LocalLeakyBucketLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.Interval {
+		return
+	}
+	l.lastSweep = now
+	for key, drained := range l.drained {
+		if drained.Before(now) {
+			delete(l.drained, key)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// allowed 连续请求 n 次，返回通过的次数
+func allowed(t *testing.T, l Limiter, key string, n int) int {
+	cnt := 0
+	for i := 0; i < n; i++ {
+		limited, err := l.Limit(context.Background(), key)
+		require.NoError(t, err)
+		if !limited {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func TestLocalTokenBucketLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewLocalTokenBucketLimiter(time.Second, 10, 5).(*LocalTokenBucketLimiter)
+	l.now = clock.Now
+
+	// 新的桶是满的，可以一次用完
+	assert.Equal(t, 5, allowed(t, l, "a", 10))
+	// 不同的 key 互不影响
+	assert.Equal(t, 5, allowed(t, l, "b", 10))
+
+	// 每 100ms 补充一个令牌
+	clock.Add(time.Millisecond * 250)
+	assert.Equal(t, 2, allowed(t, l, "a", 10))
+
+	// 最多攒到容量
+	clock.Add(time.Minute)
+	assert.Equal(t, 5, allowed(t, l, "a", 10))
+
+	// 装满的桶会被清理掉
+	clock.Add(time.Minute)
+	_, err := l.Limit(context.Background(), "c")
+	require.NoError(t, err)
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestLocalLeakyBucketLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewLocalLeakyBucketLimiter(time.Second, 10, 3).(*LocalLeakyBucketLimiter)
+	l.now = clock.Now
+
+	// 最多积压 3 个请求
+	assert.Equal(t, 3, allowed(t, l, "a", 10))
+	assert.Equal(t, 3, allowed(t, l, "b", 10))
+
+	// 每 100ms 漏出一个
+	clock.Add(time.Millisecond * 150)
+	assert.Equal(t, 1, allowed(t, l, "a", 10))
+	clock.Add(time.Millisecond * 50)
+	assert.Equal(t, 1, allowed(t, l, "a", 10))
+
+	// 漏完之后不会攒下额度
+	clock.Add(time.Minute)
+	assert.Equal(t, 3, allowed(t, l, "a", 10))
+
+	clock.Add(time.Minute)
+	_, err := l.Limit(context.Background(), "c")
+	require.NoError(t, err)
+	assert.Len(t, l.drained, 1)
+}
+
+func TestLocalLimiter_LimitDetail(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	tb := NewLocalTokenBucketLimiter(time.Second, 10, 2).(*LocalTokenBucketLimiter)
+	tb.now = clock.Now
+	res, err := tb.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, Result{Limit: 2, Remaining: 1, Reset: time.Millisecond * 100}, res)
+	_, err = tb.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	res, err = tb.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, Result{Limited: true, Limit: 2, RetryAfter: time.Millisecond * 100, Reset: time.Millisecond * 200}, res)
+
+	lb := NewLocalLeakyBucketLimiter(time.Second, 10, 2).(*LocalLeakyBucketLimiter)
+	lb.now = clock.Now
+	res, err = lb.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, Result{Limit: 2, Remaining: 1, Reset: time.Millisecond * 100}, res)
+	_, err = lb.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	res, err = lb.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, Result{Limited: true, Limit: 2, RetryAfter: time.Millisecond * 100, Reset: time.Millisecond * 200}, res)
+}
+
+func TestEvalResult(t *testing.T) {
+	cmd := redis.NewCmd(context.Background())
+	cmd.SetVal([]any{int64(1), int64(0), int64(250), int64(1000)})
+	res, err := evalResult(cmd, 10)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Limited: true, Limit: 10, RetryAfter: time.Millisecond * 250, Reset: time.Second}, res)
+
+	cmd = redis.NewCmd(context.Background())
+	cmd.SetVal([]any{int64(1)})
+	_, err = evalResult(cmd, 10)
+	assert.Error(t, err)
+}
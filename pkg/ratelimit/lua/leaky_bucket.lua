@@ -0,0 +1,22 @@
+-- 限流对象
+local key = KEYS[1]
+-- 漏出一个请求需要的毫秒数
+local emission = tonumber(ARGV[1])
+-- 桶的容量，最多积压多少个请求
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+-- 桶里的水全部漏完的时间，只存这一个数
+local drained = tonumber(redis.call('GET', key))
+if drained == nil or drained < now then
+    drained = now
+end
+-- 加上这个请求之后水位超过容量就溢出
+local after = drained + emission
+if after - now > emission * capacity then
+    -- 执行限流，等水位降到能放下这个请求再重试
+    return { 1, 0, math.ceil(after - now - emission * capacity), math.ceil(drained - now) }
+end
+redis.call('SET', key, after, 'PX', math.ceil(after - now))
+local remaining = math.floor((emission * capacity - (after - now)) / emission)
+return { 0, remaining, 0, math.ceil(after - now) }
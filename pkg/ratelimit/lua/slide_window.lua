@@ -10,13 +10,26 @@ local min = now - window
 
 redis.call('ZREMRANGEBYSCORE', key, '-inf', min)
 local cnt = redis.call('ZCOUNT', key, '-inf', '+inf')
--- local cnt = redis.call('ZCOUNT', key, min, '+inf')
+local limited = 0
 if cnt >= threshold then
     -- 执行限流
-    return "true"
+    limited = 1
 else
     -- 把 score 和 member 都设置成 now
     redis.call('ZADD', key, now, now)
     redis.call('PEXPIRE', key, window)
-    return "false"
-end
\ No newline at end of file
+    cnt = cnt + 1
+end
+
+-- 最早的请求滑出窗口之后才有新的额度，最晚的请求滑出窗口之后额度完全恢复
+local retry = 0
+local reset = 0
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local newest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+if limited == 1 and #oldest > 0 then
+    retry = tonumber(oldest[2]) + window - now
+end
+if #newest > 0 then
+    reset = tonumber(newest[2]) + window - now
+end
+return { limited, math.max(threshold - cnt, 0), retry, reset }
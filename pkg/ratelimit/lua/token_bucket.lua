@@ -0,0 +1,36 @@
+-- 限流对象
+local key = KEYS[1]
+-- 桶的容量，也就是允许的突发请求数
+local capacity = tonumber(ARGV[1])
+-- 每毫秒生成的令牌数
+local speed = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+    -- 新的桶是满的
+    tokens = capacity
+    ts = now
+end
+-- 按流逝的时间补充令牌，时钟回拨的时候不补
+if now > ts then
+    tokens = math.min(capacity, tokens + (now - ts) * speed)
+    ts = now
+end
+
+local limited = 0
+local retry = 0
+if tokens < 1 then
+    -- 执行限流，等攒够一个令牌再重试
+    limited = 1
+    retry = math.ceil((1 - tokens) / speed)
+else
+    tokens = tokens - 1
+end
+redis.call('HSET', key, 'tokens', tokens, 'ts', ts)
+-- 桶装满之后和不存在是一样的，过期掉
+local reset = math.ceil((capacity - tokens) / speed)
+redis.call('PEXPIRE', key, reset + 1)
+return { limited, math.floor(tokens), retry, reset }
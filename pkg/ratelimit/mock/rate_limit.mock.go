@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/ratelimit/rate_limit.go
+//
+// Generated by this command:
+//
+//	mockgen -source=pkg/ratelimit/rate_limit.go -package=mock -destination=pkg/ratelimit/mock/rate_limit.mock.go
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	ratelimit "github.com/ChongYanOvO/little-blue-book/pkg/ratelimit"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLimiter is a mock of Limiter interface.
+type MockLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLimiterMockRecorder
+}
+
+// MockLimiterMockRecorder is the mock recorder for MockLimiter.
+type MockLimiterMockRecorder struct {
+	mock *MockLimiter
+}
+
+// NewMockLimiter creates a new mock instance.
+func NewMockLimiter(ctrl *gomock.Controller) *MockLimiter {
+	mock := &MockLimiter{ctrl: ctrl}
+	mock.recorder = &MockLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLimiter) EXPECT() *MockLimiterMockRecorder {
+	return m.recorder
+}
+
+// Limit mocks base method.
+func (m *MockLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Limit", ctx, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Limit indicates an expected call of Limit.
+func (mr *MockLimiterMockRecorder) Limit(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Limit", reflect.TypeOf((*MockLimiter)(nil).Limit), ctx, key)
+}
+
+// MockDetailLimiter is a mock of DetailLimiter interface.
+type MockDetailLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDetailLimiterMockRecorder
+}
+
+// MockDetailLimiterMockRecorder is the mock recorder for MockDetailLimiter.
+type MockDetailLimiterMockRecorder struct {
+	mock *MockDetailLimiter
+}
+
+// NewMockDetailLimiter creates a new mock instance.
+func NewMockDetailLimiter(ctrl *gomock.Controller) *MockDetailLimiter {
+	mock := &MockDetailLimiter{ctrl: ctrl}
+	mock.recorder = &MockDetailLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDetailLimiter) EXPECT() *MockDetailLimiterMockRecorder {
+	return m.recorder
+}
+
+// Limit mocks base method.
+func (m *MockDetailLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Limit", ctx, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Limit indicates an expected call of Limit.
+func (mr *MockDetailLimiterMockRecorder) Limit(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Limit", reflect.TypeOf((*MockDetailLimiter)(nil).Limit), ctx, key)
+}
+
+// LimitDetail mocks base method.
+func (m *MockDetailLimiter) LimitDetail(ctx context.Context, key string) (ratelimit.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LimitDetail", ctx, key)
+	ret0, _ := ret[0].(ratelimit.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LimitDetail indicates an expected call of LimitDetail.
+func (mr *MockDetailLimiterMockRecorder) LimitDetail(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LimitDetail", reflect.TypeOf((*MockDetailLimiter)(nil).LimitDetail), ctx, key)
+}
@@ -1,6 +1,12 @@
 package ratelimit
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
 
 type Limiter interface {
 	// Limit 有没有触发限流。key 就是限流对象
@@ -8,3 +14,38 @@ type Limiter interface {
 	// err 限流器本身有没有错误
 	Limit(ctx context.Context, key string) (bool, error)
 }
+
+// DetailLimiter 除了是否限流还能返回剩余的额度，gin 中间件用它设置 X-RateLimit-* 响应头
+type DetailLimiter interface {
+	Limiter
+	LimitDetail(ctx context.Context, key string) (Result, error)
+}
+
+type Result struct {
+	Limited bool
+	// Limit 最多允许的请求数，滑动窗口是窗口内的阈值，令牌桶和漏桶是桶的容量
+	Limit     int
+	Remaining int
+	// RetryAfter 被限流的时候多久之后可以重试
+	RetryAfter time.Duration
+	// Reset 多久之后额度完全恢复
+	Reset time.Duration
+}
+
+// evalResult 限流脚本统一返回 {是否限流, 剩余额度, 重试等待毫秒数, 完全恢复毫秒数}
+func evalResult(cmd *redis.Cmd, limit int) (Result, error) {
+	vals, err := cmd.Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(vals) != 4 {
+		return Result{}, fmt.Errorf("限流脚本返回值格式错误: %v", vals)
+	}
+	return Result{
+		Limited:    vals[0] == 1,
+		Limit:      limit,
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+		Reset:      time.Duration(vals[3]) * time.Millisecond,
+	}, nil
+}
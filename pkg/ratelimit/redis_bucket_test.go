@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newMiniRedis(t *testing.T) (*miniredis.Miniredis, redis.Cmdable) {
+	mr := miniredis.RunT(t)
+	return mr, redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestRedisTokenBucketLimiter(t *testing.T) {
+	mr, cmd := newMiniRedis(t)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewRedisTokenBucketLimiter(cmd, time.Second, 10, 5).(*RedisTokenBucketLimiter)
+	l.now = clock.Now
+
+	// 新的桶是满的，可以一次用完
+	res, err := l.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.False(t, res.Limited)
+	assert.Equal(t, 4, res.Remaining)
+	assert.Equal(t, 4, allowed(t, l, "a", 10))
+	// 不同的 key 互不影响
+	assert.Equal(t, 5, allowed(t, l, "b", 10))
+
+	// 桶空了之后 500ms 才能装满，过期时间跟着它走
+	assert.Equal(t, time.Millisecond*501, mr.TTL("a"))
+
+	// 每 100ms 补充一个令牌，没攒够一个的时候告诉调用方等多久
+	clock.Add(time.Millisecond * 250)
+	assert.Equal(t, 2, allowed(t, l, "a", 10))
+	res, err = l.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.True(t, res.Limited)
+	assert.Equal(t, time.Millisecond*50, res.RetryAfter)
+
+	// 很久没请求也最多攒下容量那么多令牌
+	clock.Add(time.Hour)
+	assert.Equal(t, 5, allowed(t, l, "a", 10))
+
+	// 桶装满的时候 key 就过期了
+	mr.FastForward(time.Millisecond * 501)
+	assert.False(t, mr.Exists("a"))
+	assert.False(t, mr.Exists("b"))
+}
+
+func TestRedisLeakyBucketLimiter(t *testing.T) {
+	mr, cmd := newMiniRedis(t)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewRedisLeakyBucketLimiter(cmd, time.Second, 10, 5).(*RedisLeakyBucketLimiter)
+	l.now = clock.Now
+
+	// 每 100ms 漏出一个，最多积压 5 个
+	res, err := l.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.False(t, res.Limited)
+	assert.Equal(t, 4, res.Remaining)
+	assert.Equal(t, 4, allowed(t, l, "a", 10))
+	assert.Equal(t, 5, allowed(t, l, "b", 10))
+
+	// 水全部漏完要 500ms
+	assert.Equal(t, time.Millisecond*500, mr.TTL("a"))
+	res, err = l.LimitDetail(context.Background(), "a")
+	require.NoError(t, err)
+	assert.True(t, res.Limited)
+	assert.Equal(t, time.Millisecond*100, res.RetryAfter)
+	assert.Equal(t, time.Millisecond*500, res.Reset)
+
+	// 过了 250ms 漏出去两个半，能再放两个
+	clock.Add(time.Millisecond * 250)
+	assert.Equal(t, 2, allowed(t, l, "a", 10))
+
+	// 漏空之后也只能积压容量那么多
+	clock.Add(time.Hour)
+	assert.Equal(t, 5, allowed(t, l, "a", 10))
+
+	mr.FastForward(time.Millisecond * 500)
+	assert.False(t, mr.Exists("a"))
+	assert.False(t, mr.Exists("b"))
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/leaky_bucket.lua
+var luaLeakyBucket string
+
+// RedisLeakyBucketLimiter Redis 上的漏桶算法限流器实现，每个 key 只存桶里的水漏完的时间，
+// 请求按固定的速度通过，Capacity 是允许积压的请求数
+type RedisLeakyBucketLimiter struct {
+	Cmd redis.Cmdable
+
+	// Interval 内漏出 Rate 个请求
+	Interval time.Duration
+	Rate     int
+	Capacity int
+
+	now func() time.Time
+}
+
+// NewRedisLeakyBucketLimiter capacity 小于 1 的时候和 rate 一样
+func NewRedisLeakyBucketLimiter(cmd redis.Cmdable, interval time.Duration, rate int, capacity int) Limiter {
+	if capacity < 1 {
+		capacity = rate
+	}
+	return &RedisLeakyBucketLimiter{
+		Cmd:      cmd,
+		Interval: interval,
+		Rate:     rate,
+		Capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+func (r *RedisLeakyBucketLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	res, err := r.LimitDetail(ctx, key)
+	return res.Limited, err
+}
+
+func (r *RedisLeakyBucketLimiter) LimitDetail(ctx context.Context, key string) (Result, error) {
+	emission := float64(r.Interval.Milliseconds()) / float64(r.Rate)
+	return evalResult(r.Cmd.Eval(ctx, luaLeakyBucket, []string{key},
+		emission, r.Capacity, r.now().UnixMilli()), r.Capacity)
+}
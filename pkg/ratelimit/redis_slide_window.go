@@ -32,6 +32,11 @@ func NewRedisSlidingWindowLimiter(cmd redis.Cmdable, interval time.Duration, rat
 }
 
 func (r *RedisSlidingWindowLimiter) Limit(ctx context.Context, key string) (bool, error) {
-	return r.Cmd.Eval(ctx, luaSlideWindow, []string{key},
-		r.Interval.Milliseconds(), r.Rate, time.Now().UnixMilli()).Bool()
+	res, err := r.LimitDetail(ctx, key)
+	return res.Limited, err
+}
+
+func (r *RedisSlidingWindowLimiter) LimitDetail(ctx context.Context, key string) (Result, error) {
+	return evalResult(r.Cmd.Eval(ctx, luaSlideWindow, []string{key},
+		r.Interval.Milliseconds(), r.Rate, time.Now().UnixMilli()), r.Rate)
 }
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/token_bucket.lua
+var luaTokenBucket string
+
+// RedisTokenBucketLimiter Redis 上的令牌桶算法限流器实现，每个 key 只存令牌数和上次补充的时间，
+// 允许一次用完桶里的令牌，适合可以接受突发流量的场景
+type RedisTokenBucketLimiter struct {
+	Cmd redis.Cmdable
+
+	// Interval 内生成 Rate 个令牌
+	Interval time.Duration
+	Rate     int
+	// 桶的容量，最多攒下多少个令牌
+	Capacity int
+
+	now func() time.Time
+}
+
+// NewRedisTokenBucketLimiter capacity 小于 1 的时候和 rate 一样
+func NewRedisTokenBucketLimiter(cmd redis.Cmdable, interval time.Duration, rate int, capacity int) Limiter {
+	if capacity < 1 {
+		capacity = rate
+	}
+	return &RedisTokenBucketLimiter{
+		Cmd:      cmd,
+		Interval: interval,
+		Rate:     rate,
+		Capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+func (r *RedisTokenBucketLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	res, err := r.LimitDetail(ctx, key)
+	return res.Limited, err
+}
+
+func (r *RedisTokenBucketLimiter) LimitDetail(ctx context.Context, key string) (Result, error) {
+	speed := float64(r.Rate) / float64(r.Interval.Milliseconds())
+	return evalResult(r.Cmd.Eval(ctx, luaTokenBucket, []string{key},
+		r.Capacity, speed, r.now().UnixMilli()), r.Capacity)
+}
@@ -0,0 +1,117 @@
+package sensitive
+
+import (
+	"unicode"
+)
+
+// node 字典树节点，fail 指向当前前缀在树里的最长真后缀
+type node struct {
+	children map[rune]*node
+	fail     *node
+	// lengths 以当前节点结尾的敏感词的长度，按字符算，没有敏感词在这里结尾的时候为空
+	lengths []int
+}
+
+// automaton Aho-Corasick 自动机，构建之后只读，可以并发匹配
+type automaton struct {
+	root *node
+}
+
+func newAutomaton(words []string) *automaton {
+	root := &node{children: map[rune]*node{}}
+	for _, w := range words {
+		runes := normalize([]rune(w))
+		if len(runes) == 0 {
+			continue
+		}
+		cur := root
+		for _, r := range runes {
+			next, ok := cur.children[r]
+			if !ok {
+				next = &node{children: map[rune]*node{}}
+				cur.children[r] = next
+			}
+			cur = next
+		}
+		if !contains(cur.lengths, len(runes)) {
+			cur.lengths = append(cur.lengths, len(runes))
+		}
+	}
+
+	// 按层构建 fail 指针，fail 节点上结尾的词也是当前节点的后缀，一起合并进来
+	queue := make([]*node, 0, len(root.children))
+	for _, child := range root.children {
+		child.fail = root
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range cur.children {
+			fail := cur.fail
+			for fail != nil {
+				if next, ok := fail.children[r]; ok {
+					child.fail = next
+					break
+				}
+				fail = fail.fail
+			}
+			if child.fail == nil {
+				child.fail = root
+			}
+			for _, l := range child.fail.lengths {
+				if !contains(child.lengths, l) {
+					child.lengths = append(child.lengths, l)
+				}
+			}
+			queue = append(queue, child)
+		}
+	}
+	return &automaton{root: root}
+}
+
+// match 返回所有命中的位置，包括重叠的，按结束位置排序
+func (a *automaton) match(text []rune) []Hit {
+	var hits []Hit
+	cur := a.root
+	for i, r := range normalize(text) {
+		for cur != a.root && cur.children[r] == nil {
+			cur = cur.fail
+		}
+		if next, ok := cur.children[r]; ok {
+			cur = next
+		}
+		for _, l := range cur.lengths {
+			hits = append(hits, Hit{
+				Word:  string(text[i+1-l : i+1]),
+				Start: i + 1 - l,
+				End:   i + 1,
+			})
+		}
+	}
+	return hits
+}
+
+// normalize 忽略大小写和全角半角的差别，一个字符只对应一个字符，位置不会错开
+func normalize(runes []rune) []rune {
+	res := make([]rune, len(runes))
+	for i, r := range runes {
+		// 全角 ASCII 转半角
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		} else if r == 0x3000 {
+			r = ' '
+		}
+		res[i] = unicode.ToLower(r)
+	}
+	return res
+}
+
+func contains(s []int, v int) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
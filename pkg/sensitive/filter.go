@@ -0,0 +1,74 @@
+package sensitive
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// Hit 命中的敏感词，Start 和 End 是按字符（rune）算的位置，左闭右开
+type Hit struct {
+	Word  string
+	Start int
+	End   int
+}
+
+type Filter interface {
+	// Find 找出所有命中的敏感词
+	Find(text string) []Hit
+	// Mask 把命中的敏感词每个字符都替换成 mask
+	Mask(text string, mask rune) (string, []Hit)
+	// Load 替换整个词库，正在进行的匹配用的还是旧词库
+	Load(words []string)
+}
+
+// ACFilter 基于 Aho-Corasick 自动机的敏感词过滤，词库重新加载的时候整体替换自动机，匹配不用加锁
+type ACFilter struct {
+	ac atomic.Pointer[automaton]
+}
+
+func NewACFilter(words []string) *ACFilter {
+	f := &ACFilter{}
+	f.Load(words)
+	return f
+}
+
+func (f *ACFilter) Load(words []string) {
+	f.ac.Store(newAutomaton(words))
+}
+
+func (f *ACFilter) Find(text string) []Hit {
+	if text == "" {
+		return nil
+	}
+	return f.ac.Load().match([]rune(text))
+}
+
+func (f *ACFilter) Mask(text string, mask rune) (string, []Hit) {
+	runes := []rune(text)
+	hits := f.ac.Load().match(runes)
+	if len(hits) == 0 {
+		return text, nil
+	}
+	for _, h := range hits {
+		for i := h.Start; i < h.End; i++ {
+			runes[i] = mask
+		}
+	}
+	return string(runes), hits
+}
+
+// ReadWords 一行一个词，空行和 # 开头的行忽略
+func ReadWords(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		w := strings.TrimSpace(scanner.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		words = append(words, w)
+	}
+	return words, scanner.Err()
+}
@@ -0,0 +1,75 @@
+package sensitive
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestACFilter_Find(t *testing.T) {
+	testCases := []struct {
+		name  string
+		words []string
+		text  string
+		want  []Hit
+	}{
+		{
+			name:  "没有命中",
+			words: []string{"赌博"},
+			text:  "今天天气不错",
+		},
+		{
+			name:  "中文位置按字符算",
+			words: []string{"赌博"},
+			text:  "禁止赌博网站",
+			want:  []Hit{{Word: "赌博", Start: 2, End: 4}},
+		},
+		{
+			name:  "重叠和包含的词都要找出来",
+			words: []string{"he", "she", "his", "hers"},
+			text:  "ushers",
+			want: []Hit{
+				{Word: "she", Start: 1, End: 4},
+				{Word: "he", Start: 2, End: 4},
+				{Word: "hers", Start: 2, End: 6},
+			},
+		},
+		{
+			name:  "忽略大小写和全角",
+			words: []string{"spam"},
+			text:  "买 ＳＰａｍ",
+			want:  []Hit{{Word: "ＳＰａｍ", Start: 2, End: 6}},
+		},
+		{
+			name:  "空词库",
+			words: nil,
+			text:  "anything",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewACFilter(tc.words)
+			hits := f.Find(tc.text)
+			assert.ElementsMatch(t, tc.want, hits)
+		})
+	}
+}
+
+func TestACFilter_Mask(t *testing.T) {
+	f := NewACFilter([]string{"赌博", "博彩"})
+	res, hits := f.Mask("赌博彩票", '*')
+	assert.Equal(t, "***票", res)
+	assert.Len(t, hits, 2)
+
+	// 重新加载之后旧词不再命中
+	f.Load([]string{"彩票"})
+	res, hits = f.Mask("赌博彩票", '*')
+	assert.Equal(t, "赌博**", res)
+	assert.Len(t, hits, 1)
+}
+
+func TestReadWords(t *testing.T) {
+	words, err := ReadWords(strings.NewReader("# 注释\n赌博\n\n  spam  \n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"赌博", "spam"}, words)
+}
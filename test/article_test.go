@@ -23,10 +23,10 @@ type ArticleTestSuite struct {
 }
 
 func (s *ArticleTestSuite) SetupSuite() {
-	app, _ := wire.InitApp()
+	app, _, _ := wire.InitApp()
 	s.Server = app.Server
 	s.db, _ = wire.InitMysql()
-	articleHandler, _ := wire.InitArticleHandler()
+	articleHandler, _, _ := wire.InitArticleHandler()
 	articleHandler.RegisterRoutes(s.Server)
 }
 
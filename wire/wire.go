@@ -12,7 +12,6 @@ import (
 	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
 	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao/article"
 	"github.com/ChongYanOvO/little-blue-book/internal/service"
-	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
 	"github.com/google/wire"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
@@ -33,15 +32,127 @@ var BaseProvider = wire.NewSet(
 var UserProvider = wire.NewSet(
 	cache.NewCodeCache,
 	cache.NewRedisUserCache,
+	cache.NewRedisSessionCache,
 	dao.NewUserDao,
 	repository.NewCodeRepository,
 	repository.NewUserRepository,
-	sms.NewMemoryService,
+	repository.NewSessionRepository,
+	dao.NewSmsTaskDao,
+	repository.NewSmsTaskRepository,
+	bootstrap.NewSmsTemplateRegistry,
+	bootstrap.NewSmsService,
+	bootstrap.NewEmailService,
+	bootstrap.NewCodeLimits,
 	service.NewCodeService,
 	service.NewUserService,
+	service.NewSessionService,
+	cache.NewRedisLoginAttemptCache,
+	repository.NewLoginAttemptRepository,
+	service.NewLoginAttemptService,
+	dao.NewRoleDao,
+	repository.NewRoleRepository,
+	service.NewRoleService,
 	handler.NewUserHandler,
 )
 
+var CaptchaProvider = wire.NewSet(
+	cache.NewRedisCaptchaCache,
+	repository.NewCaptchaRepository,
+	bootstrap.NewCaptchaGenerator,
+	service.NewCaptchaService,
+	handler.NewCaptchaHandler,
+)
+
+var OAuth2Provider = wire.NewSet(
+	cache.NewRedisOAuth2StateCache,
+	repository.NewOAuth2StateRepository,
+	bootstrap.NewOAuth2Providers,
+	service.NewOAuth2Service,
+	handler.NewOAuth2Handler,
+)
+
+var AccountProvider = wire.NewSet(
+	service.NewAccountService,
+	handler.NewAccountHandler,
+)
+
+var FollowProvider = wire.NewSet(
+	dao.NewFollowDao,
+	cache.NewRedisFollowCache,
+	repository.NewFollowRepository,
+	service.NewFollowService,
+	handler.NewFollowHandler,
+)
+
+var FeedProvider = wire.NewSet(
+	dao.NewFeedDao,
+	repository.NewFeedRepository,
+	service.NewFeedService,
+	handler.NewFeedHandler,
+)
+
+var CommentProvider = wire.NewSet(
+	dao.NewCommentDao,
+	repository.NewCommentRepository,
+	service.NewCommentService,
+	handler.NewCommentHandler,
+)
+
+var BlockProvider = wire.NewSet(
+	dao.NewBlockDao,
+	cache.NewRedisBlockCache,
+	repository.NewBlockRepository,
+	service.NewBlockService,
+	handler.NewBlockHandler,
+)
+
+var MessageProvider = wire.NewSet(
+	dao.NewMessageDao,
+	repository.NewMessageRepository,
+	service.NewMessageLimiter,
+	service.NewMessageService,
+	handler.NewMessageHandler,
+)
+
+var PushProvider = wire.NewSet(
+	cache.NewRedisPushCache,
+	repository.NewPushRepository,
+	service.NewPushService,
+	handler.NewPushHandler,
+)
+
+var NotificationProvider = wire.NewSet(
+	dao.NewNotificationDao,
+	cache.NewRedisNotificationCache,
+	repository.NewNotificationRepository,
+	service.NewNotificationService,
+	handler.NewNotificationHandler,
+)
+
+var SensitiveProvider = wire.NewSet(
+	bootstrap.NewSensitiveFilter,
+	bootstrap.NewSensitivePolicies,
+	service.NewSensitiveService,
+)
+
+var ReportProvider = wire.NewSet(
+	dao.NewReportDao,
+	repository.NewReportRepository,
+	service.NewReportService,
+	handler.NewReportHandler,
+)
+
+var SmsProvider = wire.NewSet(
+	dao.NewSmsRecordDao,
+	repository.NewSmsRecordRepository,
+	bootstrap.NewSmsRecordService,
+	handler.NewSmsHandler,
+)
+
+var AdminProvider = wire.NewSet(
+	handler.NewAdminHandler,
+)
+
 var InteractiveProvider = wire.NewSet(
 	cache.NewRedisInteractiveCache,
 	wire.Bind(new(cache.InteractiveCache), new(*cache.RedisInteractiveCache)),
@@ -63,13 +174,28 @@ var ArticleProvider = wire.NewSet(
 	handler.NewArticleHandler,
 )
 
-func InitApp() (core.Application, error) {
+// InitApp 返回的函数用来停止后台任务，退出之前调用
+func InitApp() (core.Application, func(), error) {
 	wire.Build(
 		BaseProvider,
 		UserProvider,
+		CaptchaProvider,
+		OAuth2Provider,
 		ArticleProvider,
+		AccountProvider,
+		AdminProvider,
+		FollowProvider,
+		FeedProvider,
+		CommentProvider,
+		NotificationProvider,
+		PushProvider,
+		MessageProvider,
+		BlockProvider,
+		ReportProvider,
+		SensitiveProvider,
+		SmsProvider,
 	)
-	return core.Application{}, nil
+	return core.Application{}, nil, nil
 }
 
 func InitConfig() (*bootstrap.Config, error) {
@@ -80,12 +206,56 @@ func InitConfig() (*bootstrap.Config, error) {
 	return &bootstrap.Config{}, nil
 }
 
-func InitArticleHandler() (*handler.ArticleHandler, error) {
+func InitArticleHandler() (*handler.ArticleHandler, func(), error) {
 	wire.Build(
 		BaseProvider,
+		cache.NewRedisUserCache,
+		dao.NewUserDao,
+		repository.NewUserRepository,
+		dao.NewFollowDao,
+		cache.NewRedisFollowCache,
+		repository.NewFollowRepository,
+		dao.NewFeedDao,
+		repository.NewFeedRepository,
+		service.NewFeedService,
+		dao.NewCommentDao,
+		repository.NewCommentRepository,
+		dao.NewNotificationDao,
+		cache.NewRedisNotificationCache,
+		repository.NewNotificationRepository,
+		service.NewNotificationService,
+		cache.NewRedisPushCache,
+		repository.NewPushRepository,
+		service.NewPushService,
+		dao.NewBlockDao,
+		cache.NewRedisBlockCache,
+		repository.NewBlockRepository,
+		dao.NewReportDao,
+		repository.NewReportRepository,
+		SensitiveProvider,
 		ArticleProvider,
 	)
-	return &handler.ArticleHandler{}, nil
+	return &handler.ArticleHandler{}, nil, nil
+}
+
+// InitRoleService 命令行授予角色使用，不启动 http 服务
+func InitRoleService() (service.RoleService, error) {
+	wire.Build(
+		bootstrap.NewViper,
+		bootstrap.NewConfig,
+		bootstrap.NewMysql,
+		bootstrap.NewRedis,
+		bootstrap.NewZap,
+		cache.NewRedisUserCache,
+		cache.NewRedisSessionCache,
+		dao.NewUserDao,
+		dao.NewRoleDao,
+		repository.NewUserRepository,
+		repository.NewSessionRepository,
+		repository.NewRoleRepository,
+		service.NewRoleService,
+	)
+	return &service.RoleServiceImpl{}, nil
 }
 
 func InitMysql() (*gorm.DB, error) {
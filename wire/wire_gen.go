@@ -15,7 +15,6 @@ import (
 	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao"
 	"github.com/ChongYanOvO/little-blue-book/internal/repository/dao/article"
 	"github.com/ChongYanOvO/little-blue-book/internal/service"
-	"github.com/ChongYanOvO/little-blue-book/internal/service/sms"
 	"github.com/google/wire"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
@@ -23,32 +22,109 @@ import (
 
 // Injectors from wire.go:
 
-func InitApp() (core.Application, error) {
+// InitApp 返回的函数用来停止后台任务，退出之前调用
+func InitApp() (core.Application, func(), error) {
 	viper := bootstrap.NewViper()
 	config := bootstrap.NewConfig(viper)
 	logger := bootstrap.NewZap(config)
 	db := bootstrap.NewMysql(config, logger)
 	database := bootstrap.NewMongo(config, logger)
 	cmdable := bootstrap.NewRedis(config)
-	v := bootstrap.NewMiddlewares(logger)
 	userDao := dao.NewUserDao(db, logger)
+	sessionCache := cache.NewRedisSessionCache(cmdable, logger)
+	sessionRepository := repository.NewSessionRepository(userDao, sessionCache, logger)
+	sessionService := service.NewSessionService(sessionRepository, logger)
+	v := bootstrap.NewMiddlewares(config, cmdable, logger, sessionService)
 	userCache := cache.NewRedisUserCache(cmdable, logger)
 	userRepository := repository.NewUserRepository(userDao, userCache, logger)
 	userService := service.NewUserService(userRepository, logger)
 	codeCache := cache.NewCodeCache(cmdable, logger)
 	codeRepository := repository.NewCodeRepository(codeCache, logger)
-	smsService := sms.NewMemoryService(logger)
-	codeService := service.NewCodeService(codeRepository, smsService, logger)
-	userHandler := handler.NewUserHandler(userService, codeService, logger)
+	templateRegistry := bootstrap.NewSmsTemplateRegistry(config)
+	smsTaskDao := dao.NewSmsTaskDao(db, logger)
+	smsTaskRepository := repository.NewSmsTaskRepository(smsTaskDao, logger)
+	smsRecordDao := dao.NewSmsRecordDao(db, logger)
+	smsRecordRepository := repository.NewSmsRecordRepository(smsRecordDao, logger)
+	smsService, cleanup := bootstrap.NewSmsService(config, templateRegistry, smsTaskRepository, smsRecordRepository, logger)
+	emailService := bootstrap.NewEmailService(config, logger)
+	captchaCache := cache.NewRedisCaptchaCache(cmdable, logger)
+	captchaRepository := repository.NewCaptchaRepository(captchaCache, logger)
+	generator := bootstrap.NewCaptchaGenerator(config)
+	captchaService := service.NewCaptchaService(captchaRepository, generator, logger)
+	codeLimits := bootstrap.NewCodeLimits(config, cmdable)
+	codeService := service.NewCodeService(codeRepository, smsService, emailService, captchaService, codeLimits, logger)
+	roleDao := dao.NewRoleDao(db, logger)
+	roleRepository := repository.NewRoleRepository(roleDao, logger)
+	roleService := service.NewRoleService(roleRepository, userRepository, sessionRepository, logger)
+	loginAttemptCache := cache.NewRedisLoginAttemptCache(cmdable, logger)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(loginAttemptCache, logger)
+	loginAttemptService := service.NewLoginAttemptService(loginAttemptRepository, logger)
+	followDao := dao.NewFollowDao(db, logger)
+	followCache := cache.NewRedisFollowCache(cmdable, logger)
+	followRepository := repository.NewFollowRepository(followDao, followCache, logger)
+	blockDao := dao.NewBlockDao(db, logger)
+	blockCache := cache.NewRedisBlockCache(cmdable, logger)
+	blockRepository := repository.NewBlockRepository(blockDao, blockCache, logger)
+	notificationDao := dao.NewNotificationDao(db, logger)
+	notificationCache := cache.NewRedisNotificationCache(cmdable, logger)
+	notificationRepository := repository.NewNotificationRepository(notificationDao, notificationCache, logger)
 	articleDao := article.NewArticleDao(db, logger)
 	redisArticleCache := cache.NewRedisArticleCache(cmdable, logger)
 	articleRepository := repository.NewArticleRepository(articleDao, redisArticleCache, logger)
-	articleService := service.NewArticleService(articleRepository, logger)
-	interactiveServiceImpl := service.NewInteractiveServiceImpl()
-	articleHandler := handler.NewArticleHandler(articleService, interactiveServiceImpl, logger)
-	engine := bootstrap.NewServer(v, userHandler, articleHandler)
+	commentDao := dao.NewCommentDao(db, logger)
+	commentRepository := repository.NewCommentRepository(commentDao, logger)
+	pushCache := cache.NewRedisPushCache(cmdable, logger)
+	pushRepository := repository.NewPushRepository(pushCache)
+	pushService := service.NewPushService(pushRepository, logger)
+	notificationService := service.NewNotificationService(notificationRepository, articleRepository, commentRepository, blockRepository, pushService, logger)
+	followService := service.NewFollowService(followRepository, userRepository, blockRepository, notificationService, logger)
+	userHandler := handler.NewUserHandler(userService, codeService, sessionService, roleService, loginAttemptService, followService, logger)
+	filter, cleanup2 := bootstrap.NewSensitiveFilter(config, logger)
+	sensitivePolicies := bootstrap.NewSensitivePolicies(config)
+	reportDao := dao.NewReportDao(db, logger)
+	reportRepository := repository.NewReportRepository(reportDao, logger)
+	sensitiveService := service.NewSensitiveService(filter, sensitivePolicies, reportRepository, logger)
+	articleService := service.NewArticleService(articleRepository, userRepository, blockRepository, sensitiveService, logger)
+	interactiveDaoMysql := dao.NewInteractiveDaoMysql(db, logger)
+	redisInteractiveCache := cache.NewRedisInteractiveCache(cmdable, logger)
+	interactiveRepositoryImpl := repository.NewInteractiveRepositoryImpl(interactiveDaoMysql, redisInteractiveCache, logger)
+	interactiveServiceImpl := service.NewInteractiveServiceImpl(interactiveRepositoryImpl, notificationService)
+	feedDao := dao.NewFeedDao(db, logger)
+	feedRepository := repository.NewFeedRepository(feedDao, logger)
+	feedService := service.NewFeedService(feedRepository, followRepository, blockRepository, logger)
+	articleHandler := handler.NewArticleHandler(articleService, interactiveServiceImpl, feedService, logger)
+	v2 := bootstrap.NewOAuth2Providers(config)
+	oAuth2StateCache := cache.NewRedisOAuth2StateCache(cmdable, logger)
+	oAuth2StateRepository := repository.NewOAuth2StateRepository(oAuth2StateCache, logger)
+	oAuth2Service := service.NewOAuth2Service(v2, oAuth2StateRepository, logger)
+	oAuth2Handler := handler.NewOAuth2Handler(oAuth2Service, userService, sessionService, roleService, logger)
+	messageDao := dao.NewMessageDao(db, logger)
+	messageRepository := repository.NewMessageRepository(messageDao, logger)
+	accountService := service.NewAccountService(userRepository, articleRepository, interactiveRepositoryImpl, sessionRepository, followRepository, commentRepository, messageRepository, logger)
+	accountHandler := handler.NewAccountHandler(accountService, userService, codeService, logger)
+	adminHandler := handler.NewAdminHandler(userService, articleService, roleService, sessionService, logger)
+	followHandler := handler.NewFollowHandler(followService, logger)
+	feedHandler := handler.NewFeedHandler(feedService, logger)
+	commentService := service.NewCommentService(commentRepository, articleRepository, blockRepository, interactiveServiceImpl, notificationService, sensitiveService, logger)
+	commentHandler := handler.NewCommentHandler(commentService, logger)
+	notificationHandler := handler.NewNotificationHandler(notificationService, logger)
+	pushHandler := handler.NewPushHandler(pushService, logger)
+	messageLimiter := service.NewMessageLimiter(cmdable)
+	messageService := service.NewMessageService(messageRepository, userRepository, blockRepository, pushService, messageLimiter, logger)
+	messageHandler := handler.NewMessageHandler(messageService, logger)
+	blockService := service.NewBlockService(blockRepository, userRepository, followRepository, logger)
+	blockHandler := handler.NewBlockHandler(blockService, logger)
+	reportService := service.NewReportService(reportRepository, articleRepository, commentRepository, logger)
+	reportHandler := handler.NewReportHandler(reportService, logger)
+	smsRecordService := bootstrap.NewSmsRecordService(config, smsRecordRepository, logger)
+	smsHandler := handler.NewSmsHandler(smsRecordService, logger)
+	captchaHandler := handler.NewCaptchaHandler(captchaService, logger)
+	engine := bootstrap.NewServer(config, v, userHandler, articleHandler, oAuth2Handler, accountHandler, adminHandler, followHandler, feedHandler, commentHandler, notificationHandler, pushHandler, messageHandler, blockHandler, reportHandler, smsHandler, captchaHandler)
 	application := core.NewApplication(config, db, database, cmdable, logger, engine)
-	return application, nil
+	return application, func() {
+		cleanup2()
+		cleanup()
+	}, nil
 }
 
 func InitConfig() (*bootstrap.Config, error) {
@@ -57,7 +133,7 @@ func InitConfig() (*bootstrap.Config, error) {
 	return config, nil
 }
 
-func InitArticleHandler() (*handler.ArticleHandler, error) {
+func InitArticleHandler() (*handler.ArticleHandler, func(), error) {
 	viper := bootstrap.NewViper()
 	config := bootstrap.NewConfig(viper)
 	logger := bootstrap.NewZap(config)
@@ -66,10 +142,59 @@ func InitArticleHandler() (*handler.ArticleHandler, error) {
 	cmdable := bootstrap.NewRedis(config)
 	redisArticleCache := cache.NewRedisArticleCache(cmdable, logger)
 	articleRepository := repository.NewArticleRepository(articleDao, redisArticleCache, logger)
-	articleService := service.NewArticleService(articleRepository, logger)
-	interactiveServiceImpl := service.NewInteractiveServiceImpl()
-	articleHandler := handler.NewArticleHandler(articleService, interactiveServiceImpl, logger)
-	return articleHandler, nil
+	userDao := dao.NewUserDao(db, logger)
+	userCache := cache.NewRedisUserCache(cmdable, logger)
+	userRepository := repository.NewUserRepository(userDao, userCache, logger)
+	blockDao := dao.NewBlockDao(db, logger)
+	blockCache := cache.NewRedisBlockCache(cmdable, logger)
+	blockRepository := repository.NewBlockRepository(blockDao, blockCache, logger)
+	filter, cleanup := bootstrap.NewSensitiveFilter(config, logger)
+	sensitivePolicies := bootstrap.NewSensitivePolicies(config)
+	reportDao := dao.NewReportDao(db, logger)
+	reportRepository := repository.NewReportRepository(reportDao, logger)
+	sensitiveService := service.NewSensitiveService(filter, sensitivePolicies, reportRepository, logger)
+	articleService := service.NewArticleService(articleRepository, userRepository, blockRepository, sensitiveService, logger)
+	interactiveDaoMysql := dao.NewInteractiveDaoMysql(db, logger)
+	redisInteractiveCache := cache.NewRedisInteractiveCache(cmdable, logger)
+	interactiveRepositoryImpl := repository.NewInteractiveRepositoryImpl(interactiveDaoMysql, redisInteractiveCache, logger)
+	notificationDao := dao.NewNotificationDao(db, logger)
+	notificationCache := cache.NewRedisNotificationCache(cmdable, logger)
+	notificationRepository := repository.NewNotificationRepository(notificationDao, notificationCache, logger)
+	commentDao := dao.NewCommentDao(db, logger)
+	commentRepository := repository.NewCommentRepository(commentDao, logger)
+	pushCache := cache.NewRedisPushCache(cmdable, logger)
+	pushRepository := repository.NewPushRepository(pushCache)
+	pushService := service.NewPushService(pushRepository, logger)
+	notificationService := service.NewNotificationService(notificationRepository, articleRepository, commentRepository, blockRepository, pushService, logger)
+	interactiveServiceImpl := service.NewInteractiveServiceImpl(interactiveRepositoryImpl, notificationService)
+	feedDao := dao.NewFeedDao(db, logger)
+	feedRepository := repository.NewFeedRepository(feedDao, logger)
+	followDao := dao.NewFollowDao(db, logger)
+	followCache := cache.NewRedisFollowCache(cmdable, logger)
+	followRepository := repository.NewFollowRepository(followDao, followCache, logger)
+	feedService := service.NewFeedService(feedRepository, followRepository, blockRepository, logger)
+	articleHandler := handler.NewArticleHandler(articleService, interactiveServiceImpl, feedService, logger)
+	return articleHandler, func() {
+		cleanup()
+	}, nil
+}
+
+// InitRoleService 命令行授予角色使用，不启动 http 服务
+func InitRoleService() (service.RoleService, error) {
+	viper := bootstrap.NewViper()
+	config := bootstrap.NewConfig(viper)
+	logger := bootstrap.NewZap(config)
+	db := bootstrap.NewMysql(config, logger)
+	roleDao := dao.NewRoleDao(db, logger)
+	roleRepository := repository.NewRoleRepository(roleDao, logger)
+	userDao := dao.NewUserDao(db, logger)
+	cmdable := bootstrap.NewRedis(config)
+	userCache := cache.NewRedisUserCache(cmdable, logger)
+	userRepository := repository.NewUserRepository(userDao, userCache, logger)
+	sessionCache := cache.NewRedisSessionCache(cmdable, logger)
+	sessionRepository := repository.NewSessionRepository(userDao, sessionCache, logger)
+	roleService := service.NewRoleService(roleRepository, userRepository, sessionRepository, logger)
+	return roleService, nil
 }
 
 func InitMysql() (*gorm.DB, error) {
@@ -92,7 +217,35 @@ func InitMongo() (*mongo.Database, error) {
 
 var BaseProvider = wire.NewSet(bootstrap.NewViper, bootstrap.NewConfig, bootstrap.NewMysql, bootstrap.NewMongo, bootstrap.NewRedis, bootstrap.NewZap, bootstrap.NewMiddlewares, bootstrap.NewServer, core.NewApplication)
 
-var UserProvider = wire.NewSet(cache.NewCodeCache, cache.NewRedisUserCache, dao.NewUserDao, repository.NewCodeRepository, repository.NewUserRepository, sms.NewMemoryService, service.NewCodeService, service.NewUserService, handler.NewUserHandler)
+var UserProvider = wire.NewSet(cache.NewCodeCache, cache.NewRedisUserCache, cache.NewRedisSessionCache, dao.NewUserDao, repository.NewCodeRepository, repository.NewUserRepository, repository.NewSessionRepository, dao.NewSmsTaskDao, repository.NewSmsTaskRepository, bootstrap.NewSmsTemplateRegistry, bootstrap.NewSmsService, bootstrap.NewEmailService, bootstrap.NewCodeLimits, service.NewCodeService, service.NewUserService, service.NewSessionService, cache.NewRedisLoginAttemptCache, repository.NewLoginAttemptRepository, service.NewLoginAttemptService, dao.NewRoleDao, repository.NewRoleRepository, service.NewRoleService, handler.NewUserHandler)
+
+var CaptchaProvider = wire.NewSet(cache.NewRedisCaptchaCache, repository.NewCaptchaRepository, bootstrap.NewCaptchaGenerator, service.NewCaptchaService, handler.NewCaptchaHandler)
+
+var OAuth2Provider = wire.NewSet(cache.NewRedisOAuth2StateCache, repository.NewOAuth2StateRepository, bootstrap.NewOAuth2Providers, service.NewOAuth2Service, handler.NewOAuth2Handler)
+
+var AccountProvider = wire.NewSet(service.NewAccountService, handler.NewAccountHandler)
+
+var FollowProvider = wire.NewSet(dao.NewFollowDao, cache.NewRedisFollowCache, repository.NewFollowRepository, service.NewFollowService, handler.NewFollowHandler)
+
+var FeedProvider = wire.NewSet(dao.NewFeedDao, repository.NewFeedRepository, service.NewFeedService, handler.NewFeedHandler)
+
+var CommentProvider = wire.NewSet(dao.NewCommentDao, repository.NewCommentRepository, service.NewCommentService, handler.NewCommentHandler)
+
+var BlockProvider = wire.NewSet(dao.NewBlockDao, cache.NewRedisBlockCache, repository.NewBlockRepository, service.NewBlockService, handler.NewBlockHandler)
+
+var MessageProvider = wire.NewSet(dao.NewMessageDao, repository.NewMessageRepository, service.NewMessageLimiter, service.NewMessageService, handler.NewMessageHandler)
+
+var PushProvider = wire.NewSet(cache.NewRedisPushCache, repository.NewPushRepository, service.NewPushService, handler.NewPushHandler)
+
+var NotificationProvider = wire.NewSet(dao.NewNotificationDao, cache.NewRedisNotificationCache, repository.NewNotificationRepository, service.NewNotificationService, handler.NewNotificationHandler)
+
+var SensitiveProvider = wire.NewSet(bootstrap.NewSensitiveFilter, bootstrap.NewSensitivePolicies, service.NewSensitiveService)
+
+var ReportProvider = wire.NewSet(dao.NewReportDao, repository.NewReportRepository, service.NewReportService, handler.NewReportHandler)
+
+var SmsProvider = wire.NewSet(dao.NewSmsRecordDao, repository.NewSmsRecordRepository, bootstrap.NewSmsRecordService, handler.NewSmsHandler)
+
+var AdminProvider = wire.NewSet(handler.NewAdminHandler)
 
 var InteractiveProvider = wire.NewSet(cache.NewRedisInteractiveCache, wire.Bind(new(cache.InteractiveCache), new(*cache.RedisInteractiveCache)), dao.NewInteractiveDaoMysql, wire.Bind(new(dao.InteractiveDao), new(*dao.InteractiveDaoMysql)), repository.NewInteractiveRepositoryImpl, wire.Bind(new(repository.InteractiveRepository), new(*repository.InteractiveRepositoryImpl)), service.NewInteractiveServiceImpl, wire.Bind(new(service.InteractiveService), new(*service.InteractiveServiceImpl)))
 